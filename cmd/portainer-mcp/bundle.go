@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/portainer/portainer-mcp/internal/stackbundle"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	exportStacksCommand = "export-stacks"
+	importStacksCommand = "import-stacks"
+)
+
+// runExportStacks writes the stacks of a Portainer server to a bundle
+func runExportStacks(args []string) {
+	flags := flag.NewFlagSet(exportStacksCommand, flag.ExitOnError)
+	serverFlag := flags.String("server", "", "The Portainer server URL")
+	tokenFlag := flags.String("token", "", "The authentication token for the Portainer server")
+	outputFlag := flags.String("output", "", "The path of the bundle to write, a .zip extension writes a zip archive, otherwise a gzip compressed tar archive is written")
+	stacksFlag := flags.String("stacks", "", "Comma separated IDs of the regular stacks to export (all the stacks by default)")
+	edgeStacksFlag := flags.String("edge-stacks", "", "Comma separated IDs of the edge stacks to export (all the stacks by default)")
+	includeEnvValuesFlag := flags.Bool("include-env-values", false, "Include the environment variable values, encrypted with the passphrase set in "+stackbundle.PassphraseEnv)
+	_ = flags.Parse(args)
+
+	if *serverFlag == "" || *tokenFlag == "" || *outputFlag == "" {
+		log.Fatal().Msg("The -server, -token and -output flags are required")
+	}
+
+	passphrase := os.Getenv(stackbundle.PassphraseEnv)
+	if *includeEnvValuesFlag && passphrase == "" {
+		log.Fatal().Msgf("The %s environment variable must be set to include environment variable values", stackbundle.PassphraseEnv)
+	}
+
+	stackIDs, err := parseIDList(*stacksFlag)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -stacks flag")
+	}
+
+	edgeStackIDs, err := parseIDList(*edgeStacksFlag)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -edge-stacks flag")
+	}
+
+	cli := client.NewPortainerClient(*serverFlag, *tokenFlag, client.WithSkipTLSVerify(true))
+
+	stacks, err := stackbundle.Export(cli, stackbundle.ExportOptions{StackIDs: stackIDs, EdgeStackIDs: edgeStackIDs, IncludeEnvValues: *includeEnvValuesFlag})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to export stacks")
+	}
+
+	var buf bytes.Buffer
+	if err := stackbundle.Write(&buf, stackbundle.FormatFromPath(*outputFlag), stacks, passphrase); err != nil {
+		log.Fatal().Err(err).Msg("failed to write stack bundle")
+	}
+	if err := os.WriteFile(*outputFlag, buf.Bytes(), 0600); err != nil {
+		log.Fatal().Err(err).Msg("failed to write stack bundle")
+	}
+
+	log.Info().
+		Int("stacks", len(stacks)).
+		Str("output", *outputFlag).
+		Bool("env-values", *includeEnvValuesFlag).
+		Msg("stacks exported")
+}
+
+// runImportStacks prints the plan to import the stacks of a bundle on a Portainer server, and applies it if requested
+func runImportStacks(args []string) {
+	flags := flag.NewFlagSet(importStacksCommand, flag.ExitOnError)
+	serverFlag := flags.String("server", "", "The Portainer server URL")
+	tokenFlag := flags.String("token", "", "The authentication token for the Portainer server")
+	inputFlag := flags.String("input", "", "The path of the bundle to import")
+	environmentMapFlag := flags.String("environment-map", "", "Comma separated name=id pairs mapping the environment names of the bundle to environment IDs (environments are matched by name by default)")
+	applyFlag := flags.Bool("apply", false, "Create the stacks, by default the import plan is only printed")
+	allowMissingValuesFlag := flags.Bool("allow-missing-values", false, "Create the environment variables with empty values when the bundle contains encrypted values and no passphrase is set")
+	_ = flags.Parse(args)
+
+	if *serverFlag == "" || *tokenFlag == "" || *inputFlag == "" {
+		log.Fatal().Msg("The -server, -token and -input flags are required")
+	}
+
+	environmentMap, err := parseEnvironmentMap(*environmentMapFlag)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid -environment-map flag")
+	}
+
+	data, err := os.ReadFile(*inputFlag)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read stack bundle")
+	}
+
+	passphrase := os.Getenv(stackbundle.PassphraseEnv)
+	manifest, stacks, err := stackbundle.Read(data, passphrase)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read stack bundle")
+	}
+	if manifest.Encryption != nil && passphrase == "" && !*allowMissingValuesFlag {
+		log.Fatal().Msgf("The bundle contains encrypted environment variable values: set the %s environment variable to decrypt them, or the -allow-missing-values flag to create the variables with empty values", stackbundle.PassphraseEnv)
+	}
+
+	cli := client.NewPortainerClient(*serverFlag, *tokenFlag, client.WithSkipTLSVerify(true))
+
+	plan, err := stackbundle.Plan(cli, stacks, stackbundle.ImportOptions{EnvironmentMap: environmentMap})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to plan stack import")
+	}
+
+	if *applyFlag {
+		plan = stackbundle.Apply(cli, plan)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(plan); err != nil {
+		log.Fatal().Err(err).Msg("failed to print import plan")
+	}
+}
+
+func parseIDList(value string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseEnvironmentMap(value string) (map[string]int, error) {
+	result := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, idValue, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected name=id", pair)
+		}
+		id, err := strconv.Atoi(idValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid environment ID in %q", pair)
+		}
+		result[name] = id
+	}
+	return result, nil
+}
@@ -2,13 +2,26 @@ package main
 
 import (
 	"flag"
+	"os"
+	"path/filepath"
 
+	"github.com/portainer/portainer-mcp/internal/journal"
 	"github.com/portainer/portainer-mcp/internal/mcp"
+	"github.com/portainer/portainer-mcp/internal/revisions"
+	"github.com/portainer/portainer-mcp/internal/stackbundle"
+	"github.com/portainer/portainer-mcp/internal/templates"
 	"github.com/portainer/portainer-mcp/internal/tooldef"
 	"github.com/rs/zerolog/log"
 )
 
-const defaultToolsPath = "tools.yaml"
+const (
+	defaultToolsPath  = "tools.yaml"
+	dataDirName       = "portainer-mcp"
+	journalFileName   = "journal.json"
+	revisionsDirName  = "stack-revisions"
+	bundlesDirName    = "stack-bundles"
+	templatesFileName = "stack-templates.json"
+)
 
 var (
 	Version   string
@@ -23,11 +36,31 @@ func main() {
 		Str("commit", Commit).
 		Msg("Portainer MCP server")
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case exportStacksCommand:
+			runExportStacks(os.Args[2:])
+			return
+		case importStacksCommand:
+			runImportStacks(os.Args[2:])
+			return
+		}
+	}
+
 	serverFlag := flag.String("server", "", "The Portainer server URL")
 	tokenFlag := flag.String("token", "", "The authentication token for the Portainer server")
 	toolsFlag := flag.String("tools", "", "The path to the tools YAML file")
 	readOnlyFlag := flag.Bool("read-only", false, "Run in read-only mode")
+	allowDestructiveFlag := flag.Bool("allow-destructive", false, "Register the destructive tools, such as deleteEnvironment and deleteStack (ignored in read-only mode)")
 	disableVersionCheckFlag := flag.Bool("disable-version-check", false, "Disable Portainer server version check")
+	toolRateLimitFlag := flag.Int("tool-rate-limit", 0, "Maximum number of calls per minute for each tool (0 disables the limit)")
+	toolRateBurstFlag := flag.Int("tool-rate-burst", 10, "Maximum burst of calls for each tool when -tool-rate-limit is set")
+	environmentRateLimitFlag := flag.Int("environment-rate-limit", 0, "Maximum number of calls per minute targeting each environment (0 disables the limit)")
+	environmentRateBurstFlag := flag.Int("environment-rate-burst", 10, "Maximum burst of calls targeting each environment when -environment-rate-limit is set")
+	maxConcurrentRequestsFlag := flag.Int("max-concurrent-requests", 0, "Maximum number of concurrent requests to the Portainer server (0 disables the limit)")
+	dataDirFlag := flag.String("data-dir", "", "The directory where the server stores its local data, such as the change journal (defaults to portainer-mcp in the user configuration directory)")
+	cacheTTLFlag := flag.Duration("cache-ttl", 0, "How long the environment, tag, team and user lists are cached, e.g. 30s (0 disables the cache)")
+	maxResponseSizeFlag := flag.Int("max-response-size", 0, "Maximum size in bytes of a tool response, larger responses are returned in chunks (0 disables the limit)")
 
 	flag.Parse()
 
@@ -53,14 +86,62 @@ func main() {
 		log.Info().Msg("created tools.yaml file")
 	}
 
+	dataDir := resolveDataDir(*dataDirFlag)
+
+	changeJournal, err := journal.Open(dataPath(dataDir, journalFileName))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to open change journal, changes will only be kept in memory")
+		changeJournal, _ = journal.Open("")
+	}
+
+	stackRevisions, err := revisions.Open(dataPath(dataDir, revisionsDirName), *serverFlag)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to open stack revisions, revisions will only be kept in memory")
+		stackRevisions, _ = revisions.Open("", "")
+	}
+
+	stackTemplates, err := templates.Open(dataPath(dataDir, templatesFileName))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to open stack templates, templates will only be kept in memory")
+		stackTemplates, _ = templates.Open("")
+	}
+
+	bundleDir := dataPath(dataDir, bundlesDirName)
+	if bundleDir != "" {
+		if err := os.MkdirAll(bundleDir, 0700); err != nil {
+			log.Warn().Err(err).Msg("failed to create the stack bundle directory, stacks cannot be exported or imported")
+			bundleDir = ""
+		}
+	}
+
 	log.Info().
 		Str("portainer-host", *serverFlag).
 		Str("tools-path", toolsPath).
+		Str("data-dir", dataDir).
 		Bool("read-only", *readOnlyFlag).
+		Bool("allow-destructive", *allowDestructiveFlag).
 		Bool("disable-version-check", *disableVersionCheckFlag).
+		Int("max-response-size", *maxResponseSizeFlag).
+		Int("tool-rate-limit", *toolRateLimitFlag).
+		Int("environment-rate-limit", *environmentRateLimitFlag).
+		Int("max-concurrent-requests", *maxConcurrentRequestsFlag).
+		Dur("cache-ttl", *cacheTTLFlag).
 		Msg("starting MCP server")
 
-	server, err := mcp.NewPortainerMCPServer(*serverFlag, *tokenFlag, toolsPath, mcp.WithReadOnly(*readOnlyFlag), mcp.WithDisableVersionCheck(*disableVersionCheckFlag))
+	server, err := mcp.NewPortainerMCPServer(*serverFlag, *tokenFlag, toolsPath,
+		mcp.WithReadOnly(*readOnlyFlag),
+		mcp.WithDestructiveTools(*allowDestructiveFlag),
+		mcp.WithDisableVersionCheck(*disableVersionCheckFlag),
+		mcp.WithMaxResponseSize(*maxResponseSizeFlag),
+		mcp.WithToolRateLimit(mcp.RateLimit{PerMinute: *toolRateLimitFlag, Burst: *toolRateBurstFlag}),
+		mcp.WithEnvironmentRateLimit(mcp.RateLimit{PerMinute: *environmentRateLimitFlag, Burst: *environmentRateBurstFlag}),
+		mcp.WithMaxConcurrentRequests(*maxConcurrentRequestsFlag),
+		mcp.WithJournal(changeJournal),
+		mcp.WithStackRevisions(stackRevisions),
+		mcp.WithStackTemplates(stackTemplates),
+		mcp.WithStackBundles(bundleDir, os.Getenv(stackbundle.PassphraseEnv)),
+		mcp.WithCacheTTL(*cacheTTLFlag),
+	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create server")
 	}
@@ -74,10 +155,42 @@ func main() {
 	server.AddTeamFeatures()
 	server.AddAccessGroupFeatures()
 	server.AddDockerProxyFeatures()
+	server.AddContainerFeatures()
 	server.AddKubernetesProxyFeatures()
+	server.AddMetricsFeatures()
+	server.AddJournalFeatures()
 
 	err = server.Start()
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start server")
 	}
 }
+
+// resolveDataDir returns the directory where the server stores its local data: the given directory, or by default
+// a portainer-mcp directory in the user configuration directory, falling back to the user cache directory.
+// It returns an empty string, meaning that the local data is only kept in memory, if none of them is available.
+func resolveDataDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base, err = os.UserCacheDir()
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("no data directory available, local data will only be kept in memory")
+		return ""
+	}
+
+	return filepath.Join(base, dataDirName)
+}
+
+// dataPath returns the path of a file or directory in the data directory, or an empty string
+// if there is no data directory, so that the store using it is kept in memory
+func dataPath(dataDir, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
+}
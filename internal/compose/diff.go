@@ -0,0 +1,403 @@
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Changes of a service between two compose files
+const (
+	ServiceAdded    = "added"
+	ServiceRemoved  = "removed"
+	ServiceModified = "modified"
+)
+
+// FileDiff describes the differences between two compose files
+type FileDiff struct {
+	// Unified is the unified diff of the two files, empty if they are identical
+	Unified  string        `json:"unified_diff"`
+	Services []ServiceDiff `json:"services"`
+}
+
+// ServiceDiff describes the changes of a service between two compose files
+type ServiceDiff struct {
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	// Image is set when the image of the service changes
+	Image   *ValueChange `json:"image,omitempty"`
+	Ports   *ListChange  `json:"ports,omitempty"`
+	Volumes *ListChange  `json:"volumes,omitempty"`
+	// Environment lists the names of the environment variables of the service that change, values are not included
+	Environment *NameChange `json:"environment,omitempty"`
+	// OtherKeys lists the other keys of the service definition that change
+	OtherKeys []string `json:"other_keys,omitempty"`
+}
+
+// ValueChange is a value that changes from From to To
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ListChange lists the entries added to and removed from a list
+type ListChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// NameChange lists the names of the entries added, removed or changed in a set of name/value pairs
+type NameChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Empty returns true if no entry is added, removed or changed
+func (c NameChange) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// DiffValues compares two sets of name/value pairs, such as environment variables
+func DiffValues(from, to map[string]string) NameChange {
+	var change NameChange
+	for name, value := range to {
+		previous, exists := from[name]
+		switch {
+		case !exists:
+			change.Added = append(change.Added, name)
+		case previous != value:
+			change.Changed = append(change.Changed, name)
+		}
+	}
+	for name := range from {
+		if _, exists := to[name]; !exists {
+			change.Removed = append(change.Removed, name)
+		}
+	}
+
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+	sort.Strings(change.Changed)
+	return change
+}
+
+// Values replacing the environment variable values of the services in the unified diff
+const (
+	maskedValue        = "********"
+	maskedChangedValue = "******** (changed)"
+)
+
+// Diff compares a live compose file with a proposed one.
+// It returns the unified diff of the files and the changes of each service.
+// The values of the environment variables of the services are masked in the unified diff.
+func Diff(live, proposed string) (FileDiff, error) {
+	liveServices, err := parseServices(live)
+	if err != nil {
+		return FileDiff{}, fmt.Errorf("failed to parse the live file: %w", err)
+	}
+	proposedServices, err := parseServices(proposed)
+	if err != nil {
+		return FileDiff{}, fmt.Errorf("failed to parse the proposed file: %w", err)
+	}
+
+	diff := FileDiff{Services: []ServiceDiff{}}
+	changedValues := map[string][]string{}
+
+	for _, name := range sortedKeys(liveServices, proposedServices) {
+		from, inLive := liveServices[name]
+		to, inProposed := proposedServices[name]
+
+		serviceDiff := ServiceDiff{Name: name, Change: ServiceModified}
+		switch {
+		case !inLive:
+			serviceDiff.Change = ServiceAdded
+		case !inProposed:
+			serviceDiff.Change = ServiceRemoved
+		}
+
+		if fillServiceDiff(&serviceDiff, from, to) {
+			diff.Services = append(diff.Services, serviceDiff)
+			if serviceDiff.Environment != nil {
+				changedValues[name] = serviceDiff.Environment.Changed
+			}
+		}
+	}
+
+	diff.Unified, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(withTrailingNewline(maskEnvironment(live, nil))),
+		B:        difflib.SplitLines(withTrailingNewline(maskEnvironment(proposed, changedValues))),
+		FromFile: "live",
+		ToFile:   "proposed",
+		Context:  3,
+	})
+	if err != nil {
+		return FileDiff{}, fmt.Errorf("failed to compute the unified diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// maskEnvironment returns a compose file in which the values of the environment variables of the services
+// are masked, including the values defined under an anchor. The values of the variables listed in changed
+// by service name are masked with a distinct marker, so that the unified diff still shows that they change.
+func maskEnvironment(file string, changed map[string][]string) string {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(file), &document); err != nil || len(document.Content) == 0 {
+		return file
+	}
+
+	root := resolveAliases(document.Content[0], map[*yaml.Node]*yaml.Node{})
+	services := mappingValue(root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return file
+	}
+
+	lines := strings.SplitAfter(file, "\n")
+	masks := lineMasks{}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		service := services.Content[i].Value
+		environment := mappingValue(services.Content[i+1], "environment")
+		if environment == nil {
+			continue
+		}
+
+		switch environment.Kind {
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(environment.Content); j += 2 {
+				name, value := environment.Content[j].Value, environment.Content[j+1]
+				masks.add(value, value.Column, slices.Contains(changed[service], name))
+			}
+		case yaml.SequenceNode:
+			for _, item := range environment.Content {
+				name, _, found := strings.Cut(item.Value, "=")
+				if !found || item.Line < 1 || item.Line > len(lines) || item.Column < 1 || item.Column > len(lines[item.Line-1]) {
+					continue
+				}
+				// The value starts after the first = following the start of the item
+				if index := strings.Index(lines[item.Line-1][item.Column-1:], "="); index >= 0 {
+					masks.add(item, item.Column+index+1, slices.Contains(changed[service], name))
+				}
+			}
+		}
+	}
+
+	return masks.apply(lines)
+}
+
+// lineMask masks the end of a line, from a column, and the following lines holding the rest of a block scalar
+type lineMask struct {
+	column  int
+	changed bool
+	extra   int
+}
+
+// lineMasks are the masks to apply to the lines of a file, by line number
+type lineMasks map[int]*lineMask
+
+// add masks a scalar value starting at a column of its line. Literal and folded block scalars start on the line of
+// their indicator and span the following lines, which are masked as well.
+func (m lineMasks) add(value *yaml.Node, column int, changed bool) {
+	if value.Kind != yaml.ScalarNode || value.Line < 1 || value.ShortTag() == "!!null" {
+		return
+	}
+
+	extra := 0
+	if value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		extra = strings.Count(strings.TrimSuffix(value.Value, "\n"), "\n") + 1
+	}
+
+	mask, exists := m[value.Line]
+	if !exists {
+		m[value.Line] = &lineMask{column: column, changed: changed, extra: extra}
+		return
+	}
+	mask.column = min(mask.column, column)
+	mask.changed = mask.changed || changed
+	mask.extra = max(mask.extra, extra)
+}
+
+// apply returns the file made of the lines with the masks applied
+func (m lineMasks) apply(lines []string) string {
+	dropped := map[int]bool{}
+	for line, mask := range m {
+		for i := 1; i <= mask.extra; i++ {
+			dropped[line+i] = true
+		}
+	}
+
+	var masked strings.Builder
+	for i, line := range lines {
+		number := i + 1
+		if dropped[number] {
+			continue
+		}
+
+		mask, exists := m[number]
+		if !exists || mask.column < 1 || mask.column > len(line) {
+			masked.WriteString(line)
+			continue
+		}
+
+		marker := maskedValue
+		if mask.changed {
+			marker = maskedChangedValue
+		}
+		masked.WriteString(line[:mask.column-1] + marker)
+		if strings.HasSuffix(line, "\n") {
+			masked.WriteString("\n")
+		}
+	}
+
+	return masked.String()
+}
+
+// fillServiceDiff compares two service definitions, either of which may be nil.
+// It returns false if they are identical.
+func fillServiceDiff(diff *ServiceDiff, from, to map[string]any) bool {
+	changed := false
+
+	fromImage, toImage := scalarString(from["image"]), scalarString(to["image"])
+	if fromImage != toImage {
+		diff.Image = &ValueChange{From: fromImage, To: toImage}
+		changed = true
+	}
+
+	if ports := diffLists(from["ports"], to["ports"]); ports != nil {
+		diff.Ports = ports
+		changed = true
+	}
+
+	if volumes := diffLists(from["volumes"], to["volumes"]); volumes != nil {
+		diff.Volumes = volumes
+		changed = true
+	}
+
+	if environment := DiffValues(environmentValues(from["environment"]), environmentValues(to["environment"])); !environment.Empty() {
+		diff.Environment = &environment
+		changed = true
+	}
+
+	for _, key := range sortedKeys(from, to) {
+		switch key {
+		case "image", "ports", "volumes", "environment":
+			continue
+		}
+		if !reflect.DeepEqual(from[key], to[key]) {
+			diff.OtherKeys = append(diff.OtherKeys, key)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func parseServices(file string) (map[string]map[string]any, error) {
+	var document struct {
+		Services map[string]map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(file), &document); err != nil {
+		return nil, err
+	}
+	if document.Services == nil {
+		return map[string]map[string]any{}, nil
+	}
+	return document.Services, nil
+}
+
+// diffLists compares two lists of entries, such as ports or volumes. It returns nil if they contain the same entries.
+func diffLists(from, to any) *ListChange {
+	fromEntries, toEntries := listEntries(from), listEntries(to)
+
+	var change ListChange
+	for entry := range toEntries {
+		if !fromEntries[entry] {
+			change.Added = append(change.Added, entry)
+		}
+	}
+	for entry := range fromEntries {
+		if !toEntries[entry] {
+			change.Removed = append(change.Removed, entry)
+		}
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil
+	}
+
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+	return &change
+}
+
+func listEntries(value any) map[string]bool {
+	entries := map[string]bool{}
+	items, _ := value.([]any)
+	for _, item := range items {
+		entries[scalarString(item)] = true
+	}
+	return entries
+}
+
+// environmentValues normalizes the mapping and list syntaxes of the environment of a service
+func environmentValues(value any) map[string]string {
+	values := map[string]string{}
+	switch environment := value.(type) {
+	case map[string]any:
+		for name, v := range environment {
+			values[name] = scalarString(v)
+		}
+	case []any:
+		for _, item := range environment {
+			name, v, _ := strings.Cut(scalarString(item), "=")
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// scalarString formats a YAML value as a string, mappings and lists are formatted as JSON
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func withTrailingNewline(file string) string {
+	if file == "" || strings.HasSuffix(file, "\n") {
+		return file
+	}
+	return file + "\n"
+}
@@ -0,0 +1,114 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	live := `services:
+  web:
+    image: nginx:1.26
+    ports:
+      - "80:80"
+    volumes:
+      - ./html:/usr/share/nginx/html
+    environment:
+      MODE: prod
+      TOKEN: secret
+      DEBUG: "0"
+  cache:
+    image: redis:7
+`
+	proposed := `services:
+  web:
+    image: nginx:1.27
+    ports:
+      - "80:80"
+      - "443:443"
+    environment:
+      - MODE=prod
+      - TOKEN=rotated
+      - LOG_LEVEL=info
+    restart: always
+  db:
+    image: postgres:16
+`
+
+	diff, err := Diff(live, proposed)
+	require.NoError(t, err)
+
+	assert.Contains(t, diff.Unified, "--- live\n+++ proposed\n")
+	assert.Contains(t, diff.Unified, "-    image: nginx:1.26\n+    image: nginx:1.27\n")
+	assert.NotContains(t, diff.Unified, "secret")
+	assert.NotContains(t, diff.Unified, "rotated")
+	assert.Contains(t, diff.Unified, "-      TOKEN: ********\n")
+	assert.Contains(t, diff.Unified, "+      - TOKEN=******** (changed)\n")
+	assert.Contains(t, diff.Unified, "+      - LOG_LEVEL=********\n")
+
+	assert.Equal(t, []ServiceDiff{
+		{
+			Name:   "cache",
+			Change: ServiceRemoved,
+			Image:  &ValueChange{From: "redis:7", To: ""},
+		},
+		{
+			Name:   "db",
+			Change: ServiceAdded,
+			Image:  &ValueChange{From: "", To: "postgres:16"},
+		},
+		{
+			Name:        "web",
+			Change:      ServiceModified,
+			Image:       &ValueChange{From: "nginx:1.26", To: "nginx:1.27"},
+			Ports:       &ListChange{Added: []string{"443:443"}},
+			Volumes:     &ListChange{Removed: []string{"./html:/usr/share/nginx/html"}},
+			Environment: &NameChange{Added: []string{"LOG_LEVEL"}, Removed: []string{"DEBUG"}, Changed: []string{"TOKEN"}},
+			OtherKeys:   []string{"restart"},
+		},
+	}, diff.Services)
+}
+
+func TestDiffMasksEnvironmentValues(t *testing.T) {
+	live := "x-env: &env\n  DB_PASSWORD: hunter2\nservices:\n  db:\n    image: postgres:16\n    environment:\n      <<: *env\n" +
+		"      CERT: |\n        -----BEGIN KEY-----\n        abc\n      EMPTY:\n"
+	proposed := strings.Replace(live, "hunter2", "hunter3", 1) + "    restart: always\n"
+
+	diff, err := Diff(live, proposed)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"hunter2", "hunter3", "BEGIN KEY", "abc"} {
+		assert.NotContains(t, diff.Unified, secret)
+	}
+	assert.Contains(t, diff.Unified, "-  DB_PASSWORD: ********\n+  DB_PASSWORD: ******** (changed)\n")
+	assert.Contains(t, diff.Unified, "       EMPTY:\n+    restart: always\n")
+	assert.Equal(t, &NameChange{Changed: []string{"DB_PASSWORD"}}, diff.Services[0].Environment)
+}
+
+func TestDiffIdenticalFiles(t *testing.T) {
+	file := "services:\n  web:\n    image: nginx:1.27"
+
+	diff, err := Diff(file, file)
+	require.NoError(t, err)
+	assert.Empty(t, diff.Unified)
+	assert.Empty(t, diff.Services)
+}
+
+func TestDiffInvalidFile(t *testing.T) {
+	_, err := Diff("services:\n  web:\n    image: nginx", "services: [")
+	assert.ErrorContains(t, err, "failed to parse the proposed file")
+}
+
+func TestDiffValues(t *testing.T) {
+	change := DiffValues(
+		map[string]string{"A": "1", "B": "2", "C": "3"},
+		map[string]string{"A": "1", "B": "changed", "D": "4"},
+	)
+
+	assert.Equal(t, NameChange{Added: []string{"D"}, Removed: []string{"C"}, Changed: []string{"B"}}, change)
+	assert.False(t, change.Empty())
+	assert.True(t, DiffValues(map[string]string{"A": "1"}, map[string]string{"A": "1"}).Empty())
+}
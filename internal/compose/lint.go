@@ -0,0 +1,571 @@
+// Package compose validates Docker Compose files and Kubernetes manifests locally, before they are sent to Portainer.
+package compose
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity levels of the findings, from the most to the least severe.
+// Files with error findings would fail to deploy.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Rules reported in the findings
+const (
+	RuleSyntax            = "syntax"
+	RuleSchema            = "schema"
+	RuleUndefinedVariable = "undefined-variable"
+	RuleImageTag          = "image-tag"
+	RulePrivileged        = "privileged"
+	RuleBindMount         = "bind-mount"
+	RuleHealthcheck       = "healthcheck"
+	RuleNamespace         = "namespace"
+)
+
+// Finding is an issue found in a compose file
+type Finding struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Service  string `json:"service,omitempty"`
+	// Line is the line of the file the finding refers to, 0 if unknown
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the finding on a single line
+func (f Finding) String() string {
+	var location []string
+	if f.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d", f.Line))
+	}
+	if f.Service != "" {
+		location = append(location, "service "+f.Service)
+	}
+	if len(location) == 0 {
+		return fmt.Sprintf("%s [%s] %s", f.Severity, f.Rule, f.Message)
+	}
+	return fmt.Sprintf("%s [%s] %s: %s", f.Severity, f.Rule, strings.Join(location, ", "), f.Message)
+}
+
+// Report is the result of the validation of a compose file
+type Report struct {
+	// Valid is false when the report contains error findings
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+}
+
+// HasSeverity returns true if the report contains findings of the given severity
+func (r Report) HasSeverity(severity string) bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Options configures the validation of a compose file
+type Options struct {
+	// KnownVariables are the names of the environment variables defined for the stack.
+	// Variables used in the file are only checked when it is not nil.
+	KnownVariables []string
+}
+
+var topLevelKeys = map[string]bool{
+	"version": true, "name": true, "include": true, "services": true, "networks": true,
+	"volumes": true, "configs": true, "secrets": true, "models": true,
+}
+
+var serviceKeys = map[string]bool{
+	"annotations": true, "attach": true, "blkio_config": true, "build": true, "cap_add": true,
+	"cap_drop": true, "cgroup": true, "cgroup_parent": true, "command": true, "configs": true,
+	"container_name": true, "cpu_count": true, "cpu_percent": true, "cpu_period": true, "cpu_quota": true,
+	"cpu_rt_period": true, "cpu_rt_runtime": true, "cpu_shares": true, "cpus": true, "cpuset": true,
+	"credential_spec": true, "depends_on": true, "deploy": true, "develop": true, "device_cgroup_rules": true,
+	"devices": true, "dns": true, "dns_opt": true, "dns_search": true, "domainname": true,
+	"driver_opts": true, "entrypoint": true, "env_file": true, "environment": true, "expose": true,
+	"extends": true, "external_links": true, "extra_hosts": true, "gpus": true, "group_add": true,
+	"healthcheck": true, "hostname": true, "image": true, "init": true, "ipc": true,
+	"isolation": true, "labels": true, "label_file": true, "links": true, "logging": true,
+	"mac_address": true, "mem_limit": true, "mem_reservation": true, "mem_swappiness": true, "memswap_limit": true,
+	"models": true, "network_mode": true, "networks": true, "oom_kill_disable": true, "oom_score_adj": true,
+	"pid": true, "pids_limit": true, "platform": true, "ports": true, "post_start": true,
+	"pre_stop": true, "privileged": true, "profiles": true, "provider": true, "pull_policy": true,
+	"read_only": true, "restart": true, "runtime": true, "scale": true, "secrets": true,
+	"security_opt": true, "shm_size": true, "stdin_open": true, "stop_grace_period": true, "stop_signal": true,
+	"storage_opt": true, "sysctls": true, "tmpfs": true, "tty": true, "ulimits": true,
+	"use_api_socket": true, "user": true, "userns_mode": true, "uts": true, "volumes": true,
+	"volumes_from": true, "working_dir": true,
+}
+
+// Expected YAML node kinds of the service keys that are inspected by the validation
+var serviceKeyKinds = map[string][]yaml.Kind{
+	"image":       {yaml.ScalarNode},
+	"ports":       {yaml.SequenceNode},
+	"volumes":     {yaml.SequenceNode},
+	"environment": {yaml.MappingNode, yaml.SequenceNode},
+	"healthcheck": {yaml.MappingNode},
+	"privileged":  {yaml.ScalarNode},
+	"depends_on":  {yaml.SequenceNode, yaml.MappingNode},
+	"networks":    {yaml.SequenceNode, yaml.MappingNode},
+	"labels":      {yaml.MappingNode, yaml.SequenceNode},
+}
+
+// variablePattern matches $$ escapes, ${NAME}, ${NAME<modifier>...} and $NAME
+var variablePattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:?[-?+][^}]*)?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Validate parses a compose file and reports its schema errors along with risky or fragile settings:
+// undefined variables, untagged or latest images, privileged containers, host bind mounts and missing healthchecks.
+func Validate(file string, opts Options) Report {
+	v := &validator{}
+	v.validate(file, opts)
+
+	return v.report()
+}
+
+// report returns the findings sorted by severity then line
+func (v *validator) report() Report {
+	sort.SliceStable(v.findings, func(i, j int) bool {
+		a, b := v.findings[i], v.findings[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) < severityRank(b.Severity)
+		}
+		return a.Line < b.Line
+	})
+
+	return Report{
+		Valid:    !Report{Findings: v.findings}.HasSeverity(SeverityError),
+		Findings: v.findings,
+	}
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type validator struct {
+	findings []Finding
+}
+
+func (v *validator) add(severity, rule, service string, line int, format string, args ...any) {
+	v.findings = append(v.findings, Finding{
+		Severity: severity,
+		Rule:     rule,
+		Service:  service,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(file string, opts Options) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(file), &document); err != nil {
+		v.add(SeverityError, RuleSyntax, "", 0, "invalid YAML: %v", err)
+		return
+	}
+	if len(document.Content) == 0 {
+		v.add(SeverityError, RuleSchema, "", 0, "the file is empty")
+		return
+	}
+
+	// Anchors and merge keys are commonly used to share settings between services
+	root := resolveAliases(document.Content[0], map[*yaml.Node]*yaml.Node{})
+	if root.Kind != yaml.MappingNode {
+		v.add(SeverityError, RuleSchema, "", root.Line, "the top level of the file must be a mapping")
+		return
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if !topLevelKeys[key.Value] && !strings.HasPrefix(key.Value, "x-") {
+			v.add(SeverityError, RuleSchema, "", key.Line, "unknown top-level key %q", key.Value)
+		}
+	}
+
+	if opts.KnownVariables != nil {
+		v.checkVariables(root, opts.KnownVariables)
+	}
+
+	services := mappingValue(root, "services")
+	if services == nil {
+		if mappingValue(root, "include") == nil {
+			v.add(SeverityError, RuleSchema, "", root.Line, "the file does not define any services")
+		}
+		return
+	}
+	if services.Kind != yaml.MappingNode || len(services.Content) == 0 {
+		v.add(SeverityError, RuleSchema, "", services.Line, "services must be a non-empty mapping of service names to definitions")
+		return
+	}
+
+	serviceNames := mappingKeys(services)
+	volumeNames := mappingKeys(mappingValue(root, "volumes"))
+	networkNames := mappingKeys(mappingValue(root, "networks"))
+	networkNames["default"] = true
+
+	for i := 0; i < len(services.Content); i += 2 {
+		key, service := services.Content[i], services.Content[i+1]
+		if service.Kind != yaml.MappingNode {
+			v.add(SeverityError, RuleSchema, key.Value, key.Line, "the service definition must be a mapping")
+			continue
+		}
+		v.checkService(key, service, serviceNames, volumeNames, networkNames)
+	}
+}
+
+func (v *validator) checkService(nameNode, service *yaml.Node, serviceNames, volumeNames, networkNames map[string]bool) {
+	name := nameNode.Value
+	validKinds := true
+	for i := 0; i < len(service.Content); i += 2 {
+		key, value := service.Content[i], service.Content[i+1]
+		if !serviceKeys[key.Value] && !strings.HasPrefix(key.Value, "x-") {
+			v.add(SeverityError, RuleSchema, name, key.Line, "unknown service key %q", key.Value)
+			continue
+		}
+		if kinds, ok := serviceKeyKinds[key.Value]; ok && !hasKind(value, kinds) {
+			v.add(SeverityError, RuleSchema, name, value.Line, "invalid type for %s", key.Value)
+			validKinds = false
+		}
+	}
+	if !validKinds {
+		return
+	}
+
+	image := mappingValue(service, "image")
+	if image == nil && mappingValue(service, "build") == nil {
+		v.add(SeverityError, RuleSchema, name, nameNode.Line, "the service has neither an image nor a build section")
+	}
+	if image != nil {
+		v.checkImage(name, image)
+	}
+
+	if privileged := mappingValue(service, "privileged"); privileged != nil && privileged.Value == "true" {
+		v.add(SeverityWarning, RulePrivileged, name, privileged.Line, "the container runs in privileged mode and has full access to the host")
+	}
+
+	if volumes := mappingValue(service, "volumes"); volumes != nil {
+		for _, volume := range volumes.Content {
+			v.checkVolume(name, volume, volumeNames)
+		}
+	}
+
+	if dependsOn := mappingValue(service, "depends_on"); dependsOn != nil {
+		for _, dependency := range referencedNames(dependsOn) {
+			if !serviceNames[dependency.Value] {
+				v.add(SeverityError, RuleSchema, name, dependency.Line, "depends on undefined service %q", dependency.Value)
+			}
+		}
+	}
+
+	if networks := mappingValue(service, "networks"); networks != nil {
+		for _, network := range referencedNames(networks) {
+			if !networkNames[network.Value] && !strings.Contains(network.Value, "$") {
+				v.add(SeverityError, RuleSchema, name, network.Line, "refers to undefined network %q", network.Value)
+			}
+		}
+	}
+
+	healthcheck := mappingValue(service, "healthcheck")
+	switch {
+	case healthcheck == nil:
+		v.add(SeverityInfo, RuleHealthcheck, name, nameNode.Line, "the service does not define a healthcheck, its status only reflects whether the process is running unless the image defines one")
+	case isHealthcheckDisabled(healthcheck):
+		v.add(SeverityInfo, RuleHealthcheck, name, healthcheck.Line, "the healthcheck of the service is disabled")
+	}
+}
+
+func (v *validator) checkImage(service string, image *yaml.Node) {
+	ref := image.Value
+	if ref == "" {
+		v.add(SeverityError, RuleSchema, service, image.Line, "the image is empty")
+		return
+	}
+	if strings.Contains(ref, "$") || strings.Contains(ref, "@") {
+		return
+	}
+
+	tag := ""
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		tag = ref[i+1:]
+	}
+
+	switch tag {
+	case "":
+		v.add(SeverityWarning, RuleImageTag, service, image.Line, "image %s has no tag, the latest version is pulled on each deployment", ref)
+	case "latest":
+		v.add(SeverityWarning, RuleImageTag, service, image.Line, "image %s uses the latest tag, the deployed version can change on each deployment", ref)
+	}
+}
+
+func (v *validator) checkVolume(service string, volume *yaml.Node, volumeNames map[string]bool) {
+	var source, volumeType string
+	switch volume.Kind {
+	case yaml.ScalarNode:
+		parts := strings.Split(volume.Value, ":")
+		if len(parts) < 2 {
+			// anonymous volume
+			return
+		}
+		source = parts[0]
+		if isHostPath(source) {
+			volumeType = "bind"
+		} else {
+			volumeType = "volume"
+		}
+	case yaml.MappingNode:
+		if typeNode := mappingValue(volume, "type"); typeNode != nil {
+			volumeType = typeNode.Value
+		}
+		if sourceNode := mappingValue(volume, "source"); sourceNode != nil {
+			source = sourceNode.Value
+		}
+	default:
+		v.add(SeverityError, RuleSchema, service, volume.Line, "invalid volume definition")
+		return
+	}
+
+	switch {
+	case volumeType == "bind" && source == "/var/run/docker.sock":
+		v.add(SeverityWarning, RuleBindMount, service, volume.Line, "the container mounts the Docker socket and has full control of the Docker host")
+	case volumeType == "bind":
+		v.add(SeverityWarning, RuleBindMount, service, volume.Line, "the container mounts the host path %s, the stack depends on the layout of the host", source)
+	case volumeType == "volume" && source != "" && !volumeNames[source] && !strings.Contains(source, "$"):
+		v.add(SeverityError, RuleSchema, service, volume.Line, "refers to undefined volume %q, declare it in the top-level volumes section", source)
+	}
+}
+
+func (v *validator) checkVariables(root *yaml.Node, knownVariables []string) {
+	known := make(map[string]bool, len(knownVariables))
+	for _, name := range knownVariables {
+		known[name] = true
+	}
+
+	// Only the scalar values are interpolated, so references in comments are ignored
+	reported := map[string]bool{}
+	for _, scalar := range scalarValues(root, nil, map[*yaml.Node]bool{}) {
+		value := scalar.Value
+		for _, match := range variablePattern.FindAllStringSubmatchIndex(value, -1) {
+			if value[match[0]:match[1]] == "$$" {
+				continue
+			}
+
+			var name, modifier string
+			if match[2] >= 0 {
+				name = value[match[2]:match[3]]
+				if match[4] >= 0 {
+					modifier = strings.TrimPrefix(value[match[4]:match[5]], ":")[:1]
+				}
+			} else {
+				name = value[match[6]:match[7]]
+			}
+
+			if known[name] || reported[name] {
+				continue
+			}
+
+			line := scalar.Line + strings.Count(value[:match[0]], "\n")
+			if scalar.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				// the content of block scalars starts on the line after the indicator
+				line++
+			}
+			switch modifier {
+			case "-", "+":
+				// a default or alternative value is used when the variable is not defined
+				continue
+			case "?":
+				v.add(SeverityError, RuleUndefinedVariable, "", line, "variable %s is required but not defined for the stack", name)
+			default:
+				v.add(SeverityWarning, RuleUndefinedVariable, "", line, "variable %s is not defined for the stack and is replaced with an empty string", name)
+			}
+			reported[name] = true
+		}
+	}
+}
+
+// scalarValues appends the scalar values of a node to values in document order, skipping the keys of mappings.
+// Nodes shared by several aliases are visited once.
+func scalarValues(node *yaml.Node, values []*yaml.Node, visited map[*yaml.Node]bool) []*yaml.Node {
+	if visited[node] {
+		return values
+	}
+	visited[node] = true
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		values = append(values, node)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			values = scalarValues(node.Content[i], values, visited)
+		}
+	default:
+		for _, child := range node.Content {
+			values = scalarValues(child, values, visited)
+		}
+	}
+	return values
+}
+
+// mergeTag is the tag of the YAML merge key (<<), which inserts the keys of other mappings into a mapping
+const mergeTag = "!!merge"
+
+// resolveAliases returns a copy of a node in which aliases are replaced by the nodes they refer to, and the merge
+// keys of mappings by the keys they merge, so that the file is checked as Docker Compose reads it.
+// Each node is resolved once and shared by all the aliases referring to it.
+func resolveAliases(node *yaml.Node, resolved map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return resolveAliases(node.Alias, resolved)
+	}
+	if copied, ok := resolved[node]; ok {
+		return copied
+	}
+
+	copied := *node
+	resolved[node] = &copied
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			copied.Content[i] = resolveAliases(child, resolved)
+		}
+	case yaml.MappingNode:
+		copied.Content = mergeMapping(node, resolved)
+	}
+
+	return &copied
+}
+
+// mergeMapping returns the resolved keys and values of a mapping with its merge keys expanded.
+// The keys of the mapping take precedence over the merged ones, and when a sequence of mappings is merged,
+// the first mappings take precedence over the next ones.
+func mergeMapping(node *yaml.Node, resolved map[*yaml.Node]*yaml.Node) []*yaml.Node {
+	var content, merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], resolveAliases(node.Content[i+1], resolved)
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == mergeTag {
+			if pairs, ok := mergedPairs(value); ok {
+				merged = append(merged, pairs...)
+				continue
+			}
+		}
+		content = append(content, resolveAliases(key, resolved), value)
+	}
+
+	keys := map[string]bool{}
+	for i := 0; i < len(content); i += 2 {
+		keys[content[i].Value] = true
+	}
+	for i := 0; i < len(merged); i += 2 {
+		if !keys[merged[i].Value] {
+			keys[merged[i].Value] = true
+			content = append(content, merged[i], merged[i+1])
+		}
+	}
+
+	return content
+}
+
+// mergedPairs returns the keys and values inserted by a merge key, whose value must be a mapping or a sequence
+// of mappings. It returns false for any other value, which is then checked as a regular key.
+func mergedPairs(value *yaml.Node) ([]*yaml.Node, bool) {
+	switch value.Kind {
+	case yaml.MappingNode:
+		return value.Content, true
+	case yaml.SequenceNode:
+		var pairs []*yaml.Node
+		for _, item := range value.Content {
+			if item.Kind != yaml.MappingNode {
+				return nil, false
+			}
+			pairs = append(pairs, item.Content...)
+		}
+		return pairs, true
+	default:
+		return nil, false
+	}
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil if the key does not exist
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKeys returns the set of the keys of a mapping node
+func mappingKeys(node *yaml.Node) map[string]bool {
+	keys := map[string]bool{}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return keys
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		keys[node.Content[i].Value] = true
+	}
+	return keys
+}
+
+// referencedNames returns the names listed in a sequence node, or the keys of a mapping node
+func referencedNames(node *yaml.Node) []*yaml.Node {
+	var names []*yaml.Node
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				names = append(names, item)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			names = append(names, node.Content[i])
+		}
+	}
+	return names
+}
+
+func hasKind(node *yaml.Node, kinds []yaml.Kind) bool {
+	for _, kind := range kinds {
+		if node.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~")
+}
+
+func isHealthcheckDisabled(healthcheck *yaml.Node) bool {
+	if disable := mappingValue(healthcheck, "disable"); disable != nil && disable.Value == "true" {
+		return true
+	}
+	test := mappingValue(healthcheck, "test")
+	if test == nil {
+		return false
+	}
+	if test.Kind == yaml.SequenceNode && len(test.Content) > 0 {
+		return test.Content[0].Value == "NONE"
+	}
+	return test.Value == "NONE"
+}
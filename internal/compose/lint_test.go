@@ -0,0 +1,166 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// healthy is appended to the services of the test files that are not about healthchecks
+const healthy = "\n    healthcheck:\n      test: [\"CMD\", \"true\"]"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name             string
+		file             string
+		opts             Options
+		expectedValid    bool
+		expectedFindings []Finding
+	}{
+		{
+			name:          "valid file",
+			file:          "services:\n  web:\n    image: nginx:1.27\n    ports:\n      - \"80:80\"" + healthy,
+			expectedValid: true,
+		},
+		{
+			name:          "invalid yaml",
+			file:          "services:\n  web:\n    image: [nginx",
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleSyntax},
+			},
+		},
+		{
+			name:          "schema errors",
+			file:          "service:\n  web: {}\nservices:\n  web:\n    imag: nginx:1.27\n  db:\n    image: postgres:16\n    ports: 5432" + healthy,
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleSchema, Line: 1},
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 4},
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 5},
+				{Severity: SeverityError, Rule: RuleSchema, Service: "db", Line: 8},
+				{Severity: SeverityInfo, Rule: RuleHealthcheck, Service: "web", Line: 4},
+			},
+		},
+		{
+			name:          "undefined references",
+			file:          "services:\n  web:\n    image: nginx:1.27\n    depends_on: [db]\n    networks: [front]\n    volumes:\n      - data:/data" + healthy,
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 4},
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 5},
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 7},
+			},
+		},
+		{
+			name: "variables",
+			file: "services:\n  web:\n    image: nginx:${TAG}\n    environment:\n      TOKEN: ${TOKEN:?token is required}\n" +
+				"      MODE: ${MODE:-prod}\n      HOST: $HOST\n      PRICE: $$5" + healthy,
+			opts:          Options{KnownVariables: []string{"HOST"}},
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleUndefinedVariable, Line: 5},
+				{Severity: SeverityWarning, Rule: RuleUndefinedVariable, Line: 3},
+			},
+		},
+		{
+			name: "variables in comments and block scalars",
+			file: "# image: nginx:${TAG:?tag is required}\nservices:\n  web:\n    image: nginx:1.27 # ${COMMENTED}\n" +
+				"    command: |\n      run\n      --host $HOST\n      --port ${PORT}" + healthy,
+			opts:          Options{KnownVariables: []string{"HOST"}},
+			expectedValid: true,
+			expectedFindings: []Finding{
+				{Severity: SeverityWarning, Rule: RuleUndefinedVariable, Line: 8},
+			},
+		},
+		{
+			name:          "variables not checked without known variables",
+			file:          "services:\n  web:\n    image: nginx:${TAG}" + healthy,
+			expectedValid: true,
+		},
+		{
+			name:          "image tags",
+			file:          "services:\n  web:\n    image: nginx" + healthy + "\n  api:\n    image: registry.local:5000/api:latest" + healthy + "\n  db:\n    image: postgres@sha256:abc" + healthy,
+			expectedValid: true,
+			expectedFindings: []Finding{
+				{Severity: SeverityWarning, Rule: RuleImageTag, Service: "web", Line: 3},
+				{Severity: SeverityWarning, Rule: RuleImageTag, Service: "api", Line: 7},
+			},
+		},
+		{
+			name: "privileged containers and bind mounts",
+			file: "services:\n  agent:\n    image: portainer/agent:2.31.2\n    privileged: true\n    volumes:\n" +
+				"      - /var/run/docker.sock:/var/run/docker.sock\n      - ./config:/config:ro\n      - type: bind\n        source: /srv\n        target: /srv\n" +
+				"      - data:/data\n      - /cache\nvolumes:\n  data: {}" + "\nx-note: ok",
+			expectedValid: true,
+			expectedFindings: []Finding{
+				{Severity: SeverityWarning, Rule: RulePrivileged, Service: "agent", Line: 4},
+				{Severity: SeverityWarning, Rule: RuleBindMount, Service: "agent", Line: 6},
+				{Severity: SeverityWarning, Rule: RuleBindMount, Service: "agent", Line: 7},
+				{Severity: SeverityWarning, Rule: RuleBindMount, Service: "agent", Line: 8},
+				{Severity: SeverityInfo, Rule: RuleHealthcheck, Service: "agent", Line: 2},
+			},
+		},
+		{
+			name:          "disabled healthcheck",
+			file:          "services:\n  web:\n    image: nginx:1.27\n    healthcheck:\n      disable: true",
+			expectedValid: true,
+			expectedFindings: []Finding{
+				{Severity: SeverityInfo, Rule: RuleHealthcheck, Service: "web", Line: 5},
+			},
+		},
+		{
+			name: "anchors and merge keys",
+			file: "x-defaults: &defaults\n  image: nginx:1.27\n  restart: always\n  healthcheck:\n    test: [\"CMD\", \"true\"]\n" +
+				"services:\n  web:\n    <<: *defaults\n    ports: [\"80:80\"]\n  api:\n    <<: [*defaults]\n    image: api:1.0\n  worker: *defaults",
+			expectedValid: true,
+		},
+		{
+			name: "merged keys are checked",
+			file: "x-base: &base\n  image: nginx\n  privileged: true\n  imag: typo\n" +
+				"services:\n  web:\n    <<: *base\n    healthcheck:\n      disable: true",
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleSchema, Service: "web", Line: 4},
+				{Severity: SeverityWarning, Rule: RuleImageTag, Service: "web", Line: 2},
+				{Severity: SeverityWarning, Rule: RulePrivileged, Service: "web", Line: 3},
+				{Severity: SeverityInfo, Rule: RuleHealthcheck, Service: "web", Line: 9},
+			},
+		},
+		{
+			name:          "no services",
+			file:          "version: '3'",
+			expectedValid: false,
+			expectedFindings: []Finding{
+				{Severity: SeverityError, Rule: RuleSchema, Line: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Validate(tt.file, tt.opts)
+
+			assert.Equal(t, tt.expectedValid, report.Valid)
+
+			findings := make([]Finding, len(report.Findings))
+			for i, finding := range report.Findings {
+				assert.NotEmpty(t, finding.Message)
+				finding.Message = ""
+				findings[i] = finding
+			}
+			if tt.expectedFindings == nil {
+				tt.expectedFindings = []Finding{}
+			}
+			assert.Equal(t, tt.expectedFindings, findings)
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	finding := Finding{Severity: SeverityWarning, Rule: RuleImageTag, Service: "web", Line: 3, Message: "image nginx has no tag"}
+	assert.Equal(t, "warning [image-tag] line 3, service web: image nginx has no tag", finding.String())
+
+	finding = Finding{Severity: SeverityError, Rule: RuleSyntax, Message: "invalid YAML"}
+	assert.Equal(t, "error [syntax] invalid YAML", finding.String())
+}
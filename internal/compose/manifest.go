@@ -0,0 +1,91 @@
+package compose
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateManifest parses a multi-document Kubernetes manifest and reports the documents that are not
+// valid resources, along with the resources targeting another namespace than the one of the stack.
+// The namespace is not checked when it is empty.
+func ValidateManifest(file, namespace string) Report {
+	v := &validator{}
+	v.validateManifest(file, namespace)
+
+	return v.report()
+}
+
+func (v *validator) validateManifest(file, namespace string) {
+	decoder := yaml.NewDecoder(strings.NewReader(file))
+
+	resources := 0
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			v.add(SeverityError, RuleSyntax, "", 0, "invalid YAML: %v", err)
+			return
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+
+		root := document.Content[0]
+		if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+			continue
+		}
+		if root.Kind != yaml.MappingNode {
+			v.add(SeverityError, RuleSchema, "", root.Line, "each document of the manifest must be a mapping")
+			continue
+		}
+
+		resources++
+		v.checkResource(root, namespace)
+	}
+
+	if resources == 0 {
+		v.add(SeverityError, RuleSchema, "", 0, "the manifest does not define any resources")
+	}
+}
+
+// checkResource checks that a document of a manifest identifies a Kubernetes resource
+func (v *validator) checkResource(resource *yaml.Node, namespace string) {
+	kind := scalarValue(mappingValue(resource, "kind"))
+	if kind == "" {
+		v.add(SeverityError, RuleSchema, "", resource.Line, "the resource does not define its kind")
+		kind = "resource"
+	}
+	if scalarValue(mappingValue(resource, "apiVersion")) == "" {
+		v.add(SeverityError, RuleSchema, "", resource.Line, "%s does not define its apiVersion", kind)
+	}
+
+	metadata := mappingValue(resource, "metadata")
+	name := scalarValue(mappingValue(metadata, "name"))
+	if name == "" {
+		name = scalarValue(mappingValue(metadata, "generateName"))
+	}
+	if name == "" {
+		v.add(SeverityError, RuleSchema, "", resource.Line, "%s does not define metadata.name", kind)
+		return
+	}
+
+	resourceNamespace := mappingValue(metadata, "namespace")
+	if namespace != "" && resourceNamespace != nil && resourceNamespace.Value != namespace {
+		v.add(SeverityError, RuleNamespace, "", resourceNamespace.Line,
+			"%s/%s targets namespace %q but the stack is deployed to namespace %q", kind, name, resourceNamespace.Value, namespace)
+	}
+}
+
+// scalarValue returns the value of a scalar node, or an empty string if the node is not a scalar
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
@@ -0,0 +1,105 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+`
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		namespace string
+		valid     bool
+		rule      string
+		line      int
+		message   string
+	}{
+		{
+			name:      "valid manifest",
+			file:      testManifest,
+			namespace: "shop",
+			valid:     true,
+		},
+		{
+			name:  "namespace not checked",
+			file:  testManifest,
+			valid: true,
+		},
+		{
+			name:      "other namespace",
+			file:      testManifest,
+			namespace: "staging",
+			rule:      RuleNamespace,
+			line:      12,
+			message:   `Service/web targets namespace "shop" but the stack is deployed to namespace "staging"`,
+		},
+		{
+			name:    "invalid yaml",
+			file:    "kind: [",
+			rule:    RuleSyntax,
+			message: "invalid YAML",
+		},
+		{
+			name:    "empty manifest",
+			file:    "---\n---\n",
+			rule:    RuleSchema,
+			message: "the manifest does not define any resources",
+		},
+		{
+			name:    "missing name",
+			file:    "apiVersion: v1\nkind: ConfigMap\n",
+			rule:    RuleSchema,
+			line:    1,
+			message: "ConfigMap does not define metadata.name",
+		},
+		{
+			name:    "missing kind and apiVersion",
+			file:    "metadata:\n  name: web\n",
+			rule:    RuleSchema,
+			line:    1,
+			message: "the resource does not define its kind",
+		},
+		{
+			name:    "not a mapping",
+			file:    testManifest + "---\n- web\n",
+			rule:    RuleSchema,
+			line:    14,
+			message: "each document of the manifest must be a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := ValidateManifest(tt.file, tt.namespace)
+			assert.Equal(t, tt.valid, report.Valid)
+			if tt.valid {
+				assert.Empty(t, report.Findings)
+				return
+			}
+
+			require.NotEmpty(t, report.Findings)
+			finding := report.Findings[0]
+			assert.Equal(t, SeverityError, finding.Severity)
+			assert.Equal(t, tt.rule, finding.Rule)
+			assert.Equal(t, tt.line, finding.Line)
+			assert.Contains(t, finding.Message, tt.message)
+		})
+	}
+}
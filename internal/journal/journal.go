@@ -0,0 +1,168 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Entry records a single write performed through the MCP server,
+// with the state of the resource before and after the write.
+type Entry struct {
+	// ID is the sequential identifier of the entry
+	ID int `json:"id"`
+	// Tool is the name of the tool that performed the write
+	Tool string `json:"tool"`
+	// Kind identifies the type of resource and the part of it that was changed (e.g. "environment.tags")
+	Kind string `json:"kind"`
+	// ResourceID is the ID of the resource that was changed
+	ResourceID int `json:"resource_id"`
+	// Before is the state of the resource before the write
+	Before json.RawMessage `json:"before"`
+	// After is the state of the resource after the write
+	After json.RawMessage `json:"after"`
+	// CreatedAt is the time at which the write was performed
+	CreatedAt time.Time `json:"created_at"`
+	// RevertedBy is the ID of the entry that reverted this one, if any
+	RevertedBy int `json:"reverted_by,omitempty"`
+	// Reverts is the ID of the entry reverted by this one, if any
+	Reverts int `json:"reverts,omitempty"`
+}
+
+// Journal is an append-only log of changes, persisted as a JSON file.
+// A Journal created with an empty path is kept in memory only.
+type Journal struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries []Entry
+	nextID  int
+}
+
+// Open loads the journal stored at path, or creates an empty one if the file does not exist.
+// The parent directory is created if needed.
+func Open(path string) (*Journal, error) {
+	j := &Journal{
+		path:   path,
+		now:    time.Now,
+		nextID: 1,
+	}
+
+	if path == "" {
+		return j, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+
+	for _, entry := range j.entries {
+		j.nextID = max(j.nextID, entry.ID+1)
+	}
+
+	return j, nil
+}
+
+// Record appends a new entry to the journal and returns it with its ID and creation time set.
+// If the entry reverts another entry, that entry is marked as reverted.
+func (j *Journal) Record(entry Entry) (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.ID = j.nextID
+	entry.CreatedAt = j.now().UTC()
+
+	entries := append(slices.Clone(j.entries), entry)
+	if entry.Reverts != 0 {
+		index := j.indexOf(entry.Reverts)
+		if index < 0 {
+			return Entry{}, fmt.Errorf("journal entry %d not found", entry.Reverts)
+		}
+		entries[index].RevertedBy = entry.ID
+	}
+
+	if err := j.save(entries); err != nil {
+		return Entry{}, err
+	}
+
+	j.entries = entries
+	j.nextID++
+
+	return entry, nil
+}
+
+// List returns all the entries of the journal, most recent first
+func (j *Journal) List() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]Entry, len(j.entries))
+	for i, entry := range j.entries {
+		entries[len(j.entries)-1-i] = entry
+	}
+
+	return entries
+}
+
+// Get returns the entry with the given ID
+func (j *Journal) Get(id int) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	index := j.indexOf(id)
+	if index < 0 {
+		return Entry{}, false
+	}
+
+	return j.entries[index], true
+}
+
+// indexOf returns the index of the entry with the given ID, or -1. The caller must hold j.mu.
+func (j *Journal) indexOf(id int) int {
+	for i, entry := range j.entries {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// save writes the entries to the journal file, replacing it atomically. The caller must hold j.mu.
+func (j *Journal) save(entries []Entry) error {
+	if j.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalInMemory(t *testing.T) {
+	j, err := Open("")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+
+	first, err := j.Record(Entry{Tool: "updateTeamMembers", Kind: "team.members", ResourceID: 3, Before: json.RawMessage(`[1]`), After: json.RawMessage(`[2]`)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, now, first.CreatedAt)
+
+	second, err := j.Record(Entry{Tool: "revertChange", Kind: "team.members", ResourceID: 3, Before: json.RawMessage(`[2]`), After: json.RawMessage(`[1]`), Reverts: first.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.ID)
+
+	entries := j.List()
+	require.Len(t, entries, 2)
+	assert.Equal(t, 2, entries[0].ID, "entries must be listed most recent first")
+	assert.Equal(t, 1, entries[1].ID)
+
+	reverted, ok := j.Get(first.ID)
+	require.True(t, ok)
+	assert.Equal(t, second.ID, reverted.RevertedBy)
+
+	_, ok = j.Get(42)
+	assert.False(t, ok)
+}
+
+func TestJournalRecordUnknownRevert(t *testing.T) {
+	j, err := Open("")
+	require.NoError(t, err)
+
+	_, err = j.Record(Entry{Kind: "user.role", ResourceID: 1, Reverts: 7})
+	assert.Error(t, err)
+	assert.Empty(t, j.List(), "a failed record must not modify the journal")
+
+	entry, err := j.Record(Entry{Kind: "user.role", ResourceID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.ID, "a failed record must not consume an ID")
+}
+
+func TestJournalPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "journal.json")
+
+	j, err := Open(path)
+	require.NoError(t, err)
+
+	_, err = j.Record(Entry{Tool: "updateUserRole", Kind: "user.role", ResourceID: 1, Before: json.RawMessage(`"user"`), After: json.RawMessage(`"admin"`)})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	entries := reopened.List()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "user.role", entries[0].Kind)
+	assert.JSONEq(t, `"user"`, string(entries[0].Before))
+
+	entry, err := reopened.Record(Entry{Kind: "user.role", ResourceID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, entry.ID, "IDs must continue after the loaded entries")
+}
+
+func TestOpenInvalidJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := Open(path)
+	assert.Error(t, err)
+}
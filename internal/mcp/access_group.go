@@ -27,7 +27,7 @@ func (s *PortainerMCPServer) HandleGetAccessGroups() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		accessGroups, err := s.cli.GetAccessGroups()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+			return newToolResultAPIError("failed to get access groups", err), nil
 		}
 
 		data, err := json.Marshal(accessGroups)
@@ -55,7 +55,7 @@ func (s *PortainerMCPServer) HandleCreateAccessGroup() server.ToolHandlerFunc {
 
 		groupID, err := s.cli.CreateAccessGroup(name, environmentIds)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to create access group", err), nil
+			return newToolResultAPIError("failed to create access group", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Access group created successfully with ID: %d", groupID)), nil
@@ -76,12 +76,14 @@ func (s *PortainerMCPServer) HandleUpdateAccessGroupName() server.ToolHandlerFun
 			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindAccessGroupName, id)
+
 		err = s.cli.UpdateAccessGroupName(id, name)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update access group name", err), nil
+			return newToolResultAPIError("failed to update access group name", err), nil
 		}
 
-		return mcp.NewToolResultText("Access group name updated successfully"), nil
+		return mcp.NewToolResultText("Access group name updated successfully" + s.recordChange(ToolUpdateAccessGroupName, change)), nil
 	}
 }
 
@@ -104,12 +106,14 @@ func (s *PortainerMCPServer) HandleUpdateAccessGroupUserAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid user accesses", err), nil
 		}
 
+		change := s.captureChange(ChangeKindAccessGroupUserAccesses, id)
+
 		err = s.cli.UpdateAccessGroupUserAccesses(id, userAccessesMap)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update access group user accesses", err), nil
+			return newToolResultAPIError("failed to update access group user accesses", err), nil
 		}
 
-		return mcp.NewToolResultText("Access group user accesses updated successfully"), nil
+		return mcp.NewToolResultText("Access group user accesses updated successfully" + s.recordChange(ToolUpdateAccessGroupUserAccesses, change)), nil
 	}
 }
 
@@ -132,12 +136,14 @@ func (s *PortainerMCPServer) HandleUpdateAccessGroupTeamAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid team accesses", err), nil
 		}
 
+		change := s.captureChange(ChangeKindAccessGroupTeamAccesses, id)
+
 		err = s.cli.UpdateAccessGroupTeamAccesses(id, teamAccessesMap)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update access group team accesses", err), nil
+			return newToolResultAPIError("failed to update access group team accesses", err), nil
 		}
 
-		return mcp.NewToolResultText("Access group team accesses updated successfully"), nil
+		return mcp.NewToolResultText("Access group team accesses updated successfully" + s.recordChange(ToolUpdateAccessGroupTeamAccesses, change)), nil
 	}
 }
 
@@ -155,12 +161,14 @@ func (s *PortainerMCPServer) HandleAddEnvironmentToAccessGroup() server.ToolHand
 			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindAccessGroupEnvironments, id)
+
 		err = s.cli.AddEnvironmentToAccessGroup(id, environmentId)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to add environment to access group", err), nil
+			return newToolResultAPIError("failed to add environment to access group", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment added to access group successfully"), nil
+		return mcp.NewToolResultText("Environment added to access group successfully" + s.recordChange(ToolAddEnvironmentToAccessGroup, change)), nil
 	}
 }
 
@@ -178,11 +186,13 @@ func (s *PortainerMCPServer) HandleRemoveEnvironmentFromAccessGroup() server.Too
 			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindAccessGroupEnvironments, id)
+
 		err = s.cli.RemoveEnvironmentFromAccessGroup(id, environmentId)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to remove environment from access group", err), nil
+			return newToolResultAPIError("failed to remove environment from access group", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment removed from access group successfully"), nil
+		return mcp.NewToolResultText("Environment removed from access group successfully" + s.recordChange(ToolRemoveEnvironmentFromAccessGroup, change)), nil
 	}
 }
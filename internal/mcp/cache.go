@@ -0,0 +1,19 @@
+package mcp
+
+import (
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// refreshCache invalidates the cached lists for keys when the optional refresh parameter is set,
+// so that the next list call is sent to the Portainer server
+func (s *PortainerMCPServer) refreshCache(parser *toolgen.ParameterParser, keys ...string) error {
+	refresh, err := parser.GetBoolean("refresh", false)
+	if err != nil {
+		return err
+	}
+
+	if refresh {
+		s.cli.InvalidateCache(keys...)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListToolsRefreshCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]any
+		expectError   bool
+		expectRefresh bool
+	}{
+		{
+			name:   "cached list",
+			params: map[string]any{},
+		},
+		{
+			name:          "refresh",
+			params:        map[string]any{"refresh": true},
+			expectRefresh: true,
+		},
+		{
+			name:        "invalid refresh parameter",
+			params:      map[string]any{"refresh": "yes"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironments").Return([]models.Environment{{ID: 1, Name: "env"}}, nil)
+			mockClient.On("InvalidateCache", []string{client.CacheKeyEnvironments}).Return()
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetEnvironments()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectError, result.IsError)
+
+			if tt.expectRefresh {
+				mockClient.AssertCalled(t, "InvalidateCache", []string{client.CacheKeyEnvironments})
+			} else {
+				mockClient.AssertNotCalled(t, "InvalidateCache", []string{client.CacheKeyEnvironments})
+			}
+			if tt.expectError {
+				mockClient.AssertNotCalled(t, "GetEnvironments")
+			}
+		})
+	}
+}
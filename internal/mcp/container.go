@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// containerFilters are the filters supported by GET /containers/json
+var containerFilters = []string{
+	"ancestor", "before", "expose", "exited", "health", "id", "isolation", "is-task",
+	"label", "name", "network", "publish", "since", "status", "volume",
+}
+
+// containerIDPattern matches the IDs and names of Docker containers
+var containerIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func (s *PortainerMCPServer) AddContainerFeatures() {
+	s.addToolIfExists(ToolListContainers, s.HandleListContainers())
+	s.addToolIfExists(ToolInspectContainer, s.HandleInspectContainer())
+	s.addToolIfExists(ToolGetContainerLogs, s.HandleGetContainerLogs())
+}
+
+func (s *PortainerMCPServer) HandleListContainers() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		all, err := parser.GetBoolean("all", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid all parameter", err), nil
+		}
+
+		filterEntries, err := parser.GetArrayOfObjects("filters", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid filters parameter", err), nil
+		}
+		containerFilterArgs, err := parseContainerFilters(filterEntries)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid filters parameter", err), nil
+		}
+
+		queryParams := map[string]string{"all": fmt.Sprint(all)}
+		if containerFilterArgs.Len() > 0 {
+			encodedFilters, err := filters.ToJSON(containerFilterArgs)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid filters parameter", err), nil
+			}
+			queryParams["filters"] = encodedFilters
+		}
+
+		var rawContainers []container.Summary
+		if failed := s.getDockerJSON(environmentId, "/containers/json", queryParams, "failed to list containers", &rawContainers); failed != nil {
+			return failed, nil
+		}
+
+		containers := make([]models.Container, 0, len(rawContainers))
+		for _, rawContainer := range rawContainers {
+			containers = append(containers, models.ConvertContainerSummary(rawContainer))
+		}
+		slices.SortFunc(containers, func(a, b models.Container) int { return strings.Compare(a.Name, b.Name) })
+
+		if len(containers) == 0 {
+			return mcp.NewToolResultText("No containers found"), nil
+		}
+
+		return mcp.NewToolResultText(formatContainerTable(containers)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleInspectContainer() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		id, err := parser.GetString("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if !containerIDPattern.MatchString(id) {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid container id or name: %s", id)), nil
+		}
+
+		var rawContainer container.InspectResponse
+		if failed := s.getDockerJSON(environmentId, "/containers/"+id+"/json", nil, "failed to inspect container", &rawContainer); failed != nil {
+			return failed, nil
+		}
+
+		data, err := json.Marshal(models.ConvertContainerInspect(rawContainer))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal container", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// getDockerJSON sends a GET request to the Docker API of an environment and decodes its JSON response into out.
+// It returns a tool error result, prefixed with message, if the request fails.
+func (s *PortainerMCPServer) getDockerJSON(environmentId int, path string, queryParams map[string]string, message string, out any) *mcp.CallToolResult {
+	response, err := s.cli.ProxyDockerRequest(models.DockerProxyRequestOptions{
+		EnvironmentID: environmentId,
+		Method:        "GET",
+		Path:          path,
+		QueryParams:   queryParams,
+	})
+	if err != nil {
+		return newToolResultAPIError(message, err)
+	}
+	if err := client.ProxyResponseError(response); err != nil {
+		return newToolResultAPIError(message, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return newToolResultAPIError("failed to read Docker API response", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to parse Docker API response", err)
+	}
+
+	return nil
+}
+
+// parseContainerFilters parses the filters of listContainers, given as key-value pairs.
+// A key can be given several times, e.g. to match several statuses.
+func parseContainerFilters(entries []any) (filters.Args, error) {
+	args := filters.NewArgs()
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]any)
+		if !ok {
+			return args, fmt.Errorf("invalid filter: %v", entry)
+		}
+
+		key, ok := entryMap["key"].(string)
+		if !ok || !slices.Contains(containerFilters, key) {
+			return args, fmt.Errorf("invalid filter key: %v, must be one of %s", entryMap["key"], strings.Join(containerFilters, ", "))
+		}
+
+		value, ok := entryMap["value"].(string)
+		if !ok || value == "" {
+			return args, fmt.Errorf("invalid value of filter %s: %v", key, entryMap["value"])
+		}
+
+		args.Add(key, value)
+	}
+
+	return args, nil
+}
+
+// formatContainerTable formats containers as an aligned text table, with - for empty cells
+func formatContainerTable(containers []models.Container) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "NAME\tIMAGE\tSTATE\tHEALTH\tPORTS\tPROJECT\tUPTIME")
+	for _, c := range containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			orDash(c.Name), orDash(c.Image), orDash(c.State), orDash(c.Health),
+			orDash(strings.Join(c.Ports, ",")), orDash(c.ComposeProject), orDash(c.Uptime))
+	}
+	w.Flush()
+
+	return sb.String()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
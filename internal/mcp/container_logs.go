@@ -0,0 +1,320 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+const (
+	// defaultContainerLogsTail is the number of lines returned by getContainerLogs when tail is not given
+	defaultContainerLogsTail = 100
+	// maxContainerLogsTail is the maximum number of lines that can be requested from the Docker API
+	maxContainerLogsTail = 10000
+	// maxContainerLogsRead is the maximum number of bytes of logs kept from the Docker API, the most recent ones
+	maxContainerLogsRead = 8 << 20
+	// maxContainerLogsSize is the maximum size of the logs returned to the model, the most recent lines are kept
+	maxContainerLogsSize = 64 << 10
+)
+
+// Streams of the logs of a container
+const (
+	logStreamStdout = "stdout"
+	logStreamStderr = "stderr"
+	logStreamBoth   = "both"
+)
+
+// Content types of the logs of a container, multiplexed for containers created without a TTY
+const (
+	multiplexedStreamContentType = "application/vnd.docker.multiplexed-stream"
+	rawStreamContentType         = "application/vnd.docker.raw-stream"
+)
+
+func (s *PortainerMCPServer) HandleGetContainerLogs() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		id, err := parser.GetString("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if !containerIDPattern.MatchString(id) {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid container id or name: %s", id)), nil
+		}
+
+		tail, err := parser.GetInt("tail", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tail parameter", err), nil
+		}
+		if tail == 0 {
+			tail = defaultContainerLogsTail
+		}
+		if tail < 0 || tail > maxContainerLogsTail {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid tail parameter: must be between 1 and %d", maxContainerLogsTail)), nil
+		}
+
+		now := time.Now()
+		queryParams := map[string]string{"tail": strconv.Itoa(tail)}
+		for _, name := range []string{"since", "until"} {
+			value, err := parser.GetString(name, false)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr(fmt.Sprintf("invalid %s parameter", name), err), nil
+			}
+			if value == "" {
+				continue
+			}
+			if queryParams[name], err = parseLogTime(value, now); err != nil {
+				return mcp.NewToolResultErrorFromErr(fmt.Sprintf("invalid %s parameter", name), err), nil
+			}
+		}
+
+		timestamps, err := parser.GetBoolean("timestamps", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timestamps parameter", err), nil
+		}
+		queryParams["timestamps"] = strconv.FormatBool(timestamps)
+
+		stream, err := parser.GetString("stream", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stream parameter", err), nil
+		}
+		switch stream {
+		case logStreamStdout:
+			queryParams["stdout"], queryParams["stderr"] = "true", "false"
+		case logStreamStderr:
+			queryParams["stdout"], queryParams["stderr"] = "false", "true"
+		case logStreamBoth, "":
+			queryParams["stdout"], queryParams["stderr"] = "true", "true"
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid stream parameter: %s", stream)), nil
+		}
+
+		grep, err := parser.GetString("grep", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid grep parameter", err), nil
+		}
+		var pattern *regexp.Regexp
+		if grep != "" {
+			if pattern, err = regexp.Compile(grep); err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid grep parameter", err), nil
+			}
+		}
+
+		response, err := s.cli.ProxyDockerRequest(models.DockerProxyRequestOptions{
+			EnvironmentID: environmentId,
+			Method:        "GET",
+			Path:          "/containers/" + id + "/logs",
+			QueryParams:   queryParams,
+		})
+		if err != nil {
+			return newToolResultAPIError("failed to get container logs", err), nil
+		}
+		if err := client.ProxyResponseError(response); err != nil {
+			return newToolResultAPIError("failed to get container logs", err), nil
+		}
+		defer response.Body.Close()
+
+		logs, notes, err := readContainerLogs(response.Body, response.Header.Get("Content-Type"), maxContainerLogsRead)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read container logs", err), nil
+		}
+
+		var lines []string
+		for line := range strings.Lines(logs) {
+			line = strings.TrimRight(line, "\r\n")
+			if pattern == nil || pattern.MatchString(line) {
+				lines = append(lines, line)
+			}
+		}
+
+		if len(lines) == 0 {
+			if pattern != nil {
+				return mcp.NewToolResultText(notes + fmt.Sprintf("No log lines match %s in the last %d lines", grep, tail)), nil
+			}
+			return mcp.NewToolResultText(notes + "No logs"), nil
+		}
+
+		return mcp.NewToolResultText(notes + capLogLines(lines, maxContainerLogsSize)), nil
+	}
+}
+
+// readContainerLogs reads the logs of a container as text, keeping only their last maxSize bytes so that the most
+// recent lines are returned whatever the size of the logs. The logs of containers created without a TTY are
+// multiplexed, each chunk of stdout and stderr being prefixed with an 8-byte header, which is removed.
+// It returns the logs along with notes telling the model when they are incomplete.
+func readContainerLogs(r io.Reader, contentType string, maxSize int) (string, string, error) {
+	br := bufio.NewReader(r)
+	logs := &tailBuffer{max: maxSize}
+
+	multiplexed := contentType == multiplexedStreamContentType
+	if contentType != multiplexedStreamContentType && contentType != rawStreamContentType {
+		header, _ := br.Peek(8)
+		multiplexed = isMultiplexedStream(header)
+	}
+
+	var incomplete bool
+	if multiplexed {
+		var err error
+		if incomplete, err = demultiplexLogs(logs, br); err != nil {
+			return "", "", err
+		}
+	} else if _, err := io.Copy(logs, br); err != nil {
+		return "", "", err
+	}
+
+	data := logs.bytes()
+	var notes string
+	if logs.dropped > 0 {
+		// The oldest line kept is likely cut, it is dropped
+		if i := bytes.IndexByte(data, '\n'); i >= 0 && i < len(data)-1 {
+			data = data[i+1:]
+		}
+		notes += fmt.Sprintf("[earlier logs omitted, only the last %d bytes were read, lower tail or set since to get the complete lines]\n", maxSize)
+	}
+	if incomplete {
+		notes += "[the log stream ended in the middle of an entry, its end is missing]\n"
+	}
+
+	return string(data), notes, nil
+}
+
+// demultiplexLogs writes the content of the frames of a multiplexed log stream to w, stdout and stderr being
+// interleaved. It reports whether the stream ended in the middle of a frame, the part of the frame received
+// being kept.
+func demultiplexLogs(w io.Writer, r io.Reader) (bool, error) {
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(r, header)
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		switch stdcopy.StdType(header[0]) {
+		case stdcopy.Stdin, stdcopy.Stdout, stdcopy.Stderr:
+		case stdcopy.Systemerr:
+			message, _ := io.ReadAll(io.LimitReader(r, size))
+			return false, fmt.Errorf("error from the Docker daemon: %s", message)
+		default:
+			return false, fmt.Errorf("invalid multiplexed log stream: unknown stream type %d", header[0])
+		}
+
+		if _, err := io.CopyN(w, r, size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return true, nil
+			}
+			return false, err
+		}
+	}
+}
+
+// tailBuffer is a writer keeping the last max bytes written to it
+type tailBuffer struct {
+	max  int
+	data []byte
+	// dropped is the number of bytes written that are not kept
+	dropped int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+
+	// The oldest bytes are removed once the buffer holds twice the bytes to keep, so that they are
+	// moved once for every max bytes written
+	if len(b.data) >= 2*b.max {
+		drop := len(b.data) - b.max
+		b.dropped += int64(drop)
+		b.data = b.data[:copy(b.data, b.data[drop:])]
+	}
+
+	return len(p), nil
+}
+
+// bytes returns the last max bytes written
+func (b *tailBuffer) bytes() []byte {
+	if drop := len(b.data) - b.max; drop > 0 {
+		b.dropped += int64(drop)
+		b.data = b.data[drop:]
+	}
+	return b.data
+}
+
+// isMultiplexedStream detects the frame header of a multiplexed stream, for Docker versions that
+// do not return a content type: a stream type (0 to 2), three zero bytes and the frame size
+func isMultiplexedStream(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+
+	return data[0] <= byte(stdcopy.Stderr) && data[1] == 0 && data[2] == 0 && data[3] == 0
+}
+
+// capLogLines joins lines, keeping only the most recent ones that fit in maxSize bytes
+func capLogLines(lines []string, maxSize int) string {
+	size := 0
+	first := len(lines)
+	for first > 0 && size+len(lines[first-1])+1 <= maxSize {
+		first--
+		size += len(lines[first]) + 1
+	}
+
+	kept := lines[first:]
+	if len(kept) == 0 {
+		// The last line alone is larger than the limit, only its end is kept
+		last := lines[len(lines)-1]
+		kept = []string{strings.ToValidUTF8(last[len(last)-maxSize+1:], "")}
+		first = len(lines) - 1
+	}
+
+	logs := strings.Join(kept, "\n") + "\n"
+	if first > 0 {
+		logs = fmt.Sprintf("[%d earlier lines omitted, output limited to %d bytes]\n", first, maxSize) + logs
+	}
+
+	return logs
+}
+
+// parseLogTime converts a time given as an RFC 3339 timestamp, a UNIX timestamp, or a duration
+// before now (e.g. 30m) to the UNIX timestamp expected by the Docker API
+func parseLogTime(value string, now time.Time) (string, error) {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return strconv.FormatInt(t.Unix(), 10), nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return "", fmt.Errorf("%s is neither an RFC 3339 timestamp, a UNIX timestamp nor a duration such as 30m", value)
+	}
+
+	return strconv.FormatInt(now.Add(-d).Unix(), 10), nil
+}
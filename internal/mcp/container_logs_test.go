@@ -0,0 +1,355 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// multiplexedLogs encodes log chunks like the Docker API does for containers created without a TTY
+func multiplexedLogs(t *testing.T, chunks ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	stdout := stdcopy.NewStdWriter(&buf, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(&buf, stdcopy.Stderr)
+	for i, chunk := range chunks {
+		w := stdout
+		if i%2 == 1 {
+			w = stderr
+		}
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestReadContainerLogs(t *testing.T) {
+	multiplexed := multiplexedLogs(t, "starting\nlistening on :80\n", "warning: no config\n", "ready\n")
+
+	tests := []struct {
+		name          string
+		data          []byte
+		contentType   string
+		maxSize       int
+		expected      string
+		expectedNotes string
+	}{
+		{
+			name:        "multiplexed stream",
+			data:        multiplexed,
+			contentType: multiplexedStreamContentType,
+			expected:    "starting\nlistening on :80\nwarning: no config\nready\n",
+		},
+		{
+			name:     "multiplexed stream without content type",
+			data:     multiplexed,
+			expected: "starting\nlistening on :80\nwarning: no config\nready\n",
+		},
+		{
+			name:        "raw stream of a tty container",
+			data:        []byte("starting\r\nready\r\n"),
+			contentType: rawStreamContentType,
+			expected:    "starting\r\nready\r\n",
+		},
+		{
+			name:     "raw stream without content type",
+			data:     []byte("starting\nready\n"),
+			expected: "starting\nready\n",
+		},
+		{
+			name:          "most recent bytes kept",
+			data:          multiplexed,
+			contentType:   multiplexedStreamContentType,
+			maxSize:       30,
+			expected:      "warning: no config\nready\n",
+			expectedNotes: "[earlier logs omitted, only the last 30 bytes were read, lower tail or set since to get the complete lines]\n",
+		},
+		{
+			name:          "stream ending in the middle of a frame",
+			data:          multiplexed[:len(multiplexed)-3],
+			contentType:   multiplexedStreamContentType,
+			expected:      "starting\nlistening on :80\nwarning: no config\nrea",
+			expectedNotes: "[the log stream ended in the middle of an entry, its end is missing]\n",
+		},
+		{
+			name:          "stream ending in the middle of a header",
+			data:          append(slices.Clone(multiplexed), 1, 0, 0),
+			contentType:   multiplexedStreamContentType,
+			expected:      "starting\nlistening on :80\nwarning: no config\nready\n",
+			expectedNotes: "[the log stream ended in the middle of an entry, its end is missing]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = maxContainerLogsRead
+			}
+
+			logs, notes, err := readContainerLogs(bytes.NewReader(tt.data), tt.contentType, maxSize)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, logs)
+			assert.Equal(t, tt.expectedNotes, notes)
+		})
+	}
+}
+
+func TestReadContainerLogsDaemonError(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := stdcopy.NewStdWriter(&buf, stdcopy.Systemerr).Write([]byte("container not running"))
+	require.NoError(t, err)
+
+	_, _, err = readContainerLogs(&buf, multiplexedStreamContentType, maxContainerLogsRead)
+	assert.ErrorContains(t, err, "container not running")
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 4}
+	for _, chunk := range []string{"ab", "cdef", "g", "hijklmnop", "q"} {
+		n, err := b.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, "nopq", string(b.bytes()))
+	assert.Equal(t, int64(13), b.dropped)
+}
+
+func TestCapLogLines(t *testing.T) {
+	lines := []string{"first", "second", "third"}
+
+	assert.Equal(t, "first\nsecond\nthird\n", capLogLines(lines, 100))
+	assert.Equal(t, "[1 earlier lines omitted, output limited to 13 bytes]\nsecond\nthird\n", capLogLines(lines, 13))
+	assert.Equal(t, "[2 earlier lines omitted, output limited to 4 bytes]\nird\n", capLogLines(lines, 4))
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{value: "1735732800", expected: "1735732800"},
+		{value: "2025-01-01T11:00:00Z", expected: "1735729200"},
+		{value: "30m", expected: "1735731000"},
+		{value: "yesterday", expectError: true},
+		{value: "-5m", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseLogTime(tt.value, now)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHandleGetContainerLogs(t *testing.T) {
+	logs := multiplexedLogs(t,
+		"GET /health 200\nGET /orders 500\n",
+		"ERROR database connection refused\n",
+		"GET /health 200\n",
+	)
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		response      *http.Response
+		mockError     error
+		expectedQuery map[string]string
+		expectedText  string
+		expectError   string
+	}{
+		{
+			name:          "default parameters",
+			args:          map[string]any{"environmentId": float64(1), "id": "web-app-1"},
+			response:      logsResponse(logs),
+			expectedQuery: map[string]string{"tail": "100", "timestamps": "false", "stdout": "true", "stderr": "true"},
+			expectedText:  "GET /health 200\nGET /orders 500\nERROR database connection refused\nGET /health 200\n",
+		},
+		{
+			name: "stderr with timestamps and time range",
+			args: map[string]any{
+				"environmentId": float64(1),
+				"id":            "web-app-1",
+				"tail":          float64(500),
+				"since":         "1735729200",
+				"until":         "2025-01-01T12:00:00Z",
+				"timestamps":    true,
+				"stream":        "stderr",
+			},
+			response: logsResponse(logs),
+			expectedQuery: map[string]string{
+				"tail": "500", "since": "1735729200", "until": "1735732800",
+				"timestamps": "true", "stdout": "false", "stderr": "true",
+			},
+			expectedText: "GET /health 200\nGET /orders 500\nERROR database connection refused\nGET /health 200\n",
+		},
+		{
+			name:          "grep",
+			args:          map[string]any{"environmentId": float64(1), "id": "web-app-1", "grep": "(?i)error| 500"},
+			response:      logsResponse(logs),
+			expectedQuery: map[string]string{"tail": "100", "timestamps": "false", "stdout": "true", "stderr": "true"},
+			expectedText:  "GET /orders 500\nERROR database connection refused\n",
+		},
+		{
+			name:          "grep without match",
+			args:          map[string]any{"environmentId": float64(1), "id": "web-app-1", "grep": "panic"},
+			response:      logsResponse(logs),
+			expectedQuery: map[string]string{"tail": "100", "timestamps": "false", "stdout": "true", "stderr": "true"},
+			expectedText:  "No log lines match panic in the last 100 lines",
+		},
+		{
+			name:          "no logs",
+			args:          map[string]any{"environmentId": float64(1), "id": "web-app-1"},
+			response:      logsResponse(nil),
+			expectedQuery: map[string]string{"tail": "100", "timestamps": "false", "stdout": "true", "stderr": "true"},
+			expectedText:  "No logs",
+		},
+		{
+			name:        "invalid grep",
+			args:        map[string]any{"environmentId": float64(1), "id": "web-app-1", "grep": "(unclosed"},
+			expectError: "invalid grep parameter",
+		},
+		{
+			name:        "invalid stream",
+			args:        map[string]any{"environmentId": float64(1), "id": "web-app-1", "stream": "stdin"},
+			expectError: "invalid stream parameter",
+		},
+		{
+			name:        "tail too large",
+			args:        map[string]any{"environmentId": float64(1), "id": "web-app-1", "tail": float64(20000)},
+			expectError: "invalid tail parameter",
+		},
+		{
+			name:        "invalid since",
+			args:        map[string]any{"environmentId": float64(1), "id": "web-app-1", "since": "yesterday"},
+			expectError: "invalid since parameter",
+		},
+		{
+			name:        "invalid container id",
+			args:        map[string]any{"environmentId": float64(1), "id": "web/../../images"},
+			expectError: "invalid container id or name",
+		},
+		{
+			name:        "container not found",
+			args:        map[string]any{"environmentId": float64(1), "id": "missing"},
+			response:    createMockHttpResponse(http.StatusNotFound, `{"message":"No such container: missing"}`),
+			expectError: "No such container: missing",
+		},
+		{
+			name:        "proxy error",
+			args:        map[string]any{"environmentId": float64(1), "id": "web-app-1"},
+			mockError:   fmt.Errorf("environment unreachable"),
+			expectError: "failed to get container logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("ProxyDockerRequest", mock.AnythingOfType("models.DockerProxyRequestOptions")).Return(tt.response, tt.mockError)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetContainerLogs()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tt.expectedText, resultText(t, result))
+
+			opts := mockClient.Calls[0].Arguments.Get(0).(models.DockerProxyRequestOptions)
+			assert.Equal(t, "/containers/web-app-1/logs", opts.Path)
+			assert.Equal(t, "GET", opts.Method)
+			assert.Equal(t, tt.expectedQuery, opts.QueryParams)
+		})
+	}
+}
+
+func TestHandleGetContainerLogsCapsOutput(t *testing.T) {
+	line := strings.Repeat("x", 1023)
+	var raw strings.Builder
+	for range 100 {
+		raw.WriteString(line + "\n")
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("ProxyDockerRequest", mock.AnythingOfType("models.DockerProxyRequestOptions")).
+		Return(logsResponse(multiplexedLogs(t, raw.String())), nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetContainerLogs()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"id":            "web-app-1",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := resultText(t, result)
+	assert.True(t, strings.HasPrefix(text, "[36 earlier lines omitted, output limited to 65536 bytes]\n"))
+	assert.LessOrEqual(t, len(text), maxContainerLogsSize+100)
+}
+
+func TestHandleGetContainerLogsKeepsMostRecentLogs(t *testing.T) {
+	line := strings.Repeat("x", 1023) + "\n"
+	var raw strings.Builder
+	for raw.Len() < maxContainerLogsRead+(1<<20) {
+		raw.WriteString(line)
+	}
+	raw.WriteString("most recent line\n")
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("ProxyDockerRequest", mock.AnythingOfType("models.DockerProxyRequestOptions")).
+		Return(logsResponse(multiplexedLogs(t, raw.String())), nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetContainerLogs()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"id":            "web-app-1",
+		"grep":          "recent",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := resultText(t, result)
+	assert.True(t, strings.HasPrefix(text, "[earlier logs omitted, only the last 8388608 bytes were read"), text)
+	assert.True(t, strings.HasSuffix(text, "most recent line\n"), "the most recent logs must be kept")
+}
+
+func logsResponse(body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {multiplexedStreamContentType}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
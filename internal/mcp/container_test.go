@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainerList = `[
+	{"Id": "b1", "Names": ["/web-db-1"], "Image": "postgres:16", "State": "running", "Status": "Up 3 days (healthy)",
+	 "Labels": {"com.docker.compose.project": "web"}},
+	{"Id": "a1", "Names": ["/web-app-1"], "Image": "nginx:1.27", "State": "running", "Status": "Up 2 hours",
+	 "Ports": [{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}],
+	 "Labels": {"com.docker.compose.project": "web"}}
+]`
+
+func TestHandleListContainers(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          map[string]any
+		response      *http.Response
+		mockError     error
+		expectedQuery map[string]string
+		expectedText  string
+		expectError   string
+	}{
+		{
+			name:          "running containers",
+			args:          map[string]any{"environmentId": float64(1)},
+			response:      createMockHttpResponse(http.StatusOK, testContainerList),
+			expectedQuery: map[string]string{"all": "false"},
+			expectedText: "NAME       IMAGE        STATE    HEALTH   PORTS         PROJECT  UPTIME\n" +
+				"web-app-1  nginx:1.27   running  -        8080->80/tcp  web      2 hours\n" +
+				"web-db-1   postgres:16  running  healthy  -             web      3 days\n",
+		},
+		{
+			name: "all containers with filters",
+			args: map[string]any{
+				"environmentId": float64(1),
+				"all":           true,
+				"filters": []any{
+					map[string]any{"key": "status", "value": "exited"},
+					map[string]any{"key": "status", "value": "dead"},
+				},
+			},
+			response:      createMockHttpResponse(http.StatusOK, `[]`),
+			expectedQuery: map[string]string{"all": "true", "filters": `{"status":{"dead":true,"exited":true}}`},
+			expectedText:  "No containers found",
+		},
+		{
+			name: "invalid filter key",
+			args: map[string]any{
+				"environmentId": float64(1),
+				"filters":       []any{map[string]any{"key": "project", "value": "web"}},
+			},
+			expectError: "invalid filter key: project",
+		},
+		{
+			name:        "docker error",
+			args:        map[string]any{"environmentId": float64(1)},
+			response:    createMockHttpResponse(http.StatusInternalServerError, `{"message":"daemon unavailable"}`),
+			expectError: "daemon unavailable",
+		},
+		{
+			name:        "proxy error",
+			args:        map[string]any{"environmentId": float64(1)},
+			mockError:   fmt.Errorf("environment unreachable"),
+			expectError: "failed to list containers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("ProxyDockerRequest", mock.AnythingOfType("models.DockerProxyRequestOptions")).Return(tt.response, tt.mockError)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleListContainers()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tt.expectedText, resultText(t, result))
+
+			opts := mockClient.Calls[0].Arguments.Get(0).(models.DockerProxyRequestOptions)
+			assert.Equal(t, 1, opts.EnvironmentID)
+			assert.Equal(t, "GET", opts.Method)
+			assert.Equal(t, "/containers/json", opts.Path)
+			assert.Equal(t, tt.expectedQuery, opts.QueryParams)
+		})
+	}
+}
+
+func TestHandleInspectContainer(t *testing.T) {
+	inspect := `{
+		"Id": "a1b2c3", "Name": "/web-app-1", "Path": "nginx", "Args": ["-g", "daemon off;"],
+		"State": {"Status": "exited", "ExitCode": 137, "OOMKilled": true, "StartedAt": "2025-01-01T10:00:00Z", "FinishedAt": "2025-01-01T11:00:00Z"},
+		"Config": {"Image": "nginx:1.27", "Env": ["DB_PASSWORD=hunter2"]},
+		"HostConfig": {"RestartPolicy": {"Name": "always"}}
+	}`
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("ProxyDockerRequest", mock.MatchedBy(func(opts models.DockerProxyRequestOptions) bool {
+		return opts.Path == "/containers/web-app-1/json"
+	})).Return(createMockHttpResponse(http.StatusOK, inspect), nil)
+	mockClient.On("ProxyDockerRequest", mock.MatchedBy(func(opts models.DockerProxyRequestOptions) bool {
+		return opts.Path == "/containers/missing/json"
+	})).Return(createMockHttpResponse(http.StatusNotFound, `{"message":"No such container: missing"}`), nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleInspectContainer()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"id":            "web-app-1",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.NotContains(t, resultText(t, result), "hunter2")
+
+	var details models.ContainerDetails
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &details))
+	assert.Equal(t, "web-app-1", details.Name)
+	assert.Equal(t, []string{"nginx", "-g", "daemon off;"}, details.Command)
+	assert.Equal(t, models.ContainerState{
+		Status:     "exited",
+		ExitCode:   137,
+		OOMKilled:  true,
+		StartedAt:  "2025-01-01T10:00:00Z",
+		FinishedAt: "2025-01-01T11:00:00Z",
+	}, details.State)
+	assert.Equal(t, "always", details.RestartPolicy)
+	assert.Equal(t, []string{"DB_PASSWORD"}, details.EnvNames)
+
+	result, err = server.HandleInspectContainer()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"id":            "missing",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), ErrorCodeNotFound)
+
+	result, err = server.HandleInspectContainer()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"id":            "../images/json",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "invalid container id or name")
+	mockClient.AssertNumberOfCalls(t, "ProxyDockerRequest", 2)
+}
@@ -80,12 +80,13 @@ func (s *PortainerMCPServer) HandleDockerProxy() server.ToolHandlerFunc {
 
 		response, err := s.cli.ProxyDockerRequest(opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to send Docker API request", err), nil
+			return newToolResultAPIError("failed to send Docker API request", err), nil
 		}
+		defer response.Body.Close()
 
 		responseBody, err := io.ReadAll(response.Body)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to read Docker API response", err), nil
+			return newToolResultAPIError("failed to read Docker API response", err), nil
 		}
 
 		return mcp.NewToolResultText(string(responseBody)), nil
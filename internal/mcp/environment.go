@@ -3,27 +3,62 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/portainer/portainer-mcp/pkg/toolgen"
 )
 
 func (s *PortainerMCPServer) AddEnvironmentFeatures() {
 	s.addToolIfExists(ToolListEnvironments, s.HandleGetEnvironments())
+	s.addToolIfExists(ToolGetEnvironment, s.HandleGetEnvironment())
 
 	if !s.readOnly {
+		s.addToolIfExists(ToolCreateEnvironment, s.HandleCreateEnvironment())
+		s.addToolIfExists(ToolUpdateEnvironment, s.HandleUpdateEnvironment())
 		s.addToolIfExists(ToolUpdateEnvironmentTags, s.HandleUpdateEnvironmentTags())
 		s.addToolIfExists(ToolUpdateEnvironmentUserAccesses, s.HandleUpdateEnvironmentUserAccesses())
 		s.addToolIfExists(ToolUpdateEnvironmentTeamAccesses, s.HandleUpdateEnvironmentTeamAccesses())
 	}
+
+	s.addDestructiveToolIfAllowed(ToolDeleteEnvironment, s.HandleDeleteEnvironment())
 }
 
 func (s *PortainerMCPServer) HandleGetEnvironments() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		opts, filtered, err := s.parseEnvironmentListOptions(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environment list parameters", err), nil
+		}
+
+		if filtered {
+			page, err := s.cli.ListEnvironments(opts)
+			if err != nil {
+				return newToolResultAPIError("failed to list environments", err), nil
+			}
+
+			data, err := json.Marshal(page)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to marshal environments", err), nil
+			}
+
+			return mcp.NewToolResultText(string(data)), nil
+		}
+
+		if err := s.refreshCache(parser, client.CacheKeyEnvironments); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid refresh parameter", err), nil
+		}
+
 		environments, err := s.cli.GetEnvironments()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+			return newToolResultAPIError("failed to get environments", err), nil
 		}
 
 		data, err := json.Marshal(environments)
@@ -35,6 +70,162 @@ func (s *PortainerMCPServer) HandleGetEnvironments() server.ToolHandlerFunc {
 	}
 }
 
+func (s *PortainerMCPServer) HandleGetEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		environment, err := s.cli.GetEnvironment(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get environment", err), nil
+		}
+
+		data, err := json.Marshal(environment)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal environment", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleCreateEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		var opts models.EnvironmentCreateOptions
+		var err error
+
+		if opts.Name, err = parser.GetString("name", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if opts.Kind, err = parser.GetString("kind", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+		if opts.URL, err = parser.GetString("url", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid url parameter", err), nil
+		}
+		if opts.PublicURL, err = parser.GetString("publicUrl", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid publicUrl parameter", err), nil
+		}
+		if opts.TunnelServerAddress, err = parser.GetString("tunnelServerAddress", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tunnelServerAddress parameter", err), nil
+		}
+		if opts.GroupID, err = parser.GetInt("accessGroupId", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid accessGroupId parameter", err), nil
+		}
+		if opts.TagIds, err = parser.GetArrayOfIntegers("tagIds", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tagIds parameter", err), nil
+		}
+		if opts.EdgeCheckinInterval, err = parser.GetInt("edgeCheckinInterval", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid edgeCheckinInterval parameter", err), nil
+		}
+
+		platform, err := parser.GetString("platform", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid platform parameter", err), nil
+		}
+		if platform == "" {
+			platform = models.EnvironmentPlatformDockerStandalone
+		}
+
+		allowSelfSignedCerts, err := parser.GetBoolean("allowSelfSignedCerts", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid allowSelfSignedCerts parameter", err), nil
+		}
+
+		isEdge := opts.Kind == models.EnvironmentKindEdgeAgent
+		if isEdge {
+			// Check the platform before creating the environment, as the deploy command could not be built otherwise
+			if _, err := models.NewEdgeAgentDeployCommand(platform, "", "", SupportedPortainerVersion, allowSelfSignedCerts); err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid platform parameter", err), nil
+			}
+		}
+
+		environment, err := s.cli.CreateEnvironment(opts)
+		if err != nil {
+			return newToolResultAPIError("failed to create environment", err), nil
+		}
+
+		if isEdge {
+			environment.EdgeID, err = newUUID()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to generate edge id", err), nil
+			}
+			environment.DeployCommand, err = models.NewEdgeAgentDeployCommand(platform, environment.EdgeID, environment.EdgeKey, SupportedPortainerVersion, allowSelfSignedCerts)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to build deploy command", err), nil
+			}
+		}
+
+		data, err := json.Marshal(environment)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal environment", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleUpdateEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		name, err := optionalString(parser, "name")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		environmentURL, err := optionalString(parser, "url")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid url parameter", err), nil
+		}
+		publicURL, err := optionalString(parser, "publicUrl")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid publicUrl parameter", err), nil
+		}
+
+		if name == nil && environmentURL == nil && publicURL == nil {
+			return mcp.NewToolResultError("at least one of name, url or publicUrl must be provided"), nil
+		}
+
+		change := s.captureChange(ChangeKindEnvironmentSettings, id)
+
+		err = s.cli.UpdateEnvironment(id, name, environmentURL, publicURL)
+		if err != nil {
+			return newToolResultAPIError("failed to update environment", err), nil
+		}
+
+		return mcp.NewToolResultText("Environment updated successfully" + s.recordChange(ToolUpdateEnvironment, change)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleDeleteEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		err = s.cli.DeleteEnvironment(id)
+		if err != nil {
+			return newToolResultAPIError("failed to delete environment", err), nil
+		}
+
+		return mcp.NewToolResultText("Environment deleted successfully"), nil
+	}
+}
+
 func (s *PortainerMCPServer) HandleUpdateEnvironmentTags() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
@@ -49,12 +240,14 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentTags() server.ToolHandlerFun
 			return mcp.NewToolResultErrorFromErr("invalid tagIds parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentTags, id)
+
 		err = s.cli.UpdateEnvironmentTags(id, tagIds)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment tags", err), nil
+			return newToolResultAPIError("failed to update environment tags", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment tags updated successfully"), nil
+		return mcp.NewToolResultText("Environment tags updated successfully" + s.recordChange(ToolUpdateEnvironmentTags, change)), nil
 	}
 }
 
@@ -77,12 +270,14 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentUserAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid user accesses", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentUserAccesses, id)
+
 		err = s.cli.UpdateEnvironmentUserAccesses(id, userAccessesMap)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment user accesses", err), nil
+			return newToolResultAPIError("failed to update environment user accesses", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment user accesses updated successfully"), nil
+		return mcp.NewToolResultText("Environment user accesses updated successfully" + s.recordChange(ToolUpdateEnvironmentUserAccesses, change)), nil
 	}
 }
 
@@ -105,11 +300,90 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentTeamAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid team accesses", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentTeamAccesses, id)
+
 		err = s.cli.UpdateEnvironmentTeamAccesses(id, teamAccessesMap)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment team accesses", err), nil
+			return newToolResultAPIError("failed to update environment team accesses", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment team accesses updated successfully"), nil
+		return mcp.NewToolResultText("Environment team accesses updated successfully" + s.recordChange(ToolUpdateEnvironmentTeamAccesses, change)), nil
+	}
+}
+
+// parseEnvironmentListOptions parses the optional filter, sort and pagination parameters of listEnvironments.
+// It reports whether any of them is set, in which case the environments are listed page by page.
+// Tag names are resolved to tag IDs.
+func (s *PortainerMCPServer) parseEnvironmentListOptions(parser *toolgen.ParameterParser) (models.EnvironmentListOptions, bool, error) {
+	var opts models.EnvironmentListOptions
+	var err error
+
+	if opts.Search, err = parser.GetString("search", false); err != nil {
+		return opts, false, err
+	}
+	if opts.TagIds, err = parser.GetArrayOfIntegers("tagIds", false); err != nil {
+		return opts, false, err
+	}
+	tagNames, err := parser.GetArrayOfStrings("tags", false)
+	if err != nil {
+		return opts, false, err
+	}
+	if opts.AccessGroupIds, err = parser.GetArrayOfIntegers("accessGroupIds", false); err != nil {
+		return opts, false, err
+	}
+	if opts.EnvironmentGroupIds, err = parser.GetArrayOfIntegers("environmentGroupIds", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Types, err = parser.GetArrayOfStrings("types", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Status, err = parser.GetString("status", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Sort, err = parser.GetString("sort", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Order, err = parser.GetString("order", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Start, err = parser.GetInt("start", false); err != nil {
+		return opts, false, err
+	}
+	if opts.Limit, err = parser.GetInt("limit", false); err != nil {
+		return opts, false, err
+	}
+
+	if opts.Start < 0 || opts.Limit < 0 {
+		return opts, false, fmt.Errorf("start and limit must not be negative")
+	}
+
+	if len(tagNames) > 0 {
+		tags, err := s.cli.GetEnvironmentTags()
+		if err != nil {
+			return opts, false, fmt.Errorf("failed to get environment tags: %w", err)
+		}
+
+		for _, name := range tagNames {
+			index := slices.IndexFunc(tags, func(t models.EnvironmentTag) bool { return strings.EqualFold(t.Name, name) })
+			if index < 0 {
+				return opts, false, fmt.Errorf("unknown environment tag: %s", name)
+			}
+			opts.TagIds = append(opts.TagIds, tags[index].ID)
+		}
+	}
+
+	filtered := opts.Search != "" || len(opts.TagIds) > 0 || len(opts.AccessGroupIds) > 0 ||
+		len(opts.EnvironmentGroupIds) > 0 || len(opts.Types) > 0 || opts.Status != "" ||
+		opts.Sort != "" || opts.Order != "" || opts.Start > 0 || opts.Limit > 0
+
+	return opts, filtered, nil
+}
+
+// optionalString returns a pointer to the value of an optional string parameter, or nil if it is not provided
+func optionalString(parser *toolgen.ParameterParser, name string) (*string, error) {
+	value, err := parser.GetString(name, false)
+	if err != nil || value == "" {
+		return nil, err
 	}
+	return &value, nil
 }
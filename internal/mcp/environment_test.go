@@ -10,6 +10,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestHandleGetEnvironments(t *testing.T) {
@@ -77,6 +78,162 @@ func TestHandleGetEnvironments(t *testing.T) {
 	}
 }
 
+func TestHandleGetEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]any
+		mockDetails models.EnvironmentDetails
+		mockError   error
+		expectError bool
+	}{
+		{
+			name:   "successful environment retrieval",
+			params: map[string]any{"id": float64(1)},
+			mockDetails: models.EnvironmentDetails{
+				Environment:   models.Environment{ID: 1, Name: "env1"},
+				URL:           "tcp://10.0.0.1:9001",
+				Platform:      models.EnvironmentPlatformDockerStandalone,
+				DockerVersion: "25.0.0",
+				Snapshot:      &models.EnvironmentSnapshot{ContainerCount: 3},
+			},
+		},
+		{
+			name:        "api error",
+			params:      map[string]any{"id": float64(1)},
+			mockError:   fmt.Errorf("api error"),
+			expectError: true,
+		},
+		{
+			name:        "missing id",
+			params:      map[string]any{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironment", 1).Return(tt.mockDetails, tt.mockError)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetEnvironment()(context.Background(), CreateMCPRequest(tt.params))
+			assert.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			var details models.EnvironmentDetails
+			assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &details))
+			assert.Equal(t, tt.mockDetails, details)
+		})
+	}
+}
+
+func TestHandleGetEnvironmentsFiltered(t *testing.T) {
+	page := models.EnvironmentPage{
+		Environments: []models.Environment{{ID: 2, Name: "edge-2"}},
+		Total:        3,
+		Start:        1,
+		Limit:        1,
+		NextStart:    2,
+	}
+
+	tests := []struct {
+		name         string
+		params       map[string]any
+		mockTags     []models.EnvironmentTag
+		expectedOpts models.EnvironmentListOptions
+		expectError  string
+	}{
+		{
+			name: "filters and pagination",
+			params: map[string]any{
+				"search":              "edge",
+				"tagIds":              []any{float64(1)},
+				"accessGroupIds":      []any{float64(2)},
+				"environmentGroupIds": []any{float64(3)},
+				"types":               []any{models.EnvironmentTypeDockerEdgeAgent},
+				"status":              models.EnvironmentStatusActive,
+				"sort":                models.EnvironmentSortName,
+				"order":               models.SortOrderDesc,
+				"start":               float64(1),
+				"limit":               float64(1),
+			},
+			expectedOpts: models.EnvironmentListOptions{
+				Search:              "edge",
+				TagIds:              []int{1},
+				AccessGroupIds:      []int{2},
+				EnvironmentGroupIds: []int{3},
+				Types:               []string{models.EnvironmentTypeDockerEdgeAgent},
+				Status:              models.EnvironmentStatusActive,
+				Sort:                models.EnvironmentSortName,
+				Order:               models.SortOrderDesc,
+				Start:               1,
+				Limit:               1,
+			},
+		},
+		{
+			name:     "tag names are resolved",
+			params:   map[string]any{"tags": []any{"Production"}, "limit": float64(10)},
+			mockTags: []models.EnvironmentTag{{ID: 5, Name: "production"}},
+			expectedOpts: models.EnvironmentListOptions{
+				TagIds:              []int{5},
+				AccessGroupIds:      []int{},
+				EnvironmentGroupIds: []int{},
+				Types:               []string{},
+				Limit:               10,
+			},
+		},
+		{
+			name:        "unknown tag name",
+			params:      map[string]any{"tags": []any{"staging"}},
+			mockTags:    []models.EnvironmentTag{{ID: 5, Name: "production"}},
+			expectError: "unknown environment tag: staging",
+		},
+		{
+			name:        "negative start",
+			params:      map[string]any{"start": float64(-1)},
+			expectError: "start and limit must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironmentTags").Return(tt.mockTags, nil)
+			mockClient.On("ListEnvironments", tt.expectedOpts).Return(page, nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetEnvironments()(context.Background(), CreateMCPRequest(tt.params))
+			assert.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+				mockClient.AssertNotCalled(t, "ListEnvironments", mock.Anything)
+				return
+			}
+
+			assert.False(t, result.IsError, textContent.Text)
+			var got models.EnvironmentPage
+			assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &got))
+			assert.Equal(t, page, got)
+			mockClient.AssertNotCalled(t, "GetEnvironments")
+			mockClient.AssertCalled(t, "ListEnvironments", tt.expectedOpts)
+		})
+	}
+}
+
 func TestHandleUpdateEnvironmentTags(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -445,3 +602,158 @@ func TestHandleUpdateEnvironmentTeamAccesses(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleCreateEnvironment(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]any
+		expectedOpts  models.EnvironmentCreateOptions
+		mockCreated   models.CreatedEnvironment
+		expectError   bool
+		expectCommand string
+	}{
+		{
+			name: "edge agent with kubernetes deploy command",
+			params: map[string]any{
+				"name":          "edge",
+				"kind":          models.EnvironmentKindEdgeAgent,
+				"accessGroupId": float64(2),
+				"tagIds":        []any{float64(1)},
+				"platform":      models.EnvironmentPlatformKubernetes,
+			},
+			expectedOpts: models.EnvironmentCreateOptions{
+				Name:    "edge",
+				Kind:    models.EnvironmentKindEdgeAgent,
+				GroupID: 2,
+				TagIds:  []int{1},
+			},
+			mockCreated:   models.CreatedEnvironment{ID: 5, Name: "edge", Type: models.EnvironmentTypeDockerEdgeAgent, EdgeKey: "edge-key"},
+			expectCommand: "portainer-edge-agent-setup.sh",
+		},
+		{
+			name: "edge agent allowing self-signed certificates",
+			params: map[string]any{
+				"name":                 "edge",
+				"kind":                 models.EnvironmentKindEdgeAgent,
+				"allowSelfSignedCerts": true,
+			},
+			expectedOpts: models.EnvironmentCreateOptions{
+				Name:   "edge",
+				Kind:   models.EnvironmentKindEdgeAgent,
+				TagIds: []int{},
+			},
+			mockCreated:   models.CreatedEnvironment{ID: 7, Name: "edge", Type: models.EnvironmentTypeDockerEdgeAgent, EdgeKey: "edge-key"},
+			expectCommand: "-e EDGE_INSECURE_POLL=1",
+		},
+		{
+			name: "agent",
+			params: map[string]any{
+				"name": "agent",
+				"kind": models.EnvironmentKindAgent,
+				"url":  "10.0.0.1:9001",
+			},
+			expectedOpts: models.EnvironmentCreateOptions{
+				Name:   "agent",
+				Kind:   models.EnvironmentKindAgent,
+				URL:    "10.0.0.1:9001",
+				TagIds: []int{},
+			},
+			mockCreated: models.CreatedEnvironment{ID: 6, Name: "agent", Type: models.EnvironmentTypeDockerAgent},
+		},
+		{
+			name: "unsupported edge platform",
+			params: map[string]any{
+				"name":     "edge",
+				"kind":     models.EnvironmentKindEdgeAgent,
+				"platform": models.EnvironmentPlatformAzureACI,
+			},
+			expectError: true,
+		},
+		{
+			name:        "missing kind",
+			params:      map[string]any{"name": "edge"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("CreateEnvironment", tt.expectedOpts).Return(tt.mockCreated, nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleCreateEnvironment()(context.Background(), CreateMCPRequest(tt.params))
+			assert.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				mockClient.AssertNotCalled(t, "CreateEnvironment", mock.Anything)
+				return
+			}
+
+			assert.False(t, result.IsError, textContent.Text)
+			var created models.CreatedEnvironment
+			assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &created))
+			assert.Equal(t, tt.mockCreated.ID, created.ID)
+
+			if tt.expectCommand != "" {
+				assert.Len(t, created.EdgeID, 36)
+				assert.Contains(t, created.DeployCommand, tt.expectCommand)
+				assert.Contains(t, created.DeployCommand, created.EdgeID)
+				assert.Contains(t, created.DeployCommand, "edge-key")
+			} else {
+				assert.Empty(t, created.EdgeID)
+				assert.Empty(t, created.DeployCommand)
+			}
+		})
+	}
+}
+
+func TestHandleUpdateEnvironment(t *testing.T) {
+	name := "renamed"
+
+	t.Run("updates only the provided settings", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("UpdateEnvironment", 1, &name, (*string)(nil), (*string)(nil)).Return(nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateEnvironment()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":   float64(1),
+			"name": "renamed",
+		}))
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("requires at least one setting", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateEnvironment()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(1)}))
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		mockClient.AssertNotCalled(t, "UpdateEnvironment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleDeleteEnvironment(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("DeleteEnvironment", 1).Return(nil)
+	mockClient.On("DeleteEnvironment", 2).Return(fmt.Errorf("api error"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleDeleteEnvironment()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(1)}))
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	result, err = server.HandleDeleteEnvironment()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2)}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+)
+
+// Error codes returned to the model when a tool fails because of a Portainer API error
+const (
+	ErrorCodeNotFound       = "not_found"
+	ErrorCodeForbidden      = "forbidden"
+	ErrorCodeConflict       = "conflict"
+	ErrorCodeUnauthorized   = "unauthorized"
+	ErrorCodeUnavailable    = "unavailable"
+	ErrorCodeTimeout        = "timeout"
+	ErrorCodeInvalidRequest = "invalid_request"
+	ErrorCodeInternal       = "internal_error"
+)
+
+// toolError is returned to the model when a tool fails because of a Portainer API error
+type toolError struct {
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+	Hint       string `json:"hint,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+// errorCodes maps the kinds of client errors to the codes returned to the model, with a hint on how to recover
+var errorCodes = map[client.ErrorKind]struct {
+	code string
+	hint string
+}{
+	client.ErrorKindNotFound: {
+		code: ErrorCodeNotFound,
+		hint: "The resource does not exist. Check the ID, for example with the corresponding list tool.",
+	},
+	client.ErrorKindForbidden: {
+		code: ErrorCodeForbidden,
+		hint: "The Portainer API token is not allowed to perform this operation. Use a token of a user with the required role or access.",
+	},
+	client.ErrorKindConflict: {
+		code: ErrorCodeConflict,
+		hint: "The operation conflicts with the current state of the resource, for example a duplicate name. Fetch the current state and adjust the parameters.",
+	},
+	client.ErrorKindUnauthorized: {
+		code: ErrorCodeUnauthorized,
+		hint: "The Portainer API token is invalid or expired. Create a new access token in Portainer and restart the MCP server with it.",
+	},
+	client.ErrorKindUnavailable: {
+		code: ErrorCodeUnavailable,
+		hint: "The Portainer server is unreachable or overloaded. Retry the call later.",
+	},
+	client.ErrorKindTimeout: {
+		code: ErrorCodeTimeout,
+		hint: "The request to the Portainer server timed out. Retry the call later.",
+	},
+	client.ErrorKindInvalid: {
+		code: ErrorCodeInvalidRequest,
+		hint: "The Portainer server rejected the request. Check the parameter values against the tool description.",
+	},
+}
+
+// newToolResultAPIError creates a structured tool error for a failed Portainer API call,
+// with a stable error code and a hint on how to recover
+func newToolResultAPIError(message string, err error) *mcp.CallToolResult {
+	apiErr := client.ClassifyError(err)
+
+	result := toolError{
+		Error:   ErrorCodeInternal,
+		Message: fmt.Sprintf("%s: %s", message, err),
+	}
+	if apiErr != nil {
+		if code, ok := errorCodes[apiErr.Kind]; ok {
+			result.Error = code.code
+			result.Hint = code.hint
+		}
+		result.StatusCode = apiErr.StatusCode
+	}
+
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return mcp.NewToolResultErrorFromErr(message, err)
+	}
+
+	return mcp.NewToolResultError(string(data))
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToolResultAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   string
+		expectedStatus int
+		expectHint     bool
+	}{
+		{
+			name:           "not found",
+			err:            fmt.Errorf("failed to get stack: %w", &client.APIError{Kind: client.ErrorKindNotFound, StatusCode: http.StatusNotFound, Message: "stack not found"}),
+			expectedCode:   ErrorCodeNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectHint:     true,
+		},
+		{
+			name:           "forbidden",
+			err:            &client.APIError{Kind: client.ErrorKindForbidden, StatusCode: http.StatusForbidden, Message: "access denied"},
+			expectedCode:   ErrorCodeForbidden,
+			expectedStatus: http.StatusForbidden,
+			expectHint:     true,
+		},
+		{
+			name:         "timeout",
+			err:          &client.APIError{Kind: client.ErrorKindTimeout, Message: "deadline exceeded"},
+			expectedCode: ErrorCodeTimeout,
+			expectHint:   true,
+		},
+		{
+			name:           "invalid request",
+			err:            &client.APIError{Kind: client.ErrorKindInvalid, StatusCode: http.StatusBadRequest, Message: "invalid payload"},
+			expectedCode:   ErrorCodeInvalidRequest,
+			expectedStatus: http.StatusBadRequest,
+			expectHint:     true,
+		},
+		{
+			name:         "unclassified error",
+			err:          errors.New("api error"),
+			expectedCode: ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newToolResultAPIError("failed to do something", tt.err)
+
+			require.True(t, result.IsError)
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var toolErr toolError
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &toolErr))
+			assert.Equal(t, tt.expectedCode, toolErr.Error)
+			assert.Equal(t, tt.expectedStatus, toolErr.StatusCode)
+			assert.Contains(t, toolErr.Message, "failed to do something")
+			assert.Contains(t, toolErr.Message, tt.err.Error())
+			assert.Equal(t, tt.expectHint, toolErr.Hint != "")
+		})
+	}
+}
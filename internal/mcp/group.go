@@ -25,7 +25,7 @@ func (s *PortainerMCPServer) HandleGetEnvironmentGroups() server.ToolHandlerFunc
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		edgeGroups, err := s.cli.GetEnvironmentGroups()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get environment groups", err), nil
+			return newToolResultAPIError("failed to get environment groups", err), nil
 		}
 
 		data, err := json.Marshal(edgeGroups)
@@ -53,7 +53,7 @@ func (s *PortainerMCPServer) HandleCreateEnvironmentGroup() server.ToolHandlerFu
 
 		id, err := s.cli.CreateEnvironmentGroup(name, environmentIds)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to create environment group", err), nil
+			return newToolResultAPIError("failed to create environment group", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Environment group created successfully with ID: %d", id)), nil
@@ -74,12 +74,14 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentGroupName() server.ToolHandl
 			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentGroupName, id)
+
 		err = s.cli.UpdateEnvironmentGroupName(id, name)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment group name", err), nil
+			return newToolResultAPIError("failed to update environment group name", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment group name updated successfully"), nil
+		return mcp.NewToolResultText("Environment group name updated successfully" + s.recordChange(ToolUpdateEnvironmentGroupName, change)), nil
 	}
 }
 
@@ -97,12 +99,14 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentGroupEnvironments() server.T
 			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentGroupEnvironments, id)
+
 		err = s.cli.UpdateEnvironmentGroupEnvironments(id, environmentIds)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment group environments", err), nil
+			return newToolResultAPIError("failed to update environment group environments", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment group environments updated successfully"), nil
+		return mcp.NewToolResultText("Environment group environments updated successfully" + s.recordChange(ToolUpdateEnvironmentGroupEnvironments, change)), nil
 	}
 }
 
@@ -120,11 +124,13 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentGroupTags() server.ToolHandl
 			return mcp.NewToolResultErrorFromErr("invalid tagIds parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindEnvironmentGroupTags, id)
+
 		err = s.cli.UpdateEnvironmentGroupTags(id, tagIds)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update environment group tags", err), nil
+			return newToolResultAPIError("failed to update environment group tags", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment group tags updated successfully"), nil
+		return mcp.NewToolResultText("Environment group tags updated successfully" + s.recordChange(ToolUpdateEnvironmentGroupTags, change)), nil
 	}
 }
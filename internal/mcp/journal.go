@@ -0,0 +1,683 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/journal"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// Kinds of changes recorded in the journal. A kind identifies the resource
+// type and the part of the resource replaced by a write.
+const (
+	ChangeKindEnvironmentTags              = "environment.tags"
+	ChangeKindEnvironmentUserAccesses      = "environment.user_accesses"
+	ChangeKindEnvironmentTeamAccesses      = "environment.team_accesses"
+	ChangeKindEnvironmentSettings          = "environment.settings"
+	ChangeKindAccessGroupName              = "access_group.name"
+	ChangeKindAccessGroupEnvironments      = "access_group.environments"
+	ChangeKindAccessGroupUserAccesses      = "access_group.user_accesses"
+	ChangeKindAccessGroupTeamAccesses      = "access_group.team_accesses"
+	ChangeKindEnvironmentGroupName         = "environment_group.name"
+	ChangeKindEnvironmentGroupEnvironments = "environment_group.environments"
+	ChangeKindEnvironmentGroupTags         = "environment_group.tags"
+	ChangeKindTeamName                     = "team.name"
+	ChangeKindTeamMembers                  = "team.members"
+	ChangeKindUserRole                     = "user.role"
+	ChangeKindStack                        = "stack"
+	ChangeKindEdgeStack                    = "edge_stack"
+	ChangeKindStackAutoUpdate              = "stack.auto_update"
+	ChangeKindStackWebhook                 = "stack.webhook"
+)
+
+// maskedEnvValue replaces the values of stack environment variables returned to the model
+const maskedEnvValue = "********"
+
+// environmentSettings is the recorded state of the settings changed by updateEnvironment
+type environmentSettings struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	PublicURL string `json:"public_url"`
+}
+
+// stackState is the state of a stack recorded in the journal.
+// The values of the environment variables are not recorded, as they often contain secrets. Only a keyed hash
+// of each value is, so that a revert can tell which values it does not restore. The key is a random salt
+// shared by the states of a change, and empty in states recorded before hashes were introduced.
+type stackState struct {
+	File                string            `json:"file"`
+	EnvNames            []string          `json:"env_names,omitempty"`
+	EnvSalt             string            `json:"env_salt,omitempty"`
+	EnvHashes           map[string]string `json:"env_hashes,omitempty"`
+	EnvironmentGroupIds []int             `json:"environment_group_ids,omitempty"`
+	EdgeStack           bool              `json:"edge_stack"`
+}
+
+// pendingChange holds the state of a resource captured before a write,
+// or the error that prevented it from being captured
+type pendingChange struct {
+	kind       string
+	resourceID int
+	before     json.RawMessage
+	err        error
+}
+
+func (s *PortainerMCPServer) AddJournalFeatures() {
+	s.addToolIfExists(ToolListChanges, s.HandleListChanges())
+
+	if !s.readOnly {
+		s.addToolIfExists(ToolRevertChange, s.HandleRevertChange())
+	}
+}
+
+func (s *PortainerMCPServer) HandleListChanges() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.journal == nil {
+			return mcp.NewToolResultError("change journal is not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		kind, err := parser.GetString("kind", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		resourceId, err := parser.GetInt("resourceId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid resourceId parameter", err), nil
+		}
+
+		entries := []journal.Entry{}
+		for _, entry := range s.journal.List() {
+			if kind != "" && entry.Kind != kind {
+				continue
+			}
+			if resourceId != 0 && entry.ResourceID != resourceId {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal changes", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleRevertChange() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.journal == nil {
+			return mcp.NewToolResultError("change journal is not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("changeId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid changeId parameter", err), nil
+		}
+
+		entry, ok := s.journal.Get(id)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("change %d not found", id)), nil
+		}
+
+		if entry.RevertedBy != 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("change %d has already been reverted by change %d", id, entry.RevertedBy)), nil
+		}
+
+		current, err := s.captureStateLike(entry.Kind, entry.ResourceID, entry.After)
+		if err != nil {
+			return newToolResultAPIError("failed to get the current state of the resource", err), nil
+		}
+
+		equal, err := sameState(current, entry.After)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to compare the current state of the resource", err), nil
+		}
+		if !equal {
+			return mcp.NewToolResultError(fmt.Sprintf("the resource has changed since change %d was recorded, refusing to revert", id)), nil
+		}
+
+		if err := s.applyState(entry.Kind, entry.ResourceID, entry.Before); err != nil {
+			return newToolResultAPIError("failed to revert change", err), nil
+		}
+
+		reverted, note := "reverted successfully", ""
+		if entry.Kind == ChangeKindStack {
+			note = unrestoredEnvNote(entry.Before, current)
+		}
+		if note != "" {
+			reverted = "partially reverted"
+		}
+
+		after, err := s.captureStateLike(entry.Kind, entry.ResourceID, current)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("Change %d %s (warning: the revert was not recorded: %v)", id, reverted, err) + note), nil
+		}
+
+		revert, err := s.journal.Record(journal.Entry{
+			Tool:       ToolRevertChange,
+			Kind:       entry.Kind,
+			ResourceID: entry.ResourceID,
+			Before:     current,
+			After:      after,
+			Reverts:    id,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("Change %d %s (warning: the revert was not recorded: %v)", id, reverted, err) + note), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Change %d %s (recorded as change %d)", id, reverted, revert.ID) + note), nil
+	}
+}
+
+// captureChange captures the state of a resource before a write. The capture is best-effort: if it fails,
+// the write still goes ahead and recordChange reports that it was not recorded.
+// It returns nil if the journal is disabled.
+func (s *PortainerMCPServer) captureChange(kind string, id int) *pendingChange {
+	if s.journal == nil {
+		return nil
+	}
+
+	before, err := s.captureState(kind, id)
+	return &pendingChange{kind: kind, resourceID: id, before: before, err: err}
+}
+
+// recordChange records a completed write in the journal, along with the state of the resource after the write.
+// It returns a note to append to the tool result, which is empty if the journal is disabled.
+func (s *PortainerMCPServer) recordChange(tool string, change *pendingChange) string {
+	if change == nil {
+		return ""
+	}
+	if change.err != nil {
+		return fmt.Sprintf(" (warning: change not recorded, the state before the write could not be read: %v)", change.err)
+	}
+
+	after, err := s.captureStateLike(change.kind, change.resourceID, change.before)
+	if err != nil {
+		return fmt.Sprintf(" (warning: change not recorded: %v)", err)
+	}
+
+	entry, err := s.journal.Record(journal.Entry{
+		Tool:       tool,
+		Kind:       change.kind,
+		ResourceID: change.resourceID,
+		Before:     change.before,
+		After:      after,
+	})
+	if err != nil {
+		return fmt.Sprintf(" (warning: change not recorded: %v)", err)
+	}
+
+	return fmt.Sprintf(" (change %d recorded, use revertChange to undo)", entry.ID)
+}
+
+// captureState returns the part of a resource identified by the change kind, encoded as JSON
+func (s *PortainerMCPServer) captureState(kind string, id int) (json.RawMessage, error) {
+	salt, err := newEnvSalt()
+	if err != nil {
+		return nil, err
+	}
+	return s.captureStateWithSalt(kind, id, salt)
+}
+
+// captureStateLike returns the state of a resource in a form that can be compared with the reference state
+// of the same change: the values of the environment variables of a stack are hashed with the same salt.
+func (s *PortainerMCPServer) captureStateLike(kind string, id int, reference json.RawMessage) (json.RawMessage, error) {
+	if kind != ChangeKindStack && kind != ChangeKindEdgeStack {
+		return s.captureState(kind, id)
+	}
+
+	var stack stackState
+	if err := json.Unmarshal(reference, &stack); err != nil {
+		return nil, fmt.Errorf("invalid state: %w", err)
+	}
+	// Edge stacks used to be recorded under the stack kind
+	if stack.EdgeStack {
+		kind = ChangeKindEdgeStack
+	}
+	return s.captureStateWithSalt(kind, id, stack.EnvSalt)
+}
+
+// captureStateWithSalt returns the state of a resource, hashing the values of the environment variables of
+// a stack with salt. They are not hashed if salt is empty.
+func (s *PortainerMCPServer) captureStateWithSalt(kind string, id int, salt string) (json.RawMessage, error) {
+	var state any
+
+	switch kind {
+	case ChangeKindEnvironmentTags, ChangeKindEnvironmentUserAccesses, ChangeKindEnvironmentTeamAccesses:
+		// The state is always read from the Portainer server, as it may have changed outside of the MCP server
+		s.cli.InvalidateCache(client.CacheKeyEnvironments)
+		environments, err := s.cli.GetEnvironments()
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(environments, func(e models.Environment) bool { return e.ID == id })
+		if index < 0 {
+			return nil, notFoundError("environment", id)
+		}
+		environment := environments[index]
+
+		switch kind {
+		case ChangeKindEnvironmentTags:
+			state = sortedIDs(environment.TagIds)
+		case ChangeKindEnvironmentUserAccesses:
+			state = accessMap(environment.UserAccesses)
+		default:
+			state = accessMap(environment.TeamAccesses)
+		}
+
+	case ChangeKindEnvironmentSettings:
+		environment, err := s.cli.GetEnvironment(id)
+		if err != nil {
+			return nil, err
+		}
+		state = environmentSettings{
+			Name:      environment.Name,
+			URL:       environment.URL,
+			PublicURL: environment.PublicURL,
+		}
+
+	case ChangeKindAccessGroupName, ChangeKindAccessGroupEnvironments, ChangeKindAccessGroupUserAccesses, ChangeKindAccessGroupTeamAccesses:
+		accessGroups, err := s.cli.GetAccessGroups()
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(accessGroups, func(g models.AccessGroup) bool { return g.ID == id })
+		if index < 0 {
+			return nil, notFoundError("access group", id)
+		}
+		accessGroup := accessGroups[index]
+
+		switch kind {
+		case ChangeKindAccessGroupName:
+			state = accessGroup.Name
+		case ChangeKindAccessGroupEnvironments:
+			state = sortedIDs(accessGroup.EnvironmentIds)
+		case ChangeKindAccessGroupUserAccesses:
+			state = accessMap(accessGroup.UserAccesses)
+		default:
+			state = accessMap(accessGroup.TeamAccesses)
+		}
+
+	case ChangeKindEnvironmentGroupName, ChangeKindEnvironmentGroupEnvironments, ChangeKindEnvironmentGroupTags:
+		groups, err := s.cli.GetEnvironmentGroups()
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(groups, func(g models.Group) bool { return g.ID == id })
+		if index < 0 {
+			return nil, notFoundError("environment group", id)
+		}
+		group := groups[index]
+
+		switch kind {
+		case ChangeKindEnvironmentGroupName:
+			state = group.Name
+		case ChangeKindEnvironmentGroupEnvironments:
+			state = sortedIDs(group.EnvironmentIds)
+		default:
+			state = sortedIDs(group.TagIds)
+		}
+
+	case ChangeKindTeamName, ChangeKindTeamMembers:
+		s.cli.InvalidateCache(client.CacheKeyTeams)
+		teams, err := s.cli.GetTeams()
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(teams, func(t models.Team) bool { return t.ID == id })
+		if index < 0 {
+			return nil, notFoundError("team", id)
+		}
+
+		if kind == ChangeKindTeamName {
+			state = teams[index].Name
+		} else {
+			state = sortedIDs(teams[index].MemberIDs)
+		}
+
+	case ChangeKindUserRole:
+		s.cli.InvalidateCache(client.CacheKeyUsers)
+		users, err := s.cli.GetUsers()
+		if err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(users, func(u models.User) bool { return u.ID == id })
+		if index < 0 {
+			return nil, notFoundError("user", id)
+		}
+		state = users[index].Role
+
+	case ChangeKindStackAutoUpdate:
+		settings, err := s.cli.GetStackGitSettings(id)
+		if err != nil {
+			return nil, err
+		}
+		state = settings.AutoUpdate
+
+	case ChangeKindStackWebhook:
+		webhook, err := s.cli.GetStackWebhook(id)
+		if err != nil {
+			return nil, err
+		}
+		state = webhook.Webhook
+
+	case ChangeKindStack, ChangeKindEdgeStack:
+		stack, err := s.captureStackState(id, stackKind(kind), salt)
+		if err != nil {
+			return nil, err
+		}
+		state = stack
+
+	default:
+		return nil, fmt.Errorf("unsupported change kind: %s", kind)
+	}
+
+	return json.Marshal(state)
+}
+
+// captureStackState returns the file and environment variable names and value hashes of a regular stack,
+// or the file and environment groups of an edge stack
+func (s *PortainerMCPServer) captureStackState(id int, kind string, salt string) (stackState, error) {
+	file, err := s.cli.GetStackFile(id, kind)
+	if err != nil {
+		return stackState{}, err
+	}
+
+	if kind == models.StackKindEdge {
+		stack, err := s.cli.GetStack(id, kind)
+		if err != nil {
+			return stackState{}, err
+		}
+
+		return stackState{
+			File:                file,
+			EnvironmentGroupIds: sortedIDs(stack.EnvironmentGroupIds),
+			EdgeStack:           true,
+		}, nil
+	}
+
+	env, err := s.cli.GetStackEnv(id)
+	if err != nil {
+		return stackState{}, err
+	}
+
+	state := stackState{File: file}
+	if salt != "" {
+		state.EnvSalt = salt
+		state.EnvHashes = map[string]string{}
+	}
+	for _, entry := range env {
+		state.EnvNames = append(state.EnvNames, entry.Name)
+		if salt != "" {
+			state.EnvHashes[entry.Name] = envValueHash(salt, entry.Name, entry.Value)
+		}
+	}
+	slices.Sort(state.EnvNames)
+	return state, nil
+}
+
+// stackChangeKind returns the change kind of the writes to a stack of the given kind
+func stackChangeKind(kind string) string {
+	if kind == models.StackKindEdge {
+		return ChangeKindEdgeStack
+	}
+	return ChangeKindStack
+}
+
+// stackKind returns the kind of the stacks whose writes are recorded under a change kind
+func stackKind(changeKind string) string {
+	if changeKind == ChangeKindEdgeStack {
+		return models.StackKindEdge
+	}
+	return models.StackKindRegular
+}
+
+// newEnvSalt returns a random salt for the hashes of the environment variable values of a stack
+func newEnvSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate the environment salt: %w", err)
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+// envValueHash returns the keyed hash of the value of an environment variable
+func envValueHash(salt, name, value string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(name + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// notFoundError returns a not found error for a resource missing from a list call
+func notFoundError(resource string, id int) error {
+	return &client.APIError{Kind: client.ErrorKindNotFound, Message: fmt.Sprintf("%s %d not found", resource, id)}
+}
+
+// applyState restores the part of a resource identified by the change kind from its JSON encoded state
+func (s *PortainerMCPServer) applyState(kind string, id int, data json.RawMessage) error {
+	switch kind {
+	case ChangeKindEnvironmentTags, ChangeKindAccessGroupEnvironments, ChangeKindEnvironmentGroupEnvironments,
+		ChangeKindEnvironmentGroupTags, ChangeKindTeamMembers:
+		var ids []int
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		switch kind {
+		case ChangeKindEnvironmentTags:
+			return s.cli.UpdateEnvironmentTags(id, ids)
+		case ChangeKindAccessGroupEnvironments:
+			return s.applyAccessGroupEnvironments(id, ids)
+		case ChangeKindEnvironmentGroupEnvironments:
+			return s.cli.UpdateEnvironmentGroupEnvironments(id, ids)
+		case ChangeKindEnvironmentGroupTags:
+			return s.cli.UpdateEnvironmentGroupTags(id, ids)
+		default:
+			return s.cli.UpdateTeamMembers(id, ids)
+		}
+
+	case ChangeKindEnvironmentUserAccesses, ChangeKindEnvironmentTeamAccesses,
+		ChangeKindAccessGroupUserAccesses, ChangeKindAccessGroupTeamAccesses:
+		var accesses map[int]string
+		if err := json.Unmarshal(data, &accesses); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		switch kind {
+		case ChangeKindEnvironmentUserAccesses:
+			return s.cli.UpdateEnvironmentUserAccesses(id, accesses)
+		case ChangeKindEnvironmentTeamAccesses:
+			return s.cli.UpdateEnvironmentTeamAccesses(id, accesses)
+		case ChangeKindAccessGroupUserAccesses:
+			return s.cli.UpdateAccessGroupUserAccesses(id, accesses)
+		default:
+			return s.cli.UpdateAccessGroupTeamAccesses(id, accesses)
+		}
+
+	case ChangeKindAccessGroupName, ChangeKindEnvironmentGroupName, ChangeKindTeamName, ChangeKindUserRole:
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		switch kind {
+		case ChangeKindAccessGroupName:
+			return s.cli.UpdateAccessGroupName(id, value)
+		case ChangeKindEnvironmentGroupName:
+			return s.cli.UpdateEnvironmentGroupName(id, value)
+		case ChangeKindTeamName:
+			return s.cli.UpdateTeamName(id, value)
+		default:
+			return s.cli.UpdateUserRole(id, value)
+		}
+
+	case ChangeKindEnvironmentSettings:
+		var settings environmentSettings
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		return s.cli.UpdateEnvironment(id, &settings.Name, &settings.URL, &settings.PublicURL)
+
+	case ChangeKindStack, ChangeKindEdgeStack:
+		var stack stackState
+		if err := json.Unmarshal(data, &stack); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		if stack.EdgeStack {
+			return s.cli.UpdateStack(id, models.StackKindEdge, stack.File, stack.EnvironmentGroupIds, nil)
+		}
+		return s.applyStackState(id, stack)
+
+	case ChangeKindStackAutoUpdate:
+		var autoUpdate *models.StackAutoUpdate
+		if err := json.Unmarshal(data, &autoUpdate); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		return s.cli.UpdateStackAutoUpdate(id, autoUpdate)
+
+	case ChangeKindStackWebhook:
+		var webhook string
+		if err := json.Unmarshal(data, &webhook); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+
+		return s.cli.SetStackWebhook(id, webhook)
+
+	default:
+		return fmt.Errorf("unsupported change kind: %s", kind)
+	}
+}
+
+// applyStackState restores the file and environment variable names of a regular stack. The journal does not
+// store values, so the variables keep their current values, and the variables that are not set anymore cannot
+// be restored.
+func (s *PortainerMCPServer) applyStackState(id int, stack stackState) error {
+	current, err := s.cli.GetStackEnv(id)
+	if err != nil {
+		return err
+	}
+
+	env := []models.StackEnvVar{}
+	for _, entry := range current {
+		if slices.Contains(stack.EnvNames, entry.Name) {
+			env = append(env, entry)
+		}
+	}
+
+	return s.cli.ReplaceStack(id, stack.File, env)
+}
+
+// unrestoredEnvNote returns a note listing the environment variables of a recorded stack state that
+// could not be restored, because they were not set on the stack anymore when the change was reverted,
+// or because their values changed and the journal does not store values
+func unrestoredEnvNote(before, current json.RawMessage) string {
+	var recorded, replaced stackState
+	if json.Unmarshal(before, &recorded) != nil || json.Unmarshal(current, &replaced) != nil || recorded.EdgeStack {
+		return ""
+	}
+
+	var missing, changed []string
+	for _, name := range recorded.EnvNames {
+		if !slices.Contains(replaced.EnvNames, name) {
+			missing = append(missing, name)
+		} else if recorded.EnvSalt == "" || recorded.EnvSalt != replaced.EnvSalt ||
+			recorded.EnvHashes[name] != replaced.EnvHashes[name] {
+			changed = append(changed, name)
+		}
+	}
+
+	var note string
+	if len(missing) > 0 {
+		note += fmt.Sprintf("\n\nThe environment variables %s could not be restored, as the journal does not store values. Set them with setStackEnv.", strings.Join(missing, ", "))
+	}
+	if len(changed) > 0 {
+		if recorded.EnvSalt == "" {
+			note += fmt.Sprintf("\n\nThe values of the environment variables %s were not restored and may differ from the recorded state, which predates value tracking. Check them with listStackEnv.", strings.Join(changed, ", "))
+		} else {
+			note += fmt.Sprintf("\n\nThe values of the environment variables %s were not restored, as the journal does not store values: they keep the values set after the change. Set them with setStackEnv.", strings.Join(changed, ", "))
+		}
+	}
+	return note
+}
+
+// applyAccessGroupEnvironments adds and removes environments so that the access group contains exactly the given environments
+func (s *PortainerMCPServer) applyAccessGroupEnvironments(id int, environmentIds []int) error {
+	data, err := s.captureState(ChangeKindAccessGroupEnvironments, id)
+	if err != nil {
+		return err
+	}
+
+	var current []int
+	if err := json.Unmarshal(data, &current); err != nil {
+		return fmt.Errorf("invalid state: %w", err)
+	}
+
+	for _, environmentId := range current {
+		if !slices.Contains(environmentIds, environmentId) {
+			if err := s.cli.RemoveEnvironmentFromAccessGroup(id, environmentId); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, environmentId := range environmentIds {
+		if !slices.Contains(current, environmentId) {
+			if err := s.cli.AddEnvironmentToAccessGroup(id, environmentId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameState returns true if two JSON encoded states are equal, regardless of formatting and key order
+func sameState(a, b json.RawMessage) (bool, error) {
+	var left, right any
+	if err := json.Unmarshal(a, &left); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &right); err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(left, right), nil
+}
+
+// sortedIDs returns a sorted copy of a list of IDs, never nil so that empty lists are recorded consistently
+func sortedIDs(ids []int) []int {
+	sorted := append([]int{}, ids...)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// accessMap returns the access map, never nil so that empty maps are recorded consistently
+func accessMap(accesses map[int]string) map[int]string {
+	if accesses == nil {
+		return map[int]string{}
+	}
+	return accesses
+}
@@ -0,0 +1,343 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portainer/portainer-mcp/internal/journal"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJournal(t *testing.T) *journal.Journal {
+	t.Helper()
+	j, err := journal.Open("")
+	require.NoError(t, err)
+	return j
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotEmpty(t, result.Content)
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return textContent.Text
+}
+
+func TestUpdateAndRevertChange(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetTeams").Return([]models.Team{{ID: 1, Name: "team", MemberIDs: []int{2, 1}}}, nil).Once()
+	mockClient.On("UpdateTeamMembers", 1, []int{3}).Return(nil).Once()
+	mockClient.On("GetTeams").Return([]models.Team{{ID: 1, Name: "team", MemberIDs: []int{3}}}, nil).Twice()
+	mockClient.On("UpdateTeamMembers", 1, []int{1, 2}).Return(nil).Once()
+	mockClient.On("GetTeams").Return([]models.Team{{ID: 1, Name: "team", MemberIDs: []int{1, 2}}}, nil)
+	mockClient.On("InvalidateCache", []string{client.CacheKeyTeams}).Return()
+
+	server := &PortainerMCPServer{cli: mockClient, journal: newTestJournal(t)}
+
+	result, err := server.HandleUpdateTeamMembers()(context.Background(), CreateMCPRequest(map[string]any{
+		"id":      float64(1),
+		"userIds": []any{float64(3)},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "Team members updated successfully (change 1 recorded, use revertChange to undo)", resultText(t, result))
+
+	entry, ok := server.journal.Get(1)
+	require.True(t, ok)
+	assert.Equal(t, ToolUpdateTeamMembers, entry.Tool)
+	assert.Equal(t, ChangeKindTeamMembers, entry.Kind)
+	assert.JSONEq(t, `[1,2]`, string(entry.Before))
+	assert.JSONEq(t, `[3]`, string(entry.After))
+
+	result, err = server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "Change 1 reverted successfully (recorded as change 2)", resultText(t, result))
+
+	revert, ok := server.journal.Get(2)
+	require.True(t, ok)
+	assert.Equal(t, 1, revert.Reverts)
+	assert.JSONEq(t, `[3]`, string(revert.Before))
+	assert.JSONEq(t, `[1,2]`, string(revert.After))
+
+	result, err = server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(1)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "already been reverted by change 2")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestRevertChangeRefusesModifiedResource(t *testing.T) {
+	j := newTestJournal(t)
+	_, err := j.Record(journal.Entry{
+		Tool:       ToolUpdateEnvironmentTags,
+		Kind:       ChangeKindEnvironmentTags,
+		ResourceID: 1,
+		Before:     json.RawMessage(`[1]`),
+		After:      json.RawMessage(`[2]`),
+	})
+	require.NoError(t, err)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments").Return([]models.Environment{{ID: 1, TagIds: []int{2, 5}}}, nil)
+	mockClient.On("InvalidateCache", []string{client.CacheKeyEnvironments}).Return()
+
+	server := &PortainerMCPServer{cli: mockClient, journal: j}
+
+	result, err := server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(1)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "has changed since change 1 was recorded")
+	mockClient.AssertNotCalled(t, "UpdateEnvironmentTags", mock.Anything, mock.Anything)
+}
+
+func TestRevertStackChangeWithChangedValues(t *testing.T) {
+	salt := "salt"
+	j := newTestJournal(t)
+	_, err := j.Record(journal.Entry{
+		Tool:       ToolUpdateStack,
+		Kind:       ChangeKindStack,
+		ResourceID: 1,
+		Before: json.RawMessage(fmt.Sprintf(`{"file":"a","env_names":["TOKEN"],"env_salt":%q,"env_hashes":{"TOKEN":%q},"edge_stack":false}`,
+			salt, envValueHash(salt, "TOKEN", "old"))),
+		After: json.RawMessage(fmt.Sprintf(`{"file":"b","env_names":["TOKEN"],"env_salt":%q,"env_hashes":{"TOKEN":%q},"edge_stack":false}`,
+			salt, envValueHash(salt, "TOKEN", "new"))),
+	})
+	require.NoError(t, err)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("b", nil).Twice()
+	mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("a", nil)
+	mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{{Name: "TOKEN", Value: "new"}}, nil)
+	mockClient.On("ReplaceStack", 1, "a", []models.StackEnvVar{{Name: "TOKEN", Value: "new"}}).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient, journal: j}
+
+	result, err := server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "Change 1 partially reverted (recorded as change 2)")
+	assert.Contains(t, resultText(t, result), "The values of the environment variables TOKEN were not restored")
+	mockClient.AssertExpectations(t)
+}
+
+func TestRevertChangeNotFound(t *testing.T) {
+	server := &PortainerMCPServer{cli: &MockPortainerClient{}, journal: newTestJournal(t)}
+
+	result, err := server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(4)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "change 4 not found")
+}
+
+func TestUpdateProceedsWhenStateCannotBeCaptured(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetUsers").Return(nil, fmt.Errorf("api error"))
+	mockClient.On("InvalidateCache", []string{client.CacheKeyUsers}).Return()
+	mockClient.On("UpdateUserRole", 1, "admin").Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient, journal: newTestJournal(t)}
+
+	result, err := server.HandleUpdateUserRole()(context.Background(), CreateMCPRequest(map[string]any{
+		"id":   float64(1),
+		"role": "admin",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "warning: change not recorded")
+	mockClient.AssertCalled(t, "UpdateUserRole", 1, "admin")
+	assert.Empty(t, server.journal.List())
+}
+
+func TestHandleListChanges(t *testing.T) {
+	j := newTestJournal(t)
+	_, err := j.Record(journal.Entry{
+		Tool:       ToolUpdateStack,
+		Kind:       ChangeKindStack,
+		ResourceID: 4,
+		Before:     json.RawMessage(`{"file":"a","env_names":["TOKEN"],"edge_stack":false}`),
+		After:      json.RawMessage(`{"file":"b","env_names":["TOKEN"],"edge_stack":false}`),
+	})
+	require.NoError(t, err)
+	_, err = j.Record(journal.Entry{
+		Tool:       ToolUpdateUserRole,
+		Kind:       ChangeKindUserRole,
+		ResourceID: 2,
+		Before:     json.RawMessage(`"user"`),
+		After:      json.RawMessage(`"admin"`),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		expectedIDs []int
+	}{
+		{name: "all changes", args: map[string]any{}, expectedIDs: []int{2, 1}},
+		{name: "filter by kind", args: map[string]any{"kind": ChangeKindStack}, expectedIDs: []int{1}},
+		{name: "filter by resource", args: map[string]any{"resourceId": float64(2)}, expectedIDs: []int{2}},
+		{name: "no match", args: map[string]any{"kind": ChangeKindTeamName}, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &PortainerMCPServer{cli: &MockPortainerClient{}, journal: j}
+
+			result, err := server.HandleListChanges()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+
+			text := resultText(t, result)
+
+			var entries []journal.Entry
+			require.NoError(t, json.Unmarshal([]byte(text), &entries))
+
+			ids := []int{}
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestStackStateCaptureAndApply(t *testing.T) {
+	t.Run("regular stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("file", nil)
+		mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{
+			{Name: "TOKEN", Value: "secret"},
+			{Name: "DEBUG", Value: "1"},
+		}, nil).Once()
+		mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{
+			{Name: "TOKEN", Value: "secret"},
+			{Name: "ADDED", Value: "1"},
+		}, nil)
+		mockClient.On("ReplaceStack", 1, "file", []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}).Return(nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		state, err := server.captureState(ChangeKindStack, 1)
+		require.NoError(t, err)
+		assert.NotContains(t, string(state), "secret", "env values must not be recorded")
+
+		var stack stackState
+		require.NoError(t, json.Unmarshal(state, &stack))
+		assert.Equal(t, "file", stack.File)
+		assert.Equal(t, []string{"DEBUG", "TOKEN"}, stack.EnvNames)
+		assert.NotEmpty(t, stack.EnvSalt)
+		assert.Equal(t, envValueHash(stack.EnvSalt, "TOKEN", "secret"), stack.EnvHashes["TOKEN"])
+
+		// Variables keep their current values, and those that are not part of the state are removed
+		require.NoError(t, server.applyState(ChangeKindStack, 1, state))
+
+		current, err := server.captureStateLike(ChangeKindStack, 1, state)
+		require.NoError(t, err)
+		var replaced stackState
+		require.NoError(t, json.Unmarshal(current, &replaced))
+		assert.Equal(t, stack.EnvSalt, replaced.EnvSalt, "states compared with each other must share the salt")
+		mockClient.AssertExpectations(t)
+
+		note := unrestoredEnvNote(state, current)
+		assert.Contains(t, note, "The environment variables DEBUG could not be restored")
+		assert.NotContains(t, note, "TOKEN", "unchanged values must not be reported")
+
+		replaced.EnvHashes["TOKEN"] = envValueHash(stack.EnvSalt, "TOKEN", "new")
+		changed, err := json.Marshal(replaced)
+		require.NoError(t, err)
+		assert.Contains(t, unrestoredEnvNote(state, changed), "The values of the environment variables TOKEN were not restored")
+	})
+
+	t.Run("legacy regular stack state", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("file", nil)
+		mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		legacy := json.RawMessage(`{"file":"file","env_names":["TOKEN"],"edge_stack":false}`)
+		current, err := server.captureStateLike(ChangeKindStack, 1, legacy)
+		require.NoError(t, err)
+		equal, err := sameState(current, legacy)
+		require.NoError(t, err)
+		assert.True(t, equal, "states recorded without hashes must be compared without hashes")
+
+		assert.Contains(t, unrestoredEnvNote(legacy, current), "The values of the environment variables TOKEN were not restored and may differ")
+	})
+
+	t.Run("edge stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 2, models.StackKindEdge).Return("file", nil)
+		mockClient.On("GetStack", 2, models.StackKindEdge).Return(models.Stack{ID: 2, Kind: models.StackKindEdge, EnvironmentGroupIds: []int{3, 1}}, nil)
+		mockClient.On("UpdateStack", 2, models.StackKindEdge, "file", []int{1, 3}, []models.StackEnvVar(nil)).Return(nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		state, err := server.captureState(ChangeKindEdgeStack, 2)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"file":"file","environment_group_ids":[1,3],"edge_stack":true}`, string(state))
+
+		// Edge stack changes used to be recorded under the stack change kind
+		legacy, err := server.captureStateLike(ChangeKindStack, 2, state)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(state), string(legacy))
+
+		require.NoError(t, server.applyState(ChangeKindStack, 2, state))
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestEnvironmentSettingsCaptureAndApply(t *testing.T) {
+	name, environmentURL, publicURL := "env", "tcp://10.0.0.1:9001", ""
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironment", 1).Return(models.EnvironmentDetails{
+		Environment: models.Environment{ID: 1, Name: name},
+		URL:         environmentURL,
+	}, nil)
+	mockClient.On("UpdateEnvironment", 1, &name, &environmentURL, &publicURL).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	state, err := server.captureState(ChangeKindEnvironmentSettings, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"env","url":"tcp://10.0.0.1:9001","public_url":""}`, string(state))
+
+	require.NoError(t, server.applyState(ChangeKindEnvironmentSettings, 1, state))
+	mockClient.AssertExpectations(t)
+}
+
+func TestApplyAccessGroupEnvironments(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 1, EnvironmentIds: []int{1, 2}}}, nil)
+	mockClient.On("RemoveEnvironmentFromAccessGroup", 1, 1).Return(nil)
+	mockClient.On("AddEnvironmentToAccessGroup", 1, 3).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	err := server.applyState(ChangeKindAccessGroupEnvironments, 1, json.RawMessage(`[2,3]`))
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "AddEnvironmentToAccessGroup", 1, 2)
+}
+
+func TestJournalToolsDisabled(t *testing.T) {
+	server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+
+	result, err := server.HandleListChanges()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = server.HandleRevertChange()(context.Background(), CreateMCPRequest(map[string]any{"changeId": float64(1)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -66,12 +66,12 @@ func (s *PortainerMCPServer) HandleKubernetesProxyStripped() server.ToolHandlerF
 
 		response, err := s.cli.ProxyKubernetesRequest(opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to send Kubernetes API request", err), nil
+			return newToolResultAPIError("failed to send Kubernetes API request", err), nil
 		}
 
 		responseBody, err := k8sutil.ProcessRawKubernetesAPIResponse(response)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to process Kubernetes API response", err), nil
+			return newToolResultAPIError("failed to process Kubernetes API response", err), nil
 		}
 
 		return mcp.NewToolResultText(string(responseBody)), nil
@@ -140,12 +140,13 @@ func (s *PortainerMCPServer) HandleKubernetesProxy() server.ToolHandlerFunc {
 
 		response, err := s.cli.ProxyKubernetesRequest(opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to send Kubernetes API request", err), nil
+			return newToolResultAPIError("failed to send Kubernetes API request", err), nil
 		}
+		defer response.Body.Close()
 
 		responseBody, err := io.ReadAll(response.Body)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to read Kubernetes API response", err), nil
+			return newToolResultAPIError("failed to read Kubernetes API response", err), nil
 		}
 
 		return mcp.NewToolResultText(string(responseBody)), nil
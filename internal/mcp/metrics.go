@@ -0,0 +1,24 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (s *PortainerMCPServer) AddMetricsFeatures() {
+	s.addToolIfExists(ToolGetServerMetrics, s.HandleGetServerMetrics())
+}
+
+func (s *PortainerMCPServer) HandleGetServerMetrics() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data, err := json.Marshal(s.metrics.Snapshot())
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal metrics", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portainer/portainer-mcp/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetServerMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry func() *metrics.Registry
+		expected map[string]int64
+	}{
+		{
+			name: "recorded metrics",
+			registry: func() *metrics.Registry {
+				registry := metrics.NewRegistry()
+				registry.Set("ratelimit.tool.per_minute", 60)
+				registry.Add("ratelimit.tool.dockerProxy.throttled", 2)
+				return registry
+			},
+			expected: map[string]int64{
+				"ratelimit.tool.per_minute":            60,
+				"ratelimit.tool.dockerProxy.throttled": 2,
+			},
+		},
+		{
+			name:     "no registry",
+			registry: func() *metrics.Registry { return nil },
+			expected: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &PortainerMCPServer{
+				metrics: tt.registry(),
+			}
+
+			handler := server.HandleGetServerMetrics()
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+
+			require.NoError(t, err)
+			require.Len(t, result.Content, 1)
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var snapshot map[string]int64
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &snapshot))
+			assert.Equal(t, tt.expected, snapshot)
+		})
+	}
+}
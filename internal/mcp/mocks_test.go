@@ -69,6 +69,31 @@ func (m *MockPortainerClient) GetEnvironments() ([]models.Environment, error) {
 	return args.Get(0).([]models.Environment), args.Error(1)
 }
 
+func (m *MockPortainerClient) GetEnvironment(id int) (models.EnvironmentDetails, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.EnvironmentDetails), args.Error(1)
+}
+
+func (m *MockPortainerClient) CreateEnvironment(opts models.EnvironmentCreateOptions) (models.CreatedEnvironment, error) {
+	args := m.Called(opts)
+	return args.Get(0).(models.CreatedEnvironment), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateEnvironment(id int, name *string, environmentURL *string, publicURL *string) error {
+	args := m.Called(id, name, environmentURL, publicURL)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) DeleteEnvironment(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) ListEnvironments(opts models.EnvironmentListOptions) (models.EnvironmentPage, error) {
+	args := m.Called(opts)
+	return args.Get(0).(models.EnvironmentPage), args.Error(1)
+}
+
 func (m *MockPortainerClient) UpdateEnvironmentTags(id int, tagIds []int) error {
 	args := m.Called(id, tagIds)
 	return args.Error(0)
@@ -164,7 +189,22 @@ func (m *MockPortainerClient) GetStacks() ([]models.Stack, error) {
 	return args.Get(0).([]models.Stack), args.Error(1)
 }
 
-func (m *MockPortainerClient) GetStackFile(id int) (string, error) {
+func (m *MockPortainerClient) GetStack(id int, kind string) (models.Stack, error) {
+	args := m.Called(id, kind)
+	return args.Get(0).(models.Stack), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetEdgeStackStatus(id int) (models.EdgeStackStatus, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.EdgeStackStatus), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetStackFile(id int, kind string) (string, error) {
+	args := m.Called(id, kind)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetEdgeStackFile(id int) (string, error) {
 	args := m.Called(id)
 	return args.String(0), args.Error(1)
 }
@@ -177,16 +217,119 @@ func (m *MockPortainerClient) GetStackEnvNames(id int) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockPortainerClient) CreateStack(name string, file string, environmentGroupIds []int) (int, error) {
-	args := m.Called(name, file, environmentGroupIds)
+func (m *MockPortainerClient) GetStackEnv(id int) ([]models.StackEnvVar, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StackEnvVar), args.Error(1)
+}
+
+func (m *MockPortainerClient) CreateStack(opts models.StackCreateOptions) (int, error) {
+	args := m.Called(opts)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateStack(id int, kind string, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error {
+	args := m.Called(id, kind, file, environmentGroupIds, envOverrides)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) CreateStackFromGit(opts models.StackGitCreateOptions) (int, error) {
+	args := m.Called(opts)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockPortainerClient) UpdateStack(id int, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error {
-	args := m.Called(id, file, environmentGroupIds, envOverrides)
+func (m *MockPortainerClient) GetStackGitSettings(id int) (models.StackGitSettings, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.StackGitSettings), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateStackAutoUpdate(id int, autoUpdate *models.StackAutoUpdate) error {
+	args := m.Called(id, autoUpdate)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) RedeployStackFromGit(id int, opts models.StackGitRedeployOptions) error {
+	args := m.Called(id, opts)
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) GetStackWebhook(id int) (models.StackWebhook, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.StackWebhook), args.Error(1)
+}
+
+func (m *MockPortainerClient) SetStackWebhook(id int, webhook string) error {
+	args := m.Called(id, webhook)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) TriggerStackWebhook(webhook string) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) StartStack(id int, kind string) error {
+	args := m.Called(id, kind)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) StopStack(id int, kind string) error {
+	args := m.Called(id, kind)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) RedeployStack(id int, kind string, opts models.StackRedeployOptions) error {
+	args := m.Called(id, kind, opts)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) DeleteStack(id int, kind string, removeVolumes bool) error {
+	args := m.Called(id, kind, removeVolumes)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) ReplaceStack(id int, file string, env []models.StackEnvVar) error {
+	args := m.Called(id, file, env)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) UpdateStackEnv(id int, change models.StackEnvChange) error {
+	args := m.Called(id, change)
+	return args.Error(0)
+}
+
+func (m *MockPortainerClient) CreateKubernetesStack(opts models.KubernetesStackCreateOptions) (int, error) {
+	args := m.Called(opts)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateKubernetesStack(id int, manifest string) error {
+	args := m.Called(id, manifest)
+	return args.Error(0)
+}
+
+// Custom template methods
+
+func (m *MockPortainerClient) GetCustomTemplates() ([]models.CustomTemplate, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CustomTemplate), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetCustomTemplateFile(id int) (string, error) {
+	args := m.Called(id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) CreateCustomTemplate(opts models.CustomTemplateCreateOptions) (int, error) {
+	args := m.Called(opts)
+	return args.Int(0), args.Error(1)
+}
+
 // Team methods
 
 func (m *MockPortainerClient) CreateTeam(name string) (int, error) {
@@ -262,3 +405,8 @@ func (m *MockPortainerClient) ProxyKubernetesRequest(opts models.KubernetesProxy
 	}
 	return args.Get(0).(*http.Response), args.Error(1)
 }
+
+// Cache methods
+func (m *MockPortainerClient) InvalidateCache(keys ...string) {
+	m.Called(keys)
+}
@@ -0,0 +1,306 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// cursorParam is the optional tool argument used to fetch the next chunk of a truncated response
+	cursorParam = "cursor"
+	// pagedResponseTTL is how long a truncated response is kept in memory for follow-up calls
+	pagedResponseTTL = 10 * time.Minute
+	// maxPagedResponses is the maximum number of truncated responses kept in memory at once
+	maxPagedResponses = 32
+)
+
+// pagedResponse holds the full result of a tool call that exceeded the response size budget.
+// Exactly one of items or text is set: items when the result is a JSON array, text otherwise.
+type pagedResponse struct {
+	toolName  string
+	items     []json.RawMessage
+	text      string
+	createdAt time.Time
+}
+
+// truncationNotice is appended to a truncated tool result to tell the model how to continue.
+type truncationNotice struct {
+	Truncated     bool   `json:"truncated"`
+	NextCursor    string `json:"nextCursor,omitempty"`
+	ReturnedItems int    `json:"returnedItems,omitempty"`
+	TotalItems    int    `json:"totalItems,omitempty"`
+	ReturnedBytes int    `json:"returnedBytes,omitempty"`
+	TotalBytes    int    `json:"totalBytes,omitempty"`
+	Message       string `json:"message"`
+}
+
+// responsePager enforces a maximum size on tool results.
+// Oversized results are stored in memory and returned chunk by chunk,
+// each chunk carrying a cursor that can be passed back to the same tool to fetch the next one.
+type responsePager struct {
+	maxSize int
+	now     func() time.Time
+
+	mu        sync.Mutex
+	responses map[string]*pagedResponse
+	order     []string
+}
+
+// newResponsePager creates a pager that limits tool results to maxSize bytes
+func newResponsePager(maxSize int) *responsePager {
+	return &responsePager{
+		maxSize:   maxSize,
+		now:       time.Now,
+		responses: map[string]*pagedResponse{},
+	}
+}
+
+// middleware returns a tool handler middleware that applies the response size budget
+// and serves follow-up calls that provide a cursor
+func (p *responsePager) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cursor, _ := request.GetArguments()[cursorParam].(string)
+		if cursor != "" {
+			return p.continueFrom(request.Params.Name, cursor), nil
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		return p.apply(request.Params.Name, result), nil
+	}
+}
+
+// rejectCursorMiddleware is used when the response size budget is disabled: no response is ever
+// truncated, so a cursor cannot reference anything and is rejected instead of being silently ignored
+func rejectCursorMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if cursor, _ := request.GetArguments()[cursorParam].(string); cursor != "" {
+			return mcp.NewToolResultError("invalid cursor parameter: responses are not truncated by this server, call the tool again without a cursor"), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// apply truncates the result if its text content exceeds the response size budget
+func (p *responsePager) apply(toolName string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if len(result.Content) != 1 {
+		return result
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok || len(textContent.Text) <= p.maxSize {
+		return result
+	}
+
+	response := &pagedResponse{
+		toolName:  toolName,
+		createdAt: p.now(),
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(textContent.Text), &items); err == nil {
+		response.items = items
+	} else {
+		response.text = textContent.Text
+	}
+
+	id, err := p.store(response)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to store truncated response", err)
+	}
+
+	return p.page(id, response, 0)
+}
+
+// continueFrom returns the chunk of a stored response referenced by the cursor
+func (p *responsePager) continueFrom(toolName, cursor string) *mcp.CallToolResult {
+	id, offset, err := decodeCursor(cursor)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("invalid cursor parameter", err)
+	}
+
+	p.mu.Lock()
+	p.evictExpired()
+	response, exists := p.responses[id]
+	p.mu.Unlock()
+
+	if !exists {
+		return mcp.NewToolResultError("cursor has expired or is unknown, call the tool again without a cursor")
+	}
+
+	if response.toolName != toolName {
+		return mcp.NewToolResultError(fmt.Sprintf("cursor was issued by tool %s and cannot be used with %s", response.toolName, toolName))
+	}
+
+	return p.page(id, response, offset)
+}
+
+// page builds the tool result for the chunk of a stored response starting at offset.
+// The offset is an item index for JSON arrays and a byte index for text.
+func (p *responsePager) page(id string, response *pagedResponse, offset int) *mcp.CallToolResult {
+	if response.items != nil {
+		return p.itemsPage(id, response.items, offset)
+	}
+	return p.textPage(id, response.text, offset)
+}
+
+func (p *responsePager) itemsPage(id string, items []json.RawMessage, offset int) *mcp.CallToolResult {
+	if offset < 0 || offset > len(items) {
+		return mcp.NewToolResultError(fmt.Sprintf("cursor offset %d is out of range", offset))
+	}
+
+	// Always return at least one item so that every call makes progress,
+	// even if a single item is larger than the budget.
+	end := offset
+	size := len("[]")
+	for end < len(items) {
+		itemSize := len(items[end])
+		if end > offset {
+			itemSize++ // separating comma
+		}
+		if end > offset && size+itemSize > p.maxSize {
+			break
+		}
+		size += itemSize
+		end++
+	}
+
+	data, err := json.Marshal(items[offset:end])
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to marshal response chunk", err)
+	}
+
+	notice := truncationNotice{
+		Truncated:     end < len(items),
+		ReturnedItems: end - offset,
+		TotalItems:    len(items),
+	}
+	if notice.Truncated {
+		notice.NextCursor = encodeCursor(id, end)
+		notice.Message = fmt.Sprintf("Response truncated: returned items %d to %d of %d. Call the tool again with the nextCursor value as the cursor argument to fetch more.", offset+1, end, len(items))
+	} else {
+		notice.Message = fmt.Sprintf("Final chunk: returned items %d to %d of %d.", offset+1, end, len(items))
+	}
+
+	return newPagedResult(string(data), notice)
+}
+
+func (p *responsePager) textPage(id string, text string, offset int) *mcp.CallToolResult {
+	if offset < 0 || offset > len(text) {
+		return mcp.NewToolResultError(fmt.Sprintf("cursor offset %d is out of range", offset))
+	}
+
+	end := min(offset+p.maxSize, len(text))
+	// Avoid splitting a multi-byte character across two chunks
+	for end < len(text) && end > offset && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	notice := truncationNotice{
+		Truncated:     end < len(text),
+		ReturnedBytes: end - offset,
+		TotalBytes:    len(text),
+	}
+	if notice.Truncated {
+		notice.NextCursor = encodeCursor(id, end)
+		notice.Message = fmt.Sprintf("Response truncated: returned bytes %d to %d of %d. Call the tool again with the nextCursor value as the cursor argument to fetch more.", offset, end, len(text))
+	} else {
+		notice.Message = fmt.Sprintf("Final chunk: returned bytes %d to %d of %d.", offset, end, len(text))
+	}
+
+	return newPagedResult(text[offset:end], notice)
+}
+
+// store saves a response and returns its identifier, evicting the oldest responses when full
+func (p *responsePager) store(response *pagedResponse) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictExpired()
+	for len(p.order) >= maxPagedResponses {
+		delete(p.responses, p.order[0])
+		p.order = p.order[1:]
+	}
+
+	p.responses[id] = response
+	p.order = append(p.order, id)
+
+	return id, nil
+}
+
+// evictExpired removes responses older than pagedResponseTTL. The caller must hold p.mu.
+func (p *responsePager) evictExpired() {
+	cutoff := p.now().Add(-pagedResponseTTL)
+
+	kept := p.order[:0]
+	for _, id := range p.order {
+		if p.responses[id].createdAt.Before(cutoff) {
+			delete(p.responses, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	p.order = kept
+}
+
+// newPagedResult creates a tool result holding a response chunk followed by a truncation notice
+func newPagedResult(chunk string, notice truncationNotice) *mcp.CallToolResult {
+	data, err := json.Marshal(notice)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to marshal truncation notice", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(chunk),
+			mcp.NewTextContent(string(data)),
+		},
+	}
+}
+
+// encodeCursor creates an opaque cursor referencing a stored response and an offset within it
+func encodeCursor(id string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id + ":" + strconv.Itoa(offset)))
+}
+
+// decodeCursor extracts the stored response identifier and offset from a cursor
+func decodeCursor(cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed cursor: %w", err)
+	}
+
+	id, offsetStr, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return "", 0, fmt.Errorf("malformed cursor: %s", cursor)
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed cursor offset: %w", err)
+	}
+
+	return id, offset, nil
+}
@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTextHandler(text string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+func newToolRequest(toolName string, args map[string]any) mcp.CallToolRequest {
+	request := CreateMCPRequest(args)
+	request.Params.Name = toolName
+	return request
+}
+
+func decodeNotice(t *testing.T, result *mcp.CallToolResult) truncationNotice {
+	t.Helper()
+	require.Len(t, result.Content, 2)
+	textContent, ok := result.Content[1].(mcp.TextContent)
+	require.True(t, ok)
+
+	var notice truncationNotice
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &notice))
+	return notice
+}
+
+func TestResponsePagerPassthrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	}{
+		{
+			name:    "small result",
+			handler: newTextHandler(`[{"id":1}]`),
+		},
+		{
+			name: "error result",
+			handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultError(strings.Repeat("x", 100)), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := newResponsePager(50)
+			expected, _ := tt.handler(context.Background(), mcp.CallToolRequest{})
+
+			result, err := pager.middleware(tt.handler)(context.Background(), newToolRequest("listStacks", nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+			assert.Empty(t, pager.responses)
+		})
+	}
+}
+
+func TestResponsePagerJSONArray(t *testing.T) {
+	items := make([]map[string]any, 10)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": fmt.Sprintf("stack-%d", i)}
+	}
+	data, err := json.Marshal(items)
+	require.NoError(t, err)
+
+	pager := newResponsePager(100)
+	handler := pager.middleware(newTextHandler(string(data)))
+
+	var collected []map[string]any
+	request := newToolRequest("listStacks", nil)
+	for range len(items) {
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		chunk, ok := result.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		assert.LessOrEqual(t, len(chunk.Text), 100)
+
+		var page []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(chunk.Text), &page), "each chunk must be a valid JSON array")
+		collected = append(collected, page...)
+
+		notice := decodeNotice(t, result)
+		assert.Equal(t, len(items), notice.TotalItems)
+		assert.Equal(t, len(page), notice.ReturnedItems)
+		if !notice.Truncated {
+			assert.Empty(t, notice.NextCursor)
+			break
+		}
+		request = newToolRequest("listStacks", map[string]any{cursorParam: notice.NextCursor})
+	}
+
+	require.Len(t, collected, len(items))
+	for i, item := range collected {
+		assert.Equal(t, float64(i), item["id"])
+	}
+}
+
+func TestResponsePagerOversizedItem(t *testing.T) {
+	data := fmt.Sprintf(`[{"name":%q},{"id":2}]`, strings.Repeat("a", 200))
+
+	pager := newResponsePager(50)
+	result, err := pager.middleware(newTextHandler(data))(context.Background(), newToolRequest("listStacks", nil))
+
+	require.NoError(t, err)
+	notice := decodeNotice(t, result)
+	assert.True(t, notice.Truncated)
+	assert.Equal(t, 1, notice.ReturnedItems, "an item larger than the budget must still be returned")
+}
+
+func TestResponsePagerText(t *testing.T) {
+	text := strings.Repeat("héllo wörld ", 40)
+
+	pager := newResponsePager(64)
+	handler := pager.middleware(newTextHandler(text))
+
+	var builder strings.Builder
+	request := newToolRequest("dockerProxy", map[string]any{"environmentId": float64(1)})
+	for range len(text) {
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+
+		chunk, ok := result.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		assert.LessOrEqual(t, len(chunk.Text), 64)
+		assert.True(t, utf8.ValidString(chunk.Text), "chunks must not split characters")
+		builder.WriteString(chunk.Text)
+
+		notice := decodeNotice(t, result)
+		assert.Equal(t, len(text), notice.TotalBytes)
+		if !notice.Truncated {
+			break
+		}
+		request = newToolRequest("dockerProxy", map[string]any{cursorParam: notice.NextCursor})
+	}
+
+	assert.Equal(t, text, builder.String())
+}
+
+func TestResponsePagerInvalidCursor(t *testing.T) {
+	pager := newResponsePager(10)
+	first, err := pager.middleware(newTextHandler(strings.Repeat("x", 30)))(context.Background(), newToolRequest("dockerProxy", nil))
+	require.NoError(t, err)
+	cursor := decodeNotice(t, first).NextCursor
+
+	tests := []struct {
+		name          string
+		toolName      string
+		cursor        string
+		advance       time.Duration
+		errorContains string
+	}{
+		{
+			name:          "malformed cursor",
+			toolName:      "dockerProxy",
+			cursor:        "%%%",
+			errorContains: "invalid cursor parameter",
+		},
+		{
+			name:          "unknown cursor",
+			toolName:      "dockerProxy",
+			cursor:        encodeCursor("unknown", 10),
+			errorContains: "expired or is unknown",
+		},
+		{
+			name:          "cursor from another tool",
+			toolName:      "listStacks",
+			cursor:        cursor,
+			errorContains: "cannot be used with listStacks",
+		},
+		{
+			name:          "out of range offset",
+			toolName:      "dockerProxy",
+			cursor:        encodeCursor(mustDecodeCursorID(t, cursor), 500),
+			errorContains: "out of range",
+		},
+		{
+			name:          "expired cursor",
+			toolName:      "dockerProxy",
+			cursor:        cursor,
+			advance:       pagedResponseTTL + time.Minute,
+			errorContains: "expired or is unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.advance > 0 {
+				pager.now = func() time.Time { return time.Now().Add(tt.advance) }
+			}
+
+			handler := pager.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				t.Fatal("handler must not be called when a cursor is provided")
+				return nil, nil
+			})
+
+			result, err := handler(context.Background(), newToolRequest(tt.toolName, map[string]any{cursorParam: tt.cursor}))
+
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tt.errorContains)
+		})
+	}
+}
+
+func TestResponsePagerEviction(t *testing.T) {
+	pager := newResponsePager(10)
+	handler := pager.middleware(newTextHandler(strings.Repeat("x", 30)))
+
+	for range maxPagedResponses + 5 {
+		_, err := handler(context.Background(), newToolRequest("dockerProxy", nil))
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, pager.responses, maxPagedResponses)
+	assert.Len(t, pager.order, maxPagedResponses)
+}
+
+func mustDecodeCursorID(t *testing.T, cursor string) string {
+	t.Helper()
+	id, _, err := decodeCursor(cursor)
+	require.NoError(t, err)
+	return id
+}
+
+func TestRejectCursorMiddleware(t *testing.T) {
+	handler := rejectCursorMiddleware(newTextHandler("ok"))
+
+	result, err := handler(context.Background(), newToolRequest("listStacks", nil))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	result, err = handler(context.Background(), newToolRequest("listStacks", map[string]any{cursorParam: encodeCursor("abc", 10)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "responses are not truncated by this server")
+}
@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/metrics"
+)
+
+// Rate limit scopes reported in rate limit errors and metrics
+const (
+	RateLimitScopeTool        = "tool"
+	RateLimitScopeEnvironment = "environment"
+)
+
+// environmentArguments maps the tools that do not take the ID of the environment they target in the
+// environmentId argument to the name of that argument
+var environmentArguments = map[string]string{
+	ToolGetEnvironment:                "id",
+	ToolUpdateEnvironment:             "id",
+	ToolDeleteEnvironment:             "id",
+	ToolUpdateEnvironmentTags:         "id",
+	ToolUpdateEnvironmentUserAccesses: "id",
+	ToolUpdateEnvironmentTeamAccesses: "id",
+}
+
+// environmentArgument returns the name of the argument holding the ID of the environment targeted by a tool
+func environmentArgument(toolName string) string {
+	if name, ok := environmentArguments[toolName]; ok {
+		return name
+	}
+	return "environmentId"
+}
+
+// RateLimit configures a token bucket: PerMinute tokens are added every minute,
+// up to a maximum of Burst tokens. A PerMinute value of 0 or less disables the limit.
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// enabled returns true if the rate limit is configured
+func (r RateLimit) enabled() bool {
+	return r.PerMinute > 0
+}
+
+// rateLimitError is returned to the model when a tool call is rejected by a rate limit
+type rateLimitError struct {
+	Error             string  `json:"error"`
+	Scope             string  `json:"scope"`
+	Key               string  `json:"key"`
+	RetryAfterSeconds float64 `json:"retryAfterSeconds"`
+	Message           string  `json:"message"`
+}
+
+// tokenBucket implements the token bucket algorithm
+type tokenBucket struct {
+	ratePerSecond float64
+	burst         float64
+	tokens        float64
+	last          time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	burst := float64(max(limit.Burst, 1))
+	return &tokenBucket{
+		ratePerSecond: float64(limit.PerMinute) / 60,
+		burst:         burst,
+		tokens:        burst,
+		last:          now,
+	}
+}
+
+// take consumes a token if one is available.
+// Otherwise, it returns the duration to wait until the next token is available.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.ratePerSecond)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / b.ratePerSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// rateLimiter applies token bucket rate limits to tool calls, per tool and per environment.
+type rateLimiter struct {
+	toolLimit        RateLimit
+	environmentLimit RateLimit
+	metrics          *metrics.Registry
+	now              func() time.Time
+
+	mu                 sync.Mutex
+	toolBuckets        map[string]*tokenBucket
+	environmentBuckets map[int]*tokenBucket
+}
+
+// newRateLimiter creates a rate limiter and reports its configuration in the metrics registry
+func newRateLimiter(toolLimit, environmentLimit RateLimit, registry *metrics.Registry) *rateLimiter {
+	registry.Set("ratelimit.tool.per_minute", int64(toolLimit.PerMinute))
+	registry.Set("ratelimit.tool.burst", int64(toolLimit.Burst))
+	registry.Set("ratelimit.environment.per_minute", int64(environmentLimit.PerMinute))
+	registry.Set("ratelimit.environment.burst", int64(environmentLimit.Burst))
+
+	return &rateLimiter{
+		toolLimit:          toolLimit,
+		environmentLimit:   environmentLimit,
+		metrics:            registry,
+		now:                time.Now,
+		toolBuckets:        map[string]*tokenBucket{},
+		environmentBuckets: map[int]*tokenBucket{},
+	}
+}
+
+// middleware returns a tool handler middleware that rejects calls exceeding the rate limits
+func (l *rateLimiter) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolName := request.Params.Name
+
+		environmentId := -1
+		if id, ok := request.GetArguments()[environmentArgument(toolName)].(float64); ok {
+			environmentId = int(id)
+		}
+
+		if result := l.check(toolName, environmentId); result != nil {
+			return result, nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// check consumes a token from the tool bucket and, if the call targets an environment, from the
+// environment bucket. It returns a tool error result if any of the limits is exceeded.
+func (l *rateLimiter) check(toolName string, environmentId int) *mcp.CallToolResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if l.toolLimit.enabled() {
+		bucket, exists := l.toolBuckets[toolName]
+		if !exists {
+			bucket = newTokenBucket(l.toolLimit, now)
+			l.toolBuckets[toolName] = bucket
+		}
+
+		if ok, retryAfter := bucket.take(now); !ok {
+			l.metrics.Add("ratelimit.tool."+toolName+".throttled", 1)
+			return newRateLimitResult(RateLimitScopeTool, toolName, retryAfter)
+		}
+	}
+
+	if l.environmentLimit.enabled() && environmentId >= 0 {
+		bucket, exists := l.environmentBuckets[environmentId]
+		if !exists {
+			bucket = newTokenBucket(l.environmentLimit, now)
+			l.environmentBuckets[environmentId] = bucket
+		}
+
+		if ok, retryAfter := bucket.take(now); !ok {
+			// Environment IDs are not bounded, so throttled calls are counted for all environments together
+			l.metrics.Add("ratelimit.environment.throttled", 1)
+			return newRateLimitResult(RateLimitScopeEnvironment, strconv.Itoa(environmentId), retryAfter)
+		}
+	}
+
+	return nil
+}
+
+// newRateLimitResult creates a structured tool error telling the model when to retry
+func newRateLimitResult(scope, key string, retryAfter time.Duration) *mcp.CallToolResult {
+	retryAfterSeconds := math.Ceil(retryAfter.Seconds()*10) / 10
+
+	data, err := json.Marshal(rateLimitError{
+		Error:             "rate_limited",
+		Scope:             scope,
+		Key:               key,
+		RetryAfterSeconds: retryAfterSeconds,
+		Message:           fmt.Sprintf("rate limit exceeded for %s %s, retry after %.1f seconds", scope, key, retryAfterSeconds),
+	})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("rate limit exceeded", err)
+	}
+
+	return mcp.NewToolResultError(string(data))
+}
@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portainer/portainer-mcp/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucket(RateLimit{PerMinute: 60, Burst: 2}, start)
+
+	ok, _ := bucket.take(start)
+	assert.True(t, ok)
+	ok, _ = bucket.take(start)
+	assert.True(t, ok)
+
+	ok, retryAfter := bucket.take(start)
+	assert.False(t, ok, "burst should be exhausted")
+	assert.Equal(t, time.Second, retryAfter)
+
+	ok, retryAfter = bucket.take(start.Add(500 * time.Millisecond))
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _ = bucket.take(start.Add(time.Second))
+	assert.True(t, ok, "a token should be refilled after one second")
+
+	ok, _ = bucket.take(start.Add(time.Hour))
+	assert.True(t, ok)
+	ok, _ = bucket.take(start.Add(time.Hour))
+	assert.True(t, ok)
+	ok, _ = bucket.take(start.Add(time.Hour))
+	assert.False(t, ok, "refill must be capped at the burst size")
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		toolLimit        RateLimit
+		environmentLimit RateLimit
+		calls            []mcp.CallToolRequest
+		expectedAllowed  []bool
+		expectedScope    string
+		expectedKey      string
+		expectedMetric   string
+	}{
+		{
+			name:      "per tool limit",
+			toolLimit: RateLimit{PerMinute: 60, Burst: 1},
+			calls: []mcp.CallToolRequest{
+				newToolRequest("listStacks", nil),
+				newToolRequest("listEnvironments", nil),
+				newToolRequest("listStacks", nil),
+			},
+			expectedAllowed: []bool{true, true, false},
+			expectedScope:   RateLimitScopeTool,
+			expectedKey:     "listStacks",
+			expectedMetric:  "ratelimit.tool.listStacks.throttled",
+		},
+		{
+			name:             "per environment limit",
+			environmentLimit: RateLimit{PerMinute: 60, Burst: 2},
+			calls: []mcp.CallToolRequest{
+				newToolRequest("dockerProxy", map[string]any{"environmentId": float64(1)}),
+				newToolRequest("kubernetesProxy", map[string]any{"environmentId": float64(2)}),
+				newToolRequest("dockerProxy", map[string]any{"environmentId": float64(1)}),
+				newToolRequest("listStacks", nil),
+				newToolRequest("getKubernetesResourceStripped", map[string]any{"environmentId": float64(1)}),
+			},
+			expectedAllowed: []bool{true, true, true, true, false},
+			expectedScope:   RateLimitScopeEnvironment,
+			expectedKey:     "1",
+			expectedMetric:  "ratelimit.environment.throttled",
+		},
+		{
+			name:             "per environment limit with the environment in the id argument",
+			environmentLimit: RateLimit{PerMinute: 60, Burst: 2},
+			calls: []mcp.CallToolRequest{
+				newToolRequest(ToolGetEnvironment, map[string]any{"id": float64(4)}),
+				newToolRequest(ToolGetStack, map[string]any{"id": float64(4)}),
+				newToolRequest(ToolUpdateEnvironmentTags, map[string]any{"id": float64(4)}),
+				newToolRequest("dockerProxy", map[string]any{"environmentId": float64(4)}),
+			},
+			expectedAllowed: []bool{true, true, true, false},
+			expectedScope:   RateLimitScopeEnvironment,
+			expectedKey:     "4",
+			expectedMetric:  "ratelimit.environment.throttled",
+		},
+		{
+			name:             "tool and environment limits combined",
+			toolLimit:        RateLimit{PerMinute: 60, Burst: 5},
+			environmentLimit: RateLimit{PerMinute: 60, Burst: 1},
+			calls: []mcp.CallToolRequest{
+				newToolRequest("dockerProxy", map[string]any{"environmentId": float64(3)}),
+				newToolRequest("dockerProxy", map[string]any{"environmentId": float64(3)}),
+			},
+			expectedAllowed: []bool{true, false},
+			expectedScope:   RateLimitScopeEnvironment,
+			expectedKey:     "3",
+			expectedMetric:  "ratelimit.environment.throttled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			limiter := newRateLimiter(tt.toolLimit, tt.environmentLimit, registry)
+			now := time.Now()
+			limiter.now = func() time.Time { return now }
+
+			calls := 0
+			handler := limiter.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls++
+				return mcp.NewToolResultText("ok"), nil
+			})
+
+			var rejected *mcp.CallToolResult
+			for i, request := range tt.calls {
+				result, err := handler(context.Background(), request)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedAllowed[i], !result.IsError, "call %d", i)
+				if result.IsError {
+					rejected = result
+				}
+			}
+
+			require.NotNil(t, rejected)
+			textContent, ok := rejected.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var rateErr rateLimitError
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &rateErr))
+			assert.Equal(t, "rate_limited", rateErr.Error)
+			assert.Equal(t, tt.expectedScope, rateErr.Scope)
+			assert.Equal(t, tt.expectedKey, rateErr.Key)
+			assert.Greater(t, rateErr.RetryAfterSeconds, 0.0)
+
+			assert.Equal(t, int64(1), registry.Get(tt.expectedMetric))
+			assert.Equal(t, int64(tt.toolLimit.PerMinute), registry.Get("ratelimit.tool.per_minute"))
+			assert.Equal(t, int64(tt.environmentLimit.PerMinute), registry.Get("ratelimit.environment.per_minute"))
+		})
+	}
+}
@@ -13,12 +13,45 @@ const (
 	ToolAddEnvironmentToAccessGroup        = "addEnvironmentToAccessGroup"
 	ToolRemoveEnvironmentFromAccessGroup   = "removeEnvironmentFromAccessGroup"
 	ToolListEnvironments                   = "listEnvironments"
+	ToolGetEnvironment                     = "getEnvironment"
 	ToolUpdateEnvironment                  = "updateEnvironment"
+	ToolCreateEnvironment                  = "createEnvironment"
+	ToolDeleteEnvironment                  = "deleteEnvironment"
 	ToolGetStackFile                       = "getStackFile"
 	ToolGetStackEnvNames                   = "getStackEnvNames"
+	ToolListStackEnv                       = "listStackEnv"
+	ToolSetStackEnv                        = "setStackEnv"
+	ToolUnsetStackEnv                      = "unsetStackEnv"
+	ToolRenameStackEnv                     = "renameStackEnv"
 	ToolCreateStack                        = "createStack"
 	ToolListStacks                         = "listStacks"
+	ToolGetStack                           = "getStack"
+	ToolGetEdgeStackStatus                 = "getEdgeStackStatus"
+	ToolValidateStackFile                  = "validateStackFile"
+	ToolDiffStack                          = "diffStack"
+	ToolListStackRevisions                 = "listStackRevisions"
+	ToolRollbackStack                      = "rollbackStack"
 	ToolUpdateStack                        = "updateStack"
+	ToolCreateKubernetesStack              = "createKubernetesStack"
+	ToolUpdateKubernetesStack              = "updateKubernetesStack"
+	ToolCreateStackFromGit                 = "createStackFromGit"
+	ToolGetStackGitSettings                = "getStackGitSettings"
+	ToolUpdateStackAutoUpdate              = "updateStackAutoUpdate"
+	ToolRedeployStackFromGit               = "redeployStackFromGit"
+	ToolGetStackWebhook                    = "getStackWebhook"
+	ToolUpdateStackWebhook                 = "updateStackWebhook"
+	ToolTriggerStackWebhook                = "triggerStackWebhook"
+	ToolStartStack                         = "startStack"
+	ToolStopStack                          = "stopStack"
+	ToolRedeployStack                      = "redeployStack"
+	ToolDuplicateStack                     = "duplicateStack"
+	ToolMigrateStack                       = "migrateStack"
+	ToolListStackTemplates                 = "listStackTemplates"
+	ToolCreateStackTemplate                = "createStackTemplate"
+	ToolDeployFromTemplate                 = "deployFromTemplate"
+	ToolExportStacks                       = "exportStacks"
+	ToolImportStacks                       = "importStacks"
+	ToolDeleteStack                        = "deleteStack"
 	ToolCreateEnvironmentTag               = "createEnvironmentTag"
 	ToolListEnvironmentTags                = "listEnvironmentTags"
 	ToolCreateTeam                         = "createTeam"
@@ -38,8 +71,14 @@ const (
 	ToolUpdateEnvironmentGroupEnvironments = "updateEnvironmentGroupEnvironments"
 	ToolUpdateEnvironmentGroupTags         = "updateEnvironmentGroupTags"
 	ToolDockerProxy                        = "dockerProxy"
+	ToolListContainers                     = "listContainers"
+	ToolInspectContainer                   = "inspectContainer"
+	ToolGetContainerLogs                   = "getContainerLogs"
 	ToolKubernetesProxy                    = "kubernetesProxy"
 	ToolKubernetesProxyStripped            = "getKubernetesResourceStripped"
+	ToolGetServerMetrics                   = "getServerMetrics"
+	ToolListChanges                        = "listChanges"
+	ToolRevertChange                       = "revertChange"
 )
 
 // Access levels for users and teams
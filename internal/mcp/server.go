@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/journal"
+	"github.com/portainer/portainer-mcp/internal/metrics"
+	"github.com/portainer/portainer-mcp/internal/revisions"
+	"github.com/portainer/portainer-mcp/internal/templates"
 	"github.com/portainer/portainer-mcp/pkg/portainer/client"
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/portainer/portainer-mcp/pkg/toolgen"
@@ -14,7 +19,7 @@ import (
 
 const (
 	// MinimumToolsVersion is the minimum supported version of the tools.yaml file
-	MinimumToolsVersion = "1.0"
+	MinimumToolsVersion = "v1.3"
 	// SupportedPortainerVersion is the version of Portainer that is supported by this tool
 	SupportedPortainerVersion = "2.31.2"
 )
@@ -27,6 +32,11 @@ type PortainerClient interface {
 
 	// Environment methods
 	GetEnvironments() ([]models.Environment, error)
+	GetEnvironment(id int) (models.EnvironmentDetails, error)
+	CreateEnvironment(opts models.EnvironmentCreateOptions) (models.CreatedEnvironment, error)
+	UpdateEnvironment(id int, name *string, environmentURL *string, publicURL *string) error
+	DeleteEnvironment(id int) error
+	ListEnvironments(opts models.EnvironmentListOptions) (models.EnvironmentPage, error)
 	UpdateEnvironmentTags(id int, tagIds []int) error
 	UpdateEnvironmentUserAccesses(id int, userAccesses map[int]string) error
 	UpdateEnvironmentTeamAccesses(id int, teamAccesses map[int]string) error
@@ -49,10 +59,32 @@ type PortainerClient interface {
 
 	// Stack methods
 	GetStacks() ([]models.Stack, error)
-	GetStackFile(id int) (string, error)
+	GetStack(id int, kind string) (models.Stack, error)
+	GetEdgeStackStatus(id int) (models.EdgeStackStatus, error)
+	GetStackFile(id int, kind string) (string, error)
+	GetEdgeStackFile(id int) (string, error)
 	GetStackEnvNames(id int) ([]string, error)
-	CreateStack(name string, file string, environmentGroupIds []int) (int, error)
-	UpdateStack(id int, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error
+	GetStackEnv(id int) ([]models.StackEnvVar, error)
+	CreateStack(opts models.StackCreateOptions) (int, error)
+	UpdateStack(id int, kind string, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error
+	ReplaceStack(id int, file string, env []models.StackEnvVar) error
+	UpdateStackEnv(id int, change models.StackEnvChange) error
+	CreateKubernetesStack(opts models.KubernetesStackCreateOptions) (int, error)
+	UpdateKubernetesStack(id int, manifest string) error
+	GetCustomTemplates() ([]models.CustomTemplate, error)
+	GetCustomTemplateFile(id int) (string, error)
+	CreateCustomTemplate(opts models.CustomTemplateCreateOptions) (int, error)
+	CreateStackFromGit(opts models.StackGitCreateOptions) (int, error)
+	GetStackGitSettings(id int) (models.StackGitSettings, error)
+	UpdateStackAutoUpdate(id int, autoUpdate *models.StackAutoUpdate) error
+	RedeployStackFromGit(id int, opts models.StackGitRedeployOptions) error
+	GetStackWebhook(id int) (models.StackWebhook, error)
+	SetStackWebhook(id int, webhook string) error
+	TriggerStackWebhook(webhook string) error
+	StartStack(id int, kind string) error
+	StopStack(id int, kind string) error
+	RedeployStack(id int, kind string, opts models.StackRedeployOptions) error
+	DeleteStack(id int, kind string, removeVolumes bool) error
 
 	// Team methods
 	CreateTeam(name string) (int, error)
@@ -75,6 +107,9 @@ type PortainerClient interface {
 
 	// Kubernetes Proxy methods
 	ProxyKubernetesRequest(opts models.KubernetesProxyRequestOptions) (*http.Response, error)
+
+	// Cache methods
+	InvalidateCache(keys ...string)
 }
 
 // PortainerMCPServer is the main server that handles MCP protocol communication
@@ -84,6 +119,16 @@ type PortainerMCPServer struct {
 	cli      PortainerClient
 	tools    map[string]mcp.Tool
 	readOnly bool
+	// allowDestructive enables the tools that delete resources
+	allowDestructive bool
+	pager            *responsePager
+	metrics          *metrics.Registry
+	journal          *journal.Journal
+	revisions        *revisions.Store
+	templates        *templates.Store
+	// bundleDir is the directory in which the stack bundles are written and read
+	bundleDir        string
+	bundlePassphrase string
 }
 
 // ServerOption is a function that configures the server
@@ -91,9 +136,20 @@ type ServerOption func(*serverOptions)
 
 // serverOptions contains all configurable options for the server
 type serverOptions struct {
-	client              PortainerClient
-	readOnly            bool
-	disableVersionCheck bool
+	client                PortainerClient
+	readOnly              bool
+	disableVersionCheck   bool
+	maxResponseSize       int
+	toolRateLimit         RateLimit
+	environmentRateLimit  RateLimit
+	maxConcurrentRequests int
+	journal               *journal.Journal
+	revisions             *revisions.Store
+	templates             *templates.Store
+	bundleDir             string
+	bundlePassphrase      string
+	cacheTTL              time.Duration
+	allowDestructive      bool
 }
 
 // WithClient sets a custom client for the server.
@@ -112,6 +168,14 @@ func WithReadOnly(readOnly bool) ServerOption {
 	}
 }
 
+// WithDestructiveTools enables the tools that delete resources, such as deleteEnvironment.
+// They are not registered by default, and never in read-only mode.
+func WithDestructiveTools(allow bool) ServerOption {
+	return func(opts *serverOptions) {
+		opts.allowDestructive = allow
+	}
+}
+
 // WithDisableVersionCheck disables the Portainer server version check.
 // This allows connecting to unsupported Portainer versions.
 func WithDisableVersionCheck(disable bool) ServerOption {
@@ -120,6 +184,82 @@ func WithDisableVersionCheck(disable bool) ServerOption {
 	}
 }
 
+// WithMaxResponseSize sets the maximum size in bytes of a tool response.
+// Larger responses are truncated and can be fetched in chunks using the returned cursor.
+// A value of 0 or less disables the limit.
+func WithMaxResponseSize(size int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxResponseSize = size
+	}
+}
+
+// WithToolRateLimit sets the rate limit applied to each tool individually.
+// Calls exceeding the limit are rejected with a structured retry after error.
+func WithToolRateLimit(limit RateLimit) ServerOption {
+	return func(opts *serverOptions) {
+		opts.toolRateLimit = limit
+	}
+}
+
+// WithEnvironmentRateLimit sets the rate limit applied to the calls targeting each environment,
+// across all tools accepting an environmentId parameter.
+func WithEnvironmentRateLimit(limit RateLimit) ServerOption {
+	return func(opts *serverOptions) {
+		opts.environmentRateLimit = limit
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of concurrent requests sent to the Portainer server.
+// It only applies to the default client and is ignored when a custom client is set with WithClient.
+func WithMaxConcurrentRequests(max int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxConcurrentRequests = max
+	}
+}
+
+// WithJournal sets the journal in which the update tools record the state of the resources they change.
+// Recorded changes can be listed and reverted with the listChanges and revertChange tools.
+func WithJournal(j *journal.Journal) ServerOption {
+	return func(opts *serverOptions) {
+		opts.journal = j
+	}
+}
+
+// WithStackRevisions sets the store in which the stack tools keep the revisions of the stacks they write or observe.
+// Revisions can be listed and re-applied with the listStackRevisions and rollbackStack tools.
+func WithStackRevisions(store *revisions.Store) ServerOption {
+	return func(opts *serverOptions) {
+		opts.revisions = store
+	}
+}
+
+// WithStackTemplates sets the store of the local stack templates.
+// Without it, only the templates stored as Portainer custom templates are available.
+func WithStackTemplates(store *templates.Store) ServerOption {
+	return func(opts *serverOptions) {
+		opts.templates = store
+	}
+}
+
+// WithStackBundles sets the directory in which exportStacks writes and importStacks reads the stack bundles,
+// and the passphrase that encrypts their environment variable values. Bundles cannot be written or read
+// outside of this directory, and the tools are disabled without it.
+func WithStackBundles(dir, passphrase string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.bundleDir = dir
+		opts.bundlePassphrase = passphrase
+	}
+}
+
+// WithCacheTTL enables the caching of the environment, tag, team and user lists for the given duration.
+// The list tools accept a refresh parameter to bypass the cache.
+// It only applies to the default client and is ignored when a custom client is set with WithClient.
+func WithCacheTTL(ttl time.Duration) ServerOption {
+	return func(opts *serverOptions) {
+		opts.cacheTTL = ttl
+	}
+}
+
 // NewPortainerMCPServer creates a new Portainer MCP server.
 //
 // This server provides an implementation of the MCP protocol for Portainer,
@@ -151,11 +291,18 @@ func NewPortainerMCPServer(serverURL, token, toolsPath string, options ...Server
 		return nil, fmt.Errorf("failed to load tools: %w", err)
 	}
 
+	registry := metrics.NewRegistry()
+
 	var portainerClient PortainerClient
 	if opts.client != nil {
 		portainerClient = opts.client
 	} else {
-		portainerClient = client.NewPortainerClient(serverURL, token, client.WithSkipTLSVerify(true))
+		portainerClient = client.NewPortainerClient(serverURL, token,
+			client.WithSkipTLSVerify(true),
+			client.WithMaxConcurrentRequests(opts.maxConcurrentRequests),
+			client.WithMetrics(registry),
+			client.WithCacheTTL(opts.cacheTTL),
+		)
 	}
 
 	if !opts.disableVersionCheck {
@@ -169,16 +316,43 @@ func NewPortainerMCPServer(serverURL, token, toolsPath string, options ...Server
 		}
 	}
 
+	serverOpts := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
+	}
+
+	// Middlewares registered first wrap the ones registered after them: the rate limiter comes
+	// first so that the calls served by the pager from a cursor are rate limited too
+	if opts.toolRateLimit.enabled() || opts.environmentRateLimit.enabled() {
+		limiter := newRateLimiter(opts.toolRateLimit, opts.environmentRateLimit, registry)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(limiter.middleware))
+	}
+
+	var pager *responsePager
+	if opts.maxResponseSize > 0 {
+		pager = newResponsePager(opts.maxResponseSize)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(pager.middleware))
+	} else {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(rejectCursorMiddleware))
+	}
+
 	return &PortainerMCPServer{
 		srv: server.NewMCPServer(
 			"Portainer MCP Server",
-			"0.5.1",
-			server.WithToolCapabilities(true),
-			server.WithLogging(),
+			"0.7.0",
+			serverOpts...,
 		),
-		cli:      portainerClient,
-		tools:    tools,
-		readOnly: opts.readOnly,
+		cli:              portainerClient,
+		tools:            tools,
+		readOnly:         opts.readOnly,
+		allowDestructive: opts.allowDestructive,
+		pager:            pager,
+		metrics:          registry,
+		journal:          opts.journal,
+		revisions:        opts.revisions,
+		templates:        opts.templates,
+		bundleDir:        opts.bundleDir,
+		bundlePassphrase: opts.bundlePassphrase,
 	}, nil
 }
 
@@ -196,3 +370,12 @@ func (s *PortainerMCPServer) addToolIfExists(toolName string, handler server.Too
 		log.Printf("Tool %s not found, will not be registered for MCP usage", toolName)
 	}
 }
+
+// addDestructiveToolIfAllowed adds a tool that deletes resources to the server,
+// only if destructive tools are enabled and the server is not in read-only mode
+func (s *PortainerMCPServer) addDestructiveToolIfAllowed(toolName string, handler server.ToolHandlerFunc) {
+	if s.readOnly || !s.allowDestructive {
+		return
+	}
+	s.addToolIfExists(toolName, handler)
+}
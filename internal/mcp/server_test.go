@@ -2,7 +2,9 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,6 +17,7 @@ func TestNewPortainerMCPServer(t *testing.T) {
 	// Define paths to test data files
 	validToolsPath := "testdata/valid_tools.yaml"
 	invalidToolsPath := "testdata/invalid_tools.yaml"
+	outdatedToolsPath := "testdata/outdated_tools.yaml"
 
 	tests := []struct {
 		name          string
@@ -53,6 +56,15 @@ func TestNewPortainerMCPServer(t *testing.T) {
 			expectError:   true,
 			errorContains: "invalid version in tools.yaml",
 		},
+		{
+			name:          "outdated tools version",
+			serverURL:     "https://portainer.example.com",
+			token:         "valid-token",
+			toolsPath:     outdatedToolsPath,
+			mockSetup:     func(m *MockPortainerClient) {},
+			expectError:   true,
+			errorContains: "is below the minimum required version",
+		},
 		{
 			name:      "API communication error",
 			serverURL: "https://portainer.example.com",
@@ -194,3 +206,72 @@ func TestAddToolIfExists(t *testing.T) {
 		})
 	}
 }
+
+func TestAddDestructiveToolIfAllowed(t *testing.T) {
+	tests := []struct {
+		name             string
+		readOnly         bool
+		allowDestructive bool
+		registered       bool
+	}{
+		{name: "destructive tools disabled", registered: false},
+		{name: "destructive tools enabled", allowDestructive: true, registered: true},
+		{name: "read-only mode", readOnly: true, allowDestructive: true, registered: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("Test Server", "1.0.0", server.WithToolCapabilities(true))
+			s := &PortainerMCPServer{
+				tools: map[string]mcp.Tool{
+					ToolDeleteEnvironment: mcp.NewTool(ToolDeleteEnvironment),
+				},
+				srv:              mcpServer,
+				readOnly:         tt.readOnly,
+				allowDestructive: tt.allowDestructive,
+			}
+
+			s.addDestructiveToolIfAllowed(ToolDeleteEnvironment, s.HandleDeleteEnvironment())
+
+			response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.registered, strings.Contains(string(data), ToolDeleteEnvironment))
+		})
+	}
+}
+
+func TestRateLimitAppliesToCursorCalls(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}),
+		WithDisableVersionCheck(true),
+		WithMaxResponseSize(10),
+		WithToolRateLimit(RateLimit{PerMinute: 1, Burst: 1}),
+	)
+	require.NoError(t, err)
+	s.srv.AddTool(mcp.NewTool("listThings"), newTextHandler(strings.Repeat("x", 30)))
+
+	call := func(args map[string]any) string {
+		params, err := json.Marshal(map[string]any{"name": "listThings", "arguments": args})
+		require.NoError(t, err)
+		response := s.srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`))
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	first := call(nil)
+	require.Contains(t, first, "nextCursor")
+
+	var response struct {
+		Result struct {
+			Content []mcp.TextContent `json:"content"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(first), &response))
+	require.Len(t, response.Result.Content, 2)
+	var notice truncationNotice
+	require.NoError(t, json.Unmarshal([]byte(response.Result.Content[1].Text), &notice))
+
+	assert.Contains(t, call(map[string]any{cursorParam: notice.NextCursor}), "rate_limited")
+}
@@ -16,7 +16,7 @@ func (s *PortainerMCPServer) HandleGetSettings() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		settings, err := s.cli.GetSettings()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get settings", err), nil
+			return newToolResultAPIError("failed to get settings", err), nil
 		}
 
 		data, err := json.Marshal(settings)
@@ -13,20 +13,51 @@ import (
 
 func (s *PortainerMCPServer) AddStackFeatures() {
 	s.addToolIfExists(ToolListStacks, s.HandleGetStacks())
+	s.addToolIfExists(ToolGetStack, s.HandleGetStack())
+	s.addToolIfExists(ToolGetEdgeStackStatus, s.HandleGetEdgeStackStatus())
 	s.addToolIfExists(ToolGetStackFile, s.HandleGetStackFile())
 	s.addToolIfExists(ToolGetStackEnvNames, s.HandleGetStackEnvNames())
+	s.addToolIfExists(ToolListStackEnv, s.HandleListStackEnv())
+	s.addToolIfExists(ToolGetStackGitSettings, s.HandleGetStackGitSettings())
+	s.addToolIfExists(ToolGetStackWebhook, s.HandleGetStackWebhook())
+	s.addToolIfExists(ToolValidateStackFile, s.HandleValidateStackFile())
+	s.addToolIfExists(ToolDiffStack, s.HandleDiffStack())
+	s.addToolIfExists(ToolListStackRevisions, s.HandleListStackRevisions())
+	s.addToolIfExists(ToolListStackTemplates, s.HandleListStackTemplates())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateStack, s.HandleCreateStack())
 		s.addToolIfExists(ToolUpdateStack, s.HandleUpdateStack())
+		s.addToolIfExists(ToolCreateKubernetesStack, s.HandleCreateKubernetesStack())
+		s.addToolIfExists(ToolUpdateKubernetesStack, s.HandleUpdateKubernetesStack())
+		s.addToolIfExists(ToolSetStackEnv, s.HandleSetStackEnv())
+		s.addToolIfExists(ToolUnsetStackEnv, s.HandleUnsetStackEnv())
+		s.addToolIfExists(ToolRenameStackEnv, s.HandleRenameStackEnv())
+		s.addToolIfExists(ToolCreateStackFromGit, s.HandleCreateStackFromGit())
+		s.addToolIfExists(ToolUpdateStackAutoUpdate, s.HandleUpdateStackAutoUpdate())
+		s.addToolIfExists(ToolRedeployStackFromGit, s.HandleRedeployStackFromGit())
+		s.addToolIfExists(ToolUpdateStackWebhook, s.HandleUpdateStackWebhook())
+		s.addToolIfExists(ToolTriggerStackWebhook, s.HandleTriggerStackWebhook())
+		s.addToolIfExists(ToolStartStack, s.HandleStartStack())
+		s.addToolIfExists(ToolStopStack, s.HandleStopStack())
+		s.addToolIfExists(ToolRedeployStack, s.HandleRedeployStack())
+		s.addToolIfExists(ToolRollbackStack, s.HandleRollbackStack())
+		s.addToolIfExists(ToolDuplicateStack, s.HandleDuplicateStack())
+		s.addToolIfExists(ToolCreateStackTemplate, s.HandleCreateStackTemplate())
+		s.addToolIfExists(ToolDeployFromTemplate, s.HandleDeployFromTemplate())
+		s.addToolIfExists(ToolExportStacks, s.HandleExportStacks())
+		s.addToolIfExists(ToolImportStacks, s.HandleImportStacks())
 	}
+
+	s.addDestructiveToolIfAllowed(ToolDeleteStack, s.HandleDeleteStack())
+	s.addDestructiveToolIfAllowed(ToolMigrateStack, s.HandleMigrateStack())
 }
 
 func (s *PortainerMCPServer) HandleGetStacks() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		stacks, err := s.cli.GetStacks()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get stacks", err), nil
+			return newToolResultAPIError("failed to get stacks", err), nil
 		}
 
 		data, err := json.Marshal(stacks)
@@ -38,6 +69,57 @@ func (s *PortainerMCPServer) HandleGetStacks() server.ToolHandlerFunc {
 	}
 }
 
+func (s *PortainerMCPServer) HandleGetStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		stack, err := s.cli.GetStack(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+
+		data, err := json.Marshal(stack)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleGetEdgeStackStatus() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		status, err := s.cli.GetEdgeStackStatus(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get edge stack status", err), nil
+		}
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal edge stack status", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
 func (s *PortainerMCPServer) HandleGetStackFile() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
@@ -47,11 +129,24 @@ func (s *PortainerMCPServer) HandleGetStackFile() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
 		}
 
-		stackFile, err := s.cli.GetStackFile(id)
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		kind, err = s.resolveStackKind(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+
+		stackFile, err := s.cli.GetStackFile(id, kind)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get stack file", err), nil
+			return newToolResultAPIError("failed to get stack file", err), nil
 		}
 
+		// Failing to record the revision must not prevent reading the file
+		_ = s.observeStackFile(id, kind, stackFile)
+
 		return mcp.NewToolResultText(stackFile), nil
 	}
 }
@@ -67,7 +162,7 @@ func (s *PortainerMCPServer) HandleGetStackEnvNames() server.ToolHandlerFunc {
 
 		names, err := s.cli.GetStackEnvNames(id)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get stack env names", err), nil
+			return newToolResultAPIError("failed to get stack env names", err), nil
 		}
 
 		data, err := json.Marshal(names)
@@ -83,27 +178,73 @@ func (s *PortainerMCPServer) HandleCreateStack() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
 
-		name, err := parser.GetString("name", true)
-		if err != nil {
+		var opts models.StackCreateOptions
+		var err error
+
+		if opts.Name, err = parser.GetString("name", true); err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
 		}
+		if opts.File, err = parser.GetString("file", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+		}
+		if opts.EnvironmentID, err = parser.GetInt("environmentId", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if opts.Type, err = parser.GetString("type", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid type parameter", err), nil
+		}
+		if opts.EnvironmentGroupIds, err = parser.GetArrayOfIntegers("environmentGroupIds", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentGroupIds parameter", err), nil
+		}
 
-		file, err := parser.GetString("file", true)
+		envRaw, err := parser.GetArrayOfObjects("env", false)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+			return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+		}
+		if len(envRaw) > 0 {
+			if opts.Env, err = parseStackEnvOverrides(envRaw); err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+			}
 		}
 
-		environmentGroupIds, err := parser.GetArrayOfIntegers("environmentGroupIds", true)
+		hasGroups := len(opts.EnvironmentGroupIds) > 0
+		if hasGroups == (opts.EnvironmentID != 0) {
+			return mcp.NewToolResultError("exactly one of environmentId or environmentGroupIds must be provided"), nil
+		}
+		if hasGroups && opts.Type != "" {
+			return mcp.NewToolResultError("the type parameter only applies to stacks created on an environment"), nil
+		}
+		if hasGroups && len(opts.Env) > 0 {
+			return mcp.NewToolResultError("the env parameter is not supported for edge stacks"), nil
+		}
+		if opts.Type != "" && opts.Type != models.StackTypeStandalone && opts.Type != models.StackTypeSwarm {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid type parameter: %s", opts.Type)), nil
+		}
+
+		skipValidation, err := parser.GetBoolean("skipValidation", false)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid environmentGroupIds parameter", err), nil
+			return mcp.NewToolResultErrorFromErr("invalid skipValidation parameter", err), nil
+		}
+
+		var warnings string
+		if !skipValidation {
+			var failed *mcp.CallToolResult
+			if failed, warnings = checkStackFile(opts.File, envVarNames(opts.Env)); failed != nil {
+				return failed, nil
+			}
 		}
 
-		id, err := s.cli.CreateStack(name, file, environmentGroupIds)
+		id, err := s.cli.CreateStack(opts)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("error creating stack", err), nil
+			return newToolResultAPIError("error creating stack", err), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Stack created successfully with ID: %d", id)), nil
+		kind := models.StackKindRegular
+		if hasGroups {
+			kind = models.StackKindEdge
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack created successfully with ID: %d", id) + s.recordStackRevision(ToolCreateStack, id, kind) + warnings), nil
 	}
 }
 
@@ -136,13 +277,177 @@ func (s *PortainerMCPServer) HandleUpdateStack() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid envOverrides parameter", err), nil
 		}
 
-		err = s.cli.UpdateStack(id, file, environmentGroupIds, envOverrides)
+		skipValidation, err := parser.GetBoolean("skipValidation", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid skipValidation parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		kind, err = s.resolveStackKind(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+
+		var warnings string
+		if !skipValidation {
+			var failed *mcp.CallToolResult
+			if failed, warnings = checkStackFile(file, s.stackVariableNames(id, kind, envOverrides)); failed != nil {
+				return failed, nil
+			}
+		}
+
+		observed := s.observeStackWarning(id, kind)
+
+		change := s.captureChange(stackChangeKind(kind), id)
+
+		err = s.cli.UpdateStack(id, kind, file, environmentGroupIds, envOverrides)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update stack", err), nil
+			return newToolResultAPIError("failed to update stack", err), nil
 		}
 
-		return mcp.NewToolResultText("Stack updated successfully"), nil
+		return mcp.NewToolResultText("Stack updated successfully" + observed + s.recordChange(ToolUpdateStack, change) + s.recordStackRevision(ToolUpdateStack, id, kind) + warnings), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleStartStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		if err := s.cli.StartStack(id, kind); err != nil {
+			return newToolResultAPIError("failed to start stack", err), nil
+		}
+
+		return mcp.NewToolResultText("Stack started successfully"), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleStopStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		if err := s.cli.StopStack(id, kind); err != nil {
+			return newToolResultAPIError("failed to stop stack", err), nil
+		}
+
+		return mcp.NewToolResultText("Stack stopped successfully"), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleRedeployStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		var opts models.StackRedeployOptions
+		if opts.PullImage, err = parser.GetBoolean("pullImage", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid pullImage parameter", err), nil
+		}
+		if opts.Prune, err = parser.GetBoolean("prune", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid prune parameter", err), nil
+		}
+
+		if err := s.cli.RedeployStack(id, kind, opts); err != nil {
+			return newToolResultAPIError("failed to redeploy stack", err), nil
+		}
+
+		return mcp.NewToolResultText("Stack redeployed successfully"), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleDeleteStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		removeVolumes, err := parser.GetBoolean("removeVolumes", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid removeVolumes parameter", err), nil
+		}
+
+		if err := s.cli.DeleteStack(id, kind, removeVolumes); err != nil {
+			return newToolResultAPIError("failed to delete stack", err), nil
+		}
+
+		return mcp.NewToolResultText("Stack deleted successfully"), nil
+	}
+}
+
+// parseStackKind reads the optional kind parameter, which selects a regular or an edge stack when both share the
+// requested ID. Without it, the client rejects an ID matching both kinds of stacks.
+func parseStackKind(parser *toolgen.ParameterParser) (string, error) {
+	kind, err := parser.GetString("kind", false)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case "", models.StackKindRegular, models.StackKindEdge:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("kind must be %s or %s, got %s", models.StackKindRegular, models.StackKindEdge, kind)
+	}
+}
+
+// resolveStackKind returns the kind of a stack, models.StackKindRegular or models.StackKindEdge, so that the
+// following calls act on that stack only. kind is the kind given to the tool, if it is empty an ID matching
+// both kinds of stacks is rejected.
+func (s *PortainerMCPServer) resolveStackKind(id int, kind string) (string, error) {
+	stack, err := s.cli.GetStack(id, kind)
+	if err != nil {
+		return "", err
+	}
+	return regularOrEdge(stack.Kind), nil
+}
+
+// regularOrEdge returns the kind under which a stack of the given detailed kind (e.g. models.StackKindCompose)
+// is looked up: models.StackKindEdge for edge stacks and models.StackKindRegular for the others
+func regularOrEdge(kind string) string {
+	if kind == models.StackKindEdge {
+		return models.StackKindEdge
 	}
+	return models.StackKindRegular
 }
 
 func parseStackEnvOverrides(entries []any) ([]models.StackEnvVar, error) {
@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/stackbundle"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// exportedBundle is the result of the exportStacks tool
+type exportedBundle struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// EnvValuesIncluded is true if the bundle contains the encrypted values of the environment variables
+	EnvValuesIncluded bool                     `json:"env_values_included"`
+	Stacks            []stackbundle.StackEntry `json:"stacks"`
+}
+
+// importedBundle is the result of the importStacks tool
+type importedBundle struct {
+	DryRun bool                       `json:"dry_run"`
+	Stacks []stackbundle.PlannedStack `json:"stacks"`
+}
+
+func (s *PortainerMCPServer) HandleExportStacks() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.bundleDir == "" {
+			return mcp.NewToolResultError("stack bundles are not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		format, err := parser.GetString("format", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid format parameter", err), nil
+		}
+		if format == "" {
+			format = stackbundle.FormatFromPath(name)
+		}
+		if format != stackbundle.FormatTar && format != stackbundle.FormatZip {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid format parameter: %s", format)), nil
+		}
+
+		stackIDs, err := parser.GetArrayOfIntegers("stackIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackIds parameter", err), nil
+		}
+
+		edgeStackIDs, err := parser.GetArrayOfIntegers("edgeStackIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid edgeStackIds parameter", err), nil
+		}
+
+		includeEnvValues, err := parser.GetBoolean("includeEnvValues", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid includeEnvValues parameter", err), nil
+		}
+		if includeEnvValues && s.bundlePassphrase == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("the %s environment variable of the MCP server must be set to include environment variable values", stackbundle.PassphraseEnv)), nil
+		}
+
+		overwrite, err := parser.GetBoolean("overwrite", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid overwrite parameter", err), nil
+		}
+
+		stacks, err := stackbundle.Export(s.cli, stackbundle.ExportOptions{StackIDs: stackIDs, EdgeStackIDs: edgeStackIDs, IncludeEnvValues: includeEnvValues})
+		if err != nil {
+			return newToolResultAPIError("failed to export stacks", err), nil
+		}
+
+		var buf bytes.Buffer
+		if err := stackbundle.Write(&buf, format, stacks, s.bundlePassphrase); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to write stack bundle", err), nil
+		}
+
+		path, err := s.writeBundleFile(name, buf.Bytes(), overwrite)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to write stack bundle", err), nil
+		}
+
+		result := exportedBundle{
+			Path:              path,
+			Format:            format,
+			EnvValuesIncluded: includeEnvValues,
+			Stacks:            make([]stackbundle.StackEntry, 0, len(stacks)),
+		}
+		for _, stack := range stacks {
+			result.Stacks = append(result.Stacks, stack.StackEntry)
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal export result", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleImportStacks() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.bundleDir == "" {
+			return mcp.NewToolResultError("stack bundles are not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		environmentMapRaw, err := parser.GetArrayOfObjects("environmentMap", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentMap parameter", err), nil
+		}
+
+		environmentMap, err := parseEnvironmentMap(environmentMapRaw)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentMap parameter", err), nil
+		}
+
+		apply, err := parser.GetBoolean("apply", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid apply parameter", err), nil
+		}
+
+		allowMissingValues, err := parser.GetBoolean("allowMissingValues", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid allowMissingValues parameter", err), nil
+		}
+
+		data, err := s.readBundleFile(name)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read stack bundle", err), nil
+		}
+
+		manifest, stacks, err := stackbundle.Read(data, s.bundlePassphrase)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read stack bundle", err), nil
+		}
+		if manifest.Encryption != nil && s.bundlePassphrase == "" && !allowMissingValues {
+			return mcp.NewToolResultError(fmt.Sprintf("the bundle contains encrypted environment variable values: set the %s environment variable of the MCP server to decrypt them, or set allowMissingValues to create the variables with empty values", stackbundle.PassphraseEnv)), nil
+		}
+
+		plan, err := stackbundle.Plan(s.cli, stacks, stackbundle.ImportOptions{EnvironmentMap: environmentMap})
+		if err != nil {
+			return newToolResultAPIError("failed to plan stack import", err), nil
+		}
+
+		if apply {
+			plan = stackbundle.Apply(s.cli, plan)
+			for _, planned := range plan {
+				if planned.StackID != 0 {
+					// The revision is saved on a best effort basis, the result already reports the created stacks
+					_ = s.recordStackRevision(ToolImportStacks, planned.StackID, regularOrEdge(planned.Kind))
+				}
+			}
+		}
+
+		result, err := json.Marshal(importedBundle{DryRun: !apply, Stacks: plan})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal import plan", err), nil
+		}
+
+		return mcp.NewToolResultText(string(result)), nil
+	}
+}
+
+// writeBundleFile writes a bundle to a file of the bundle directory and returns its path.
+// The name is resolved within the directory, so that files outside of it cannot be written even through
+// symbolic links, and an existing bundle is only replaced if overwrite is set.
+func (s *PortainerMCPServer) writeBundleFile(name string, data []byte, overwrite bool) (string, error) {
+	root, err := os.OpenRoot(s.bundleDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the bundle directory: %w", err)
+	}
+	defer root.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+
+	file, err := root.OpenFile(name, flags, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("the bundle %s already exists, set overwrite to replace it", name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.bundleDir, name), nil
+}
+
+// readBundleFile reads a bundle from a file of the bundle directory, the name is resolved within the directory
+func (s *PortainerMCPServer) readBundleFile(name string) ([]byte, error) {
+	root, err := os.OpenRoot(s.bundleDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bundle directory: %w", err)
+	}
+	defer root.Close()
+
+	file, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// parseEnvironmentMap parses the mapping of the environment names of a bundle to environment IDs
+func parseEnvironmentMap(entries []any) (map[string]int, error) {
+	result := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid environment mapping: %v", entry)
+		}
+
+		name, ok := entryMap["name"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid environment name: %v", entryMap["name"])
+		}
+
+		id, ok := entryMap["environmentId"].(float64)
+		if !ok || id <= 0 {
+			return nil, fmt.Errorf("invalid environment ID: %v", entryMap["environmentId"])
+		}
+
+		result[name] = int(id)
+	}
+
+	return result, nil
+}
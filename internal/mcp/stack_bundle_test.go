@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/internal/stackbundle"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleExportAndImportStacks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stacks.zip")
+
+	source := &MockPortainerClient{}
+	source.On("GetStacks").Return([]models.Stack{
+		{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 3},
+		{ID: 1, Name: "agent", Kind: models.StackKindEdge, EnvironmentGroupIds: []int{4}},
+	}, nil)
+	source.On("GetEnvironments").Return([]models.Environment{{ID: 3, Name: "staging"}}, nil)
+	source.On("GetEnvironmentGroups").Return([]models.Group{{ID: 4, Name: "stores"}}, nil)
+	source.On("GetStackFile", 1, models.StackKindRegular).Return("services: {}", nil)
+	source.On("GetStackEnv", 1).Return([]models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}, nil)
+	source.On("GetEdgeStackFile", 1).Return("services:\n  agent: {}", nil)
+
+	exporter := &PortainerMCPServer{cli: source, bundleDir: dir, bundlePassphrase: "correct horse"}
+
+	result, err := exporter.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name":             "stacks.zip",
+		"includeEnvValues": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var exported exportedBundle
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &exported))
+	assert.Equal(t, path, exported.Path)
+	assert.Equal(t, stackbundle.FormatZip, exported.Format)
+	assert.True(t, exported.EnvValuesIncluded)
+	require.Len(t, exported.Stacks, 2)
+	assert.Equal(t, models.StackKindEdge, exported.Stacks[1].Kind)
+	assert.NotContains(t, resultText(t, result), "secret")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	target := &MockPortainerClient{}
+	target.On("GetStacks").Return([]models.Stack{{ID: 30, Name: "agent", Kind: models.StackKindEdge}}, nil)
+	target.On("GetEnvironments").Return([]models.Environment{{ID: 8, Name: "production"}}, nil)
+	target.On("GetEnvironmentGroups").Return([]models.Group{{ID: 9, Name: "stores"}}, nil)
+	target.On("CreateStack", models.StackCreateOptions{
+		Name:          "web",
+		File:          "services: {}",
+		EnvironmentID: 8,
+		Type:          models.StackTypeStandalone,
+		Env:           []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+	}).Return(12, nil)
+
+	importer := &PortainerMCPServer{cli: target, bundleDir: dir, bundlePassphrase: "correct horse"}
+	params := map[string]any{
+		"name":           "stacks.zip",
+		"environmentMap": []any{map[string]any{"name": "staging", "environmentId": float64(8)}},
+	}
+
+	result, err = importer.HandleImportStacks()(context.Background(), CreateMCPRequest(params))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var plan importedBundle
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &plan))
+	assert.True(t, plan.DryRun)
+	require.Len(t, plan.Stacks, 2)
+	assert.Equal(t, stackbundle.ActionCreate, plan.Stacks[0].Action)
+	assert.Equal(t, 8, plan.Stacks[0].EnvironmentID)
+	assert.Equal(t, stackbundle.ActionSkip, plan.Stacks[1].Action, "the edge stack already exists on the target server")
+	target.AssertNotCalled(t, "CreateStack", mock.Anything)
+
+	params["apply"] = true
+	result, err = importer.HandleImportStacks()(context.Background(), CreateMCPRequest(params))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &plan))
+	assert.False(t, plan.DryRun)
+	assert.Equal(t, 12, plan.Stacks[0].StackID)
+	target.AssertExpectations(t)
+}
+
+func TestHandleExportStacksRequiresPassphrase(t *testing.T) {
+	server := &PortainerMCPServer{cli: &MockPortainerClient{}, bundleDir: t.TempDir()}
+
+	result, err := server.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name":             "stacks.tar.gz",
+		"includeEnvValues": true,
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "PORTAINER_MCP_BUNDLE_PASSPHRASE environment variable")
+}
+
+func TestHandleExportStacksBundleDir(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.tar.gz"), []byte("previous bundle"), 0600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+
+	source := &MockPortainerClient{}
+	source.On("GetStacks").Return([]models.Stack{}, nil)
+	source.On("GetEnvironments").Return([]models.Environment{}, nil)
+	source.On("GetEnvironmentGroups").Return([]models.Group{}, nil)
+
+	server := &PortainerMCPServer{cli: source, bundleDir: dir}
+
+	for _, name := range []string{"../stacks.tar.gz", filepath.Join(outside, "stacks.tar.gz"), "link/stacks.tar.gz"} {
+		result, err := server.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+			"name": name,
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%s must be refused", name)
+	}
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no bundle must be written outside of the bundle directory")
+
+	result, err := server.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "existing.tar.gz",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "set overwrite to replace it")
+
+	data, err := os.ReadFile(filepath.Join(dir, "existing.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "previous bundle", string(data))
+
+	result, err = server.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name":      "existing.tar.gz",
+		"overwrite": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	data, err = os.ReadFile(filepath.Join(dir, "existing.tar.gz"))
+	require.NoError(t, err)
+	assert.NotEqual(t, "previous bundle", string(data))
+}
+
+func TestHandleStackBundlesDisabled(t *testing.T) {
+	server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+
+	result, err := server.HandleExportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "stacks.tar.gz",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "stack bundles are not enabled")
+
+	result, err = server.HandleImportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "/etc/passwd",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "stack bundles are not enabled")
+}
+
+func TestHandleImportStacksMissingPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, "stacks.tar.gz"))
+	require.NoError(t, err)
+	require.NoError(t, stackbundle.Write(file, stackbundle.FormatTar, []stackbundle.Stack{{
+		StackEntry: stackbundle.StackEntry{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentName: "staging", EnvNames: []string{"TOKEN"}},
+		Env:        []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+	}}, "correct horse"))
+	require.NoError(t, file.Close())
+
+	target := &MockPortainerClient{}
+	server := &PortainerMCPServer{cli: target, bundleDir: dir}
+
+	result, err := server.HandleImportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "stacks.tar.gz",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "set allowMissingValues")
+	target.AssertNotCalled(t, "GetStacks")
+
+	target.On("GetStacks").Return([]models.Stack{}, nil)
+	target.On("GetEnvironments").Return([]models.Environment{{ID: 8, Name: "staging"}}, nil)
+	target.On("GetEnvironmentGroups").Return([]models.Group{}, nil)
+
+	result, err = server.HandleImportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name":               "stacks.tar.gz",
+		"allowMissingValues": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var plan importedBundle
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &plan))
+	require.Len(t, plan.Stacks, 1)
+	assert.Equal(t, stackbundle.ActionCreate, plan.Stacks[0].Action)
+	assert.Equal(t, []string{"TOKEN"}, plan.Stacks[0].EnvWithoutValues)
+}
+
+func TestHandleImportStacksWrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, "stacks.tar.gz"))
+	require.NoError(t, err)
+	require.NoError(t, stackbundle.Write(file, stackbundle.FormatTar, []stackbundle.Stack{{
+		StackEntry: stackbundle.StackEntry{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvNames: []string{"TOKEN"}},
+		Env:        []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+	}}, "correct horse"))
+	require.NoError(t, file.Close())
+
+	server := &PortainerMCPServer{cli: &MockPortainerClient{}, bundleDir: dir, bundlePassphrase: "wrong"}
+
+	result, err := server.HandleImportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "stacks.tar.gz",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "wrong passphrase")
+
+	result, err = server.HandleImportStacks()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "../stacks.tar.gz",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "bundles outside of the bundle directory must not be read")
+}
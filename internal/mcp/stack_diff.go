@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/compose"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// stackDiff is the result of the diffStack tool
+type stackDiff struct {
+	StackID int  `json:"stack_id"`
+	Changed bool `json:"changed"`
+	compose.FileDiff
+	// Env lists the names of the stack environment variables that change, values are never included.
+	// It is not set for edge stacks, which do not have environment variables.
+	Env *compose.NameChange `json:"env,omitempty"`
+}
+
+func (s *PortainerMCPServer) HandleDiffStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		file, err := parser.GetString("file", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+		}
+
+		envOverridesRaw, err := parser.GetArrayOfObjects("envOverrides", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid envOverrides parameter", err), nil
+		}
+
+		envOverrides, err := parseStackEnvOverrides(envOverridesRaw)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid envOverrides parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		kind, err = s.resolveStackKind(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+
+		liveFile, err := s.cli.GetStackFile(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack file", err), nil
+		}
+
+		fileDiff, err := compose.Diff(liveFile, file)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to compare the stack files", err), nil
+		}
+
+		result := stackDiff{
+			StackID:  id,
+			Changed:  fileDiff.Unified != "",
+			FileDiff: fileDiff,
+		}
+
+		if kind == models.StackKindEdge {
+			if len(envOverrides) > 0 {
+				return mcp.NewToolResultError("the envOverrides parameter is not supported for edge stacks"), nil
+			}
+		} else {
+			env, err := s.cli.GetStackEnv(id)
+			if err != nil {
+				return newToolResultAPIError("failed to get stack env", err), nil
+			}
+
+			current := make(map[string]string, len(env))
+			for _, entry := range env {
+				current[entry.Name] = entry.Value
+			}
+			proposed := make(map[string]string, len(current)+len(envOverrides))
+			for name, value := range current {
+				proposed[name] = value
+			}
+			for _, override := range envOverrides {
+				proposed[override.Name] = override.Value
+			}
+
+			envChange := compose.DiffValues(current, proposed)
+			result.Env = &envChange
+			result.Changed = result.Changed || !envChange.Empty()
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack diff", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/internal/compose"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDiffStack(t *testing.T) {
+	liveFile := "services:\n  web:\n    image: nginx:1.26\n"
+	proposedFile := "services:\n  web:\n    image: nginx:1.27\n"
+
+	t.Run("regular stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return(liveFile, nil)
+		mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{{Name: "TOKEN", Value: "secret"}, {Name: "MODE", Value: "prod"}}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleDiffStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":   float64(1),
+			"file": proposedFile,
+			"envOverrides": []any{
+				map[string]any{"name": "TOKEN", "value": "rotated"},
+				map[string]any{"name": "MODE", "value": "prod"},
+				map[string]any{"name": "DEBUG", "value": "1"},
+			},
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		text := resultText(t, result)
+		assert.NotContains(t, text, "secret")
+		assert.NotContains(t, text, "rotated")
+
+		var diff stackDiff
+		require.NoError(t, json.Unmarshal([]byte(text), &diff))
+		assert.True(t, diff.Changed)
+		assert.Contains(t, diff.Unified, "+    image: nginx:1.27")
+		require.Len(t, diff.Services, 1)
+		assert.Equal(t, &compose.ValueChange{From: "nginx:1.26", To: "nginx:1.27"}, diff.Services[0].Image)
+		assert.Equal(t, &compose.NameChange{Added: []string{"DEBUG"}, Changed: []string{"TOKEN"}}, diff.Env)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("identical edge stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 2, models.StackKindEdge).Return(models.Stack{ID: 2, Kind: models.StackKindEdge}, nil)
+		mockClient.On("GetStackFile", 2, models.StackKindEdge).Return(liveFile, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleDiffStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":   float64(2),
+			"kind": models.StackKindEdge,
+			"file": liveFile,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		var diff stackDiff
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &diff))
+		assert.False(t, diff.Changed)
+		assert.Empty(t, diff.Services)
+		assert.Nil(t, diff.Env)
+	})
+
+	t.Run("env overrides on edge stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 2, models.StackKindEdge).Return(models.Stack{ID: 2, Kind: models.StackKindEdge}, nil)
+		mockClient.On("GetStackFile", 2, models.StackKindEdge).Return(liveFile, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleDiffStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":           float64(2),
+			"kind":         models.StackKindEdge,
+			"file":         liveFile,
+			"envOverrides": []any{map[string]any{"name": "TOKEN", "value": "rotated"}},
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 3, "").Return(models.Stack{}, fmt.Errorf("stack not found"))
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleDiffStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":   float64(3),
+			"file": proposedFile,
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "stack not found")
+	})
+
+	t.Run("ambiguous ID", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 4, "").Return(models.Stack{}, client.ErrAmbiguousStackID)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleDiffStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":   float64(4),
+			"file": proposedFile,
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		mockClient.AssertNotCalled(t, "GetStackFile", 4, mock.Anything)
+	})
+}
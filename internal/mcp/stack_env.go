@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleListStackEnv() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		showValues, err := parser.GetBoolean("showValues", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid showValues parameter", err), nil
+		}
+
+		env, err := s.cli.GetStackEnv(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack env", err), nil
+		}
+
+		result := make([]models.StackEnvVar, 0, len(env))
+		for _, entry := range env {
+			// Empty values are not masked, so that unset variables can be told apart
+			if !showValues && entry.Value != "" {
+				entry.Value = maskedEnvValue
+			}
+			result = append(result, entry)
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack env", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleSetStackEnv() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		envRaw, err := parser.GetArrayOfObjects("env", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+		}
+
+		env, err := parseStackEnvOverrides(envRaw)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+		}
+		if len(env) == 0 {
+			return mcp.NewToolResultError("at least one environment variable must be provided"), nil
+		}
+
+		return s.updateStackEnv(ToolSetStackEnv, id, models.StackEnvChange{Set: env}, "Stack env variables set successfully"), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleUnsetStackEnv() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		names, err := parser.GetArrayOfStrings("names", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid names parameter", err), nil
+		}
+		if len(names) == 0 {
+			return mcp.NewToolResultError("at least one environment variable name must be provided"), nil
+		}
+
+		return s.updateStackEnv(ToolUnsetStackEnv, id, models.StackEnvChange{Unset: names}, "Stack env variables removed successfully"), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleRenameStackEnv() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		newName, err := parser.GetString("newName", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid newName parameter", err), nil
+		}
+		if newName == "" || newName == name {
+			return mcp.NewToolResultError("newName must be a different, non-empty name"), nil
+		}
+
+		change := models.StackEnvChange{Rename: map[string]string{name: newName}}
+		return s.updateStackEnv(ToolRenameStackEnv, id, change, fmt.Sprintf("Stack env variable %s renamed to %s successfully", name, newName)), nil
+	}
+}
+
+// updateStackEnv applies a change to the environment variables of a stack, recording it in the change journal
+// and the stack revisions like updateStack
+func (s *PortainerMCPServer) updateStackEnv(tool string, id int, change models.StackEnvChange, message string) *mcp.CallToolResult {
+	observed := s.observeStackWarning(id, models.StackKindRegular)
+
+	pending := s.captureChange(ChangeKindStack, id)
+
+	if err := s.cli.UpdateStackEnv(id, change); err != nil {
+		return newToolResultAPIError("failed to update stack env", err)
+	}
+
+	return mcp.NewToolResultText(message + observed + s.recordChange(tool, pending) + s.recordStackRevision(tool, id, models.StackKindRegular))
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleListStackEnv(t *testing.T) {
+	env := []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}, {Name: "EMPTY", Value: ""}}
+
+	tests := []struct {
+		name       string
+		showValues bool
+		want       []models.StackEnvVar
+	}{
+		{
+			name: "masked values",
+			want: []models.StackEnvVar{{Name: "TOKEN", Value: maskedEnvValue}, {Name: "EMPTY", Value: ""}},
+		},
+		{
+			name:       "shown values",
+			showValues: true,
+			want:       env,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStackEnv", 1).Return(env, nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleListStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+				"id":         float64(1),
+				"showValues": tt.showValues,
+			}))
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+
+			var got []models.StackEnvVar
+			require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("edge stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackEnv", 2).Return(nil, client.ErrEdgeStackEnv)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleListStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+			"id": float64(2),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "not available for edge stacks")
+	})
+}
+
+func TestHandleSetStackEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]any
+		mockError   error
+		expectError string
+	}{
+		{
+			name: "successful set",
+			params: map[string]any{
+				"id":  float64(1),
+				"env": []any{map[string]any{"name": "MODE", "value": "prod"}},
+			},
+		},
+		{
+			name:        "empty env",
+			params:      map[string]any{"id": float64(1), "env": []any{}},
+			expectError: "at least one environment variable must be provided",
+		},
+		{
+			name: "edge stack",
+			params: map[string]any{
+				"id":  float64(1),
+				"env": []any{map[string]any{"name": "MODE", "value": "prod"}},
+			},
+			mockError:   client.ErrEdgeStackEnv,
+			expectError: "failed to update stack env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("UpdateStackEnv", 1, models.StackEnvChange{Set: []models.StackEnvVar{{Name: "MODE", Value: "prod"}}}).Return(tt.mockError)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleSetStackEnv()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, "Stack env variables set successfully", resultText(t, result))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleUnsetStackEnv(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("UpdateStackEnv", 1, models.StackEnvChange{Unset: []string{"DEBUG", "OLD"}}).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleUnsetStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+		"id":    float64(1),
+		"names": []any{"DEBUG", "OLD"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "Stack env variables removed successfully", resultText(t, result))
+	mockClient.AssertExpectations(t)
+
+	result, err = server.HandleUnsetStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+		"id":    float64(1),
+		"names": []any{},
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleRenameStackEnv(t *testing.T) {
+	t.Run("successful rename with revision", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("services: {}", nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"TOKEN"}, nil).Once()
+		mockClient.On("UpdateStackEnv", 1, models.StackEnvChange{Rename: map[string]string{"TOKEN": "API_TOKEN"}}).Return(nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"API_TOKEN"}, nil).Once()
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: newTestRevisionStore(t)}
+
+		result, err := server.HandleRenameStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":      float64(1),
+			"name":    "TOKEN",
+			"newName": "API_TOKEN",
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Equal(t, "Stack env variable TOKEN renamed to API_TOKEN successfully (stack revision 2 saved)", resultText(t, result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("previous state not observed", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("", assert.AnError).Once()
+		mockClient.On("UpdateStackEnv", 1, models.StackEnvChange{Rename: map[string]string{"TOKEN": "API_TOKEN"}}).Return(nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("services: {}", nil).Once()
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"API_TOKEN"}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: newTestRevisionStore(t)}
+
+		result, err := server.HandleRenameStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":      float64(1),
+			"name":    "TOKEN",
+			"newName": "API_TOKEN",
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, "failing to observe the previous state must not prevent the update")
+		assert.Contains(t, resultText(t, result), "warning: the previous state of the stack was not saved as a revision")
+		assert.Contains(t, resultText(t, result), "(stack revision 1 saved)")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+
+		result, err := server.HandleRenameStackEnv()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":      float64(1),
+			"name":    "TOKEN",
+			"newName": "TOKEN",
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "newName must be a different, non-empty name")
+	})
+}
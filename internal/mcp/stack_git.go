@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleCreateStackFromGit() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		var opts models.StackGitCreateOptions
+		var err error
+
+		if opts.Name, err = parser.GetString("name", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if opts.EnvironmentID, err = parser.GetInt("environmentId", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if opts.Type, err = parser.GetString("type", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid type parameter", err), nil
+		}
+		if opts.Type != "" && opts.Type != models.StackTypeStandalone && opts.Type != models.StackTypeSwarm {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid type parameter: %s", opts.Type)), nil
+		}
+		if opts.RepositoryURL, err = parser.GetString("repositoryUrl", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid repositoryUrl parameter", err), nil
+		}
+		if opts.ReferenceName, err = parser.GetString("referenceName", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid referenceName parameter", err), nil
+		}
+		if opts.ComposeFile, err = parser.GetString("composeFile", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid composeFile parameter", err), nil
+		}
+		if opts.AdditionalFiles, err = parser.GetArrayOfStrings("additionalFiles", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid additionalFiles parameter", err), nil
+		}
+		if opts.Username, err = parser.GetString("username", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid username parameter", err), nil
+		}
+		if opts.Password, err = parser.GetString("password", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid password parameter", err), nil
+		}
+		if opts.GitCredentialID, err = parser.GetInt("gitCredentialId", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid gitCredentialId parameter", err), nil
+		}
+		if opts.TLSSkipVerify, err = parser.GetBoolean("tlsSkipVerify", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tlsSkipVerify parameter", err), nil
+		}
+
+		envRaw, err := parser.GetArrayOfObjects("env", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+		}
+		if len(envRaw) > 0 {
+			if opts.Env, err = parseStackEnvOverrides(envRaw); err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+			}
+		}
+
+		if opts.AutoUpdate, err = parseStackAutoUpdate(parser, ""); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid auto-update parameters", err), nil
+		}
+
+		id, err := s.cli.CreateStackFromGit(opts)
+		if err != nil {
+			return newToolResultAPIError("error creating stack from git", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack created successfully with ID: %d", id)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleGetStackGitSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		settings, err := s.cli.GetStackGitSettings(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack git settings", err), nil
+		}
+
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack git settings", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleUpdateStackAutoUpdate() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		current, err := s.cli.GetStackGitSettings(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack git settings", err), nil
+		}
+
+		currentWebhook := ""
+		if current.AutoUpdate != nil {
+			currentWebhook = current.AutoUpdate.Webhook
+		}
+
+		autoUpdate, err := parseStackAutoUpdate(parser, currentWebhook)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid auto-update parameters", err), nil
+		}
+
+		change := s.captureChange(ChangeKindStackAutoUpdate, id)
+
+		if err := s.cli.UpdateStackAutoUpdate(id, autoUpdate); err != nil {
+			return newToolResultAPIError("failed to update stack auto-update settings", err), nil
+		}
+
+		message := "Stack auto-update settings updated successfully"
+		if autoUpdate == nil {
+			message = "Stack auto-update disabled successfully"
+		}
+
+		return mcp.NewToolResultText(message + s.recordChange(ToolUpdateStackAutoUpdate, change)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleRedeployStackFromGit() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		var opts models.StackGitRedeployOptions
+		if opts.ReferenceName, err = parser.GetString("referenceName", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid referenceName parameter", err), nil
+		}
+		if opts.PullImage, err = parser.GetBoolean("pullImage", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid pullImage parameter", err), nil
+		}
+		if opts.Prune, err = parser.GetBoolean("prune", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid prune parameter", err), nil
+		}
+
+		if err := s.cli.RedeployStackFromGit(id, opts); err != nil {
+			return newToolResultAPIError("failed to redeploy stack from git", err), nil
+		}
+
+		return mcp.NewToolResultText("Stack redeployed successfully"), nil
+	}
+}
+
+// parseStackAutoUpdate reads the auto-update parameters of a git stack.
+// It returns nil, which disables auto-updates, if neither an interval nor a webhook is requested.
+// currentWebhook is reused when a webhook is requested, so that the webhook URL of the stack does not change.
+func parseStackAutoUpdate(parser *toolgen.ParameterParser, currentWebhook string) (*models.StackAutoUpdate, error) {
+	interval, err := parser.GetString("autoUpdateInterval", false)
+	if err != nil {
+		return nil, err
+	}
+	if interval != "" {
+		if _, err := time.ParseDuration(interval); err != nil {
+			return nil, fmt.Errorf("invalid autoUpdateInterval: %w", err)
+		}
+	}
+
+	webhook, err := parser.GetBoolean("autoUpdateWebhook", false)
+	if err != nil {
+		return nil, err
+	}
+
+	forceUpdate, err := parser.GetBoolean("forceUpdate", false)
+	if err != nil {
+		return nil, err
+	}
+
+	forcePullImage, err := parser.GetBoolean("forcePullImage", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval == "" && !webhook {
+		return nil, nil
+	}
+
+	autoUpdate := &models.StackAutoUpdate{
+		Interval:       interval,
+		ForceUpdate:    forceUpdate,
+		ForcePullImage: forcePullImage,
+	}
+
+	if webhook {
+		autoUpdate.Webhook = currentWebhook
+		if autoUpdate.Webhook == "" {
+			if autoUpdate.Webhook, err = newUUID(); err != nil {
+				return nil, fmt.Errorf("failed to generate webhook id: %w", err)
+			}
+		}
+	}
+
+	return autoUpdate, nil
+}
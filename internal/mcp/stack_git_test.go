@@ -0,0 +1,238 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateStackFromGit(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		check       func(t *testing.T, opts models.StackGitCreateOptions)
+		mockError   error
+		expectError bool
+	}{
+		{
+			name: "with credentials and webhook",
+			args: map[string]any{
+				"name":              "web",
+				"environmentId":     float64(3),
+				"type":              models.StackTypeSwarm,
+				"repositoryUrl":     "https://git.example.com/ops/stacks.git",
+				"referenceName":     "refs/heads/main",
+				"composeFile":       "web/docker-compose.yml",
+				"additionalFiles":   []any{"web/override.yml"},
+				"username":          "deploy",
+				"password":          "token",
+				"env":               []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+				"autoUpdateWebhook": true,
+			},
+			check: func(t *testing.T, opts models.StackGitCreateOptions) {
+				assert.Equal(t, "web", opts.Name)
+				assert.Equal(t, 3, opts.EnvironmentID)
+				assert.Equal(t, models.StackTypeSwarm, opts.Type)
+				assert.Equal(t, "https://git.example.com/ops/stacks.git", opts.RepositoryURL)
+				assert.Equal(t, "refs/heads/main", opts.ReferenceName)
+				assert.Equal(t, "web/docker-compose.yml", opts.ComposeFile)
+				assert.Equal(t, []string{"web/override.yml"}, opts.AdditionalFiles)
+				assert.Equal(t, "deploy", opts.Username)
+				assert.Equal(t, "token", opts.Password)
+				assert.Equal(t, []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}, opts.Env)
+				require.NotNil(t, opts.AutoUpdate)
+				assert.Empty(t, opts.AutoUpdate.Interval)
+				assert.Len(t, opts.AutoUpdate.Webhook, 36, "a webhook id must be generated")
+			},
+		},
+		{
+			name: "without auto-update",
+			args: map[string]any{
+				"name":          "web",
+				"environmentId": float64(3),
+				"repositoryUrl": "https://git.example.com/ops/stacks.git",
+			},
+			check: func(t *testing.T, opts models.StackGitCreateOptions) {
+				assert.Nil(t, opts.AutoUpdate)
+				assert.Nil(t, opts.Env)
+			},
+		},
+		{
+			name: "api error",
+			args: map[string]any{
+				"name":          "web",
+				"environmentId": float64(3),
+				"repositoryUrl": "https://git.example.com/ops/stacks.git",
+			},
+			mockError:   fmt.Errorf("api error"),
+			expectError: true,
+		},
+		{
+			name: "missing repository",
+			args: map[string]any{
+				"name":          "web",
+				"environmentId": float64(3),
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid interval",
+			args: map[string]any{
+				"name":               "web",
+				"environmentId":      float64(3),
+				"repositoryUrl":      "https://git.example.com/ops/stacks.git",
+				"autoUpdateInterval": "often",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("CreateStackFromGit", mock.Anything).Return(5, tt.mockError)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleCreateStackFromGit()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				if tt.mockError != nil {
+					assert.Contains(t, resultText(t, result), tt.mockError.Error())
+				} else {
+					mockClient.AssertNotCalled(t, "CreateStackFromGit", mock.Anything)
+				}
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, "Stack created successfully with ID: 5", resultText(t, result))
+			tt.check(t, mockClient.Calls[0].Arguments.Get(0).(models.StackGitCreateOptions))
+		})
+	}
+}
+
+func TestHandleGetStackGitSettings(t *testing.T) {
+	settings := models.StackGitSettings{
+		StackID:        1,
+		StackGitConfig: models.StackGitConfig{RepositoryURL: "https://git.example.com/ops/stacks.git"},
+		AutoUpdate:     &models.StackAutoUpdate{Interval: "5m"},
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackGitSettings", 1).Return(settings, nil)
+	mockClient.On("GetStackGitSettings", 2).Return(models.StackGitSettings{}, fmt.Errorf("stack 2 is not deployed from a git repository"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetStackGitSettings()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var got models.StackGitSettings
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &got))
+	assert.Equal(t, settings, got)
+
+	result, err = server.HandleGetStackGitSettings()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "not deployed from a git repository")
+}
+
+func TestHandleUpdateStackAutoUpdate(t *testing.T) {
+	const webhook = "05de31a2-79fa-4644-9c12-faa67e5c49f0"
+
+	tests := []struct {
+		name     string
+		current  *models.StackAutoUpdate
+		args     map[string]any
+		expected *models.StackAutoUpdate
+		message  string
+	}{
+		{
+			name:     "enable polling",
+			args:     map[string]any{"id": float64(1), "autoUpdateInterval": "10m", "forcePullImage": true},
+			expected: &models.StackAutoUpdate{Interval: "10m", ForcePullImage: true},
+			message:  "Stack auto-update settings updated successfully",
+		},
+		{
+			name:     "keep existing webhook",
+			current:  &models.StackAutoUpdate{Interval: "5m", Webhook: webhook},
+			args:     map[string]any{"id": float64(1), "autoUpdateWebhook": true},
+			expected: &models.StackAutoUpdate{Webhook: webhook},
+			message:  "Stack auto-update settings updated successfully",
+		},
+		{
+			name:    "disable",
+			current: &models.StackAutoUpdate{Interval: "5m"},
+			args:    map[string]any{"id": float64(1)},
+			message: "Stack auto-update disabled successfully",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStackGitSettings", 1).Return(models.StackGitSettings{StackID: 1, AutoUpdate: tt.current}, nil)
+			mockClient.On("UpdateStackAutoUpdate", 1, tt.expected).Return(nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleUpdateStackAutoUpdate()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tt.message, resultText(t, result))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStackAutoUpdateCaptureAndApply(t *testing.T) {
+	autoUpdate := &models.StackAutoUpdate{Interval: "5m", ForceUpdate: true}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackGitSettings", 1).Return(models.StackGitSettings{StackID: 1, AutoUpdate: autoUpdate}, nil)
+	mockClient.On("UpdateStackAutoUpdate", 1, autoUpdate).Return(nil)
+	mockClient.On("UpdateStackAutoUpdate", 1, (*models.StackAutoUpdate)(nil)).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	state, err := server.captureState(ChangeKindStackAutoUpdate, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"interval":"5m","force_update":true,"force_pull_image":false}`, string(state))
+
+	require.NoError(t, server.applyState(ChangeKindStackAutoUpdate, 1, state))
+	require.NoError(t, server.applyState(ChangeKindStackAutoUpdate, 1, json.RawMessage(`null`)))
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleRedeployStackFromGit(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("RedeployStackFromGit", 1, models.StackGitRedeployOptions{ReferenceName: "refs/tags/v2", PullImage: true}).Return(nil)
+	mockClient.On("RedeployStackFromGit", 2, models.StackGitRedeployOptions{}).Return(fmt.Errorf("api error"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleRedeployStackFromGit()(context.Background(), CreateMCPRequest(map[string]any{
+		"id":            float64(1),
+		"referenceName": "refs/tags/v2",
+		"pullImage":     true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "Stack redeployed successfully", resultText(t, result))
+
+	result, err = server.HandleRedeployStackFromGit()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	mockClient.AssertExpectations(t)
+}
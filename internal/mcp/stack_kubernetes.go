@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/compose"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+// Formats of the file of a kubernetes stack
+const (
+	kubernetesFormatManifest = "manifest"
+	kubernetesFormatCompose  = "compose"
+)
+
+func (s *PortainerMCPServer) HandleCreateKubernetesStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		var opts models.KubernetesStackCreateOptions
+		var err error
+
+		if opts.Name, err = parser.GetString("name", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if opts.EnvironmentID, err = parser.GetInt("environmentId", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if opts.Namespace, err = parser.GetString("namespace", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+		if opts.Manifest, err = parser.GetString("manifest", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid manifest parameter", err), nil
+		}
+
+		format, err := parser.GetString("format", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid format parameter", err), nil
+		}
+		switch format {
+		case "", kubernetesFormatManifest:
+		case kubernetesFormatCompose:
+			opts.ComposeFormat = true
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid format parameter: %s", format)), nil
+		}
+
+		skipValidation, err := parser.GetBoolean("skipValidation", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid skipValidation parameter", err), nil
+		}
+
+		environment, err := s.cli.GetEnvironment(opts.EnvironmentID)
+		if err != nil {
+			return newToolResultAPIError("failed to get environment", err), nil
+		}
+		if environment.Platform != models.EnvironmentPlatformKubernetes {
+			return mcp.NewToolResultError(fmt.Sprintf("environment %d is a %s environment, kubernetes stacks can only be created on kubernetes environments", opts.EnvironmentID, environment.Platform)), nil
+		}
+
+		var warnings string
+		if !skipValidation {
+			var failed *mcp.CallToolResult
+			if failed, warnings = checkKubernetesStackFile(opts.Manifest, opts.Namespace, opts.ComposeFormat); failed != nil {
+				return failed, nil
+			}
+		}
+
+		id, err := s.cli.CreateKubernetesStack(opts)
+		if err != nil {
+			return newToolResultAPIError("error creating kubernetes stack", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Kubernetes stack created successfully with ID: %d", id) + s.recordStackRevision(ToolCreateKubernetesStack, id, models.StackKindRegular) + warnings), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleUpdateKubernetesStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		manifest, err := parser.GetString("manifest", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid manifest parameter", err), nil
+		}
+
+		skipValidation, err := parser.GetBoolean("skipValidation", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid skipValidation parameter", err), nil
+		}
+
+		stack, err := s.cli.GetStack(id, models.StackKindRegular)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+		if stack.Kind != models.StackKindKubernetes {
+			return mcp.NewToolResultError(fmt.Sprintf("stack %d is a %s stack, use updateStack to update it", id, stack.Kind)), nil
+		}
+
+		var warnings string
+		if !skipValidation {
+			var failed *mcp.CallToolResult
+			if failed, warnings = checkKubernetesStackFile(manifest, stack.Namespace, stack.ComposeFormat); failed != nil {
+				return failed, nil
+			}
+		}
+
+		observed := s.observeStackWarning(id, models.StackKindRegular)
+
+		change := s.captureChange(ChangeKindStack, id)
+
+		if err := s.cli.UpdateKubernetesStack(id, manifest); err != nil {
+			return newToolResultAPIError("failed to update kubernetes stack", err), nil
+		}
+
+		return mcp.NewToolResultText("Kubernetes stack updated successfully" + observed + s.recordChange(ToolUpdateKubernetesStack, change) + s.recordStackRevision(ToolUpdateKubernetesStack, id, models.StackKindRegular) + warnings), nil
+	}
+}
+
+// checkKubernetesStackFile validates the file of a kubernetes stack, a manifest or a compose file
+// converted by Portainer, before it is sent to Portainer
+func checkKubernetesStackFile(file, namespace string, composeFormat bool) (*mcp.CallToolResult, string) {
+	if composeFormat {
+		return checkStackFile(file, nil)
+	}
+	return checkValidationReport(compose.ValidateManifest(file, namespace))
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubernetesManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+`
+
+func TestHandleCreateKubernetesStack(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       map[string]any
+		platform     string
+		expectOpts   *models.KubernetesStackCreateOptions
+		expectError  string
+		expectResult string
+	}{
+		{
+			name: "manifest",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "shop", "manifest": testKubernetesManifest,
+			},
+			platform: models.EnvironmentPlatformKubernetes,
+			expectOpts: &models.KubernetesStackCreateOptions{
+				Name: "shop", EnvironmentID: 7, Namespace: "shop", Manifest: testKubernetesManifest,
+			},
+			expectResult: "Kubernetes stack created successfully with ID: 21",
+		},
+		{
+			name: "compose format",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "shop", "format": "compose",
+				"manifest": "services:\n  web:\n    image: nginx:1.27\n",
+			},
+			platform: models.EnvironmentPlatformKubernetes,
+			expectOpts: &models.KubernetesStackCreateOptions{
+				Name: "shop", EnvironmentID: 7, Namespace: "shop", ComposeFormat: true,
+				Manifest: "services:\n  web:\n    image: nginx:1.27\n",
+			},
+			expectResult: "Kubernetes stack created successfully with ID: 21",
+		},
+		{
+			name: "other namespace",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "staging", "manifest": testKubernetesManifest,
+			},
+			platform:    models.EnvironmentPlatformKubernetes,
+			expectError: `Service/web targets namespace "shop" but the stack is deployed to namespace "staging"`,
+		},
+		{
+			name: "validation skipped",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "staging", "manifest": testKubernetesManifest,
+				"skipValidation": true,
+			},
+			platform: models.EnvironmentPlatformKubernetes,
+			expectOpts: &models.KubernetesStackCreateOptions{
+				Name: "shop", EnvironmentID: 7, Namespace: "staging", Manifest: testKubernetesManifest,
+			},
+			expectResult: "Kubernetes stack created successfully with ID: 21",
+		},
+		{
+			name: "docker environment",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "shop", "manifest": testKubernetesManifest,
+			},
+			platform:    models.EnvironmentPlatformDockerStandalone,
+			expectError: "environment 7 is a docker-standalone environment, kubernetes stacks can only be created on kubernetes environments",
+		},
+		{
+			name: "invalid format",
+			params: map[string]any{
+				"name": "shop", "environmentId": float64(7), "namespace": "shop", "manifest": testKubernetesManifest,
+				"format": "helm",
+			},
+			expectError: "invalid format parameter: helm",
+		},
+		{
+			name:        "missing namespace",
+			params:      map[string]any{"name": "shop", "environmentId": float64(7), "manifest": testKubernetesManifest},
+			expectError: "invalid namespace parameter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironment", 7).Return(models.EnvironmentDetails{Environment: models.Environment{ID: 7}, Platform: tt.platform}, nil)
+			if tt.expectOpts != nil {
+				mockClient.On("CreateKubernetesStack", *tt.expectOpts).Return(21, nil)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleCreateKubernetesStack()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				mockClient.AssertNotCalled(t, "CreateKubernetesStack", mock.Anything)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tt.expectResult, resultText(t, result))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleUpdateKubernetesStack(t *testing.T) {
+	kubernetesStack := models.Stack{ID: 3, Name: "shop", Kind: models.StackKindKubernetes, EnvironmentID: 7, Namespace: "shop"}
+
+	tests := []struct {
+		name         string
+		stack        models.Stack
+		manifest     string
+		expectUpdate bool
+		expectError  string
+	}{
+		{
+			name:         "manifest",
+			stack:        kubernetesStack,
+			manifest:     testKubernetesManifest,
+			expectUpdate: true,
+		},
+		{
+			name:        "other namespace",
+			stack:       models.Stack{ID: 3, Name: "shop", Kind: models.StackKindKubernetes, EnvironmentID: 7, Namespace: "staging"},
+			manifest:    testKubernetesManifest,
+			expectError: "stack file validation failed",
+		},
+		{
+			name:         "compose format",
+			stack:        models.Stack{ID: 3, Name: "shop", Kind: models.StackKindKubernetes, EnvironmentID: 7, Namespace: "shop", ComposeFormat: true},
+			manifest:     "services:\n  web:\n    image: nginx:1.27\n",
+			expectUpdate: true,
+		},
+		{
+			name:        "manifest for a compose format stack",
+			stack:       models.Stack{ID: 3, Name: "shop", Kind: models.StackKindKubernetes, EnvironmentID: 7, Namespace: "shop", ComposeFormat: true},
+			manifest:    testKubernetesManifest,
+			expectError: "unknown top-level key",
+		},
+		{
+			name:        "compose stack",
+			stack:       models.Stack{ID: 3, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 2},
+			manifest:    testKubernetesManifest,
+			expectError: "stack 3 is a compose stack, use updateStack to update it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStack", 3, models.StackKindRegular).Return(tt.stack, nil)
+			if tt.expectUpdate {
+				mockClient.On("UpdateKubernetesStack", 3, tt.manifest).Return(nil)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleUpdateKubernetesStack()(context.Background(), CreateMCPRequest(map[string]any{
+				"id":       float64(3),
+				"manifest": tt.manifest,
+			}))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				mockClient.AssertNotCalled(t, "UpdateKubernetesStack", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Contains(t, resultText(t, result), "Kubernetes stack updated successfully")
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
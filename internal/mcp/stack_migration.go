@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleDuplicateStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		environmentID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		opts, failed := s.prepareStackCopy(id, environmentID, name)
+		if failed != nil {
+			return failed, nil
+		}
+
+		newID, err := s.cli.CreateStack(opts)
+		if err != nil {
+			return newToolResultAPIError("failed to create the stack copy", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack duplicated successfully with ID: %d", newID) + s.recordStackRevision(ToolDuplicateStack, newID, models.StackKindRegular)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleMigrateStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		environmentID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		opts, failed := s.prepareStackCopy(id, environmentID, name)
+		if failed != nil {
+			return failed, nil
+		}
+
+		// The source stack is kept in the revisions, as it is deleted once the copy is created
+		observed := s.observeStackWarning(id, models.StackKindRegular)
+
+		newID, err := s.cli.CreateStack(opts)
+		if err != nil {
+			return newToolResultAPIError("failed to create the stack on the target environment", err), nil
+		}
+
+		if err := s.cli.DeleteStack(id, models.StackKindRegular, false); err != nil {
+			return newToolResultAPIError(fmt.Sprintf("stack created on the target environment with ID %d, but the source stack could not be deleted", newID), err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack migrated successfully, new stack ID: %d", newID) + observed + s.recordStackRevision(ToolMigrateStack, newID, models.StackKindRegular)), nil
+	}
+}
+
+// prepareStackCopy reads the file and environment variables of a regular stack and checks that it can be created
+// on an environment under a name, which defaults to the name of the stack.
+// It returns the options to create the copy, or an error result if the stack cannot be copied.
+func (s *PortainerMCPServer) prepareStackCopy(id, environmentID int, name string) (models.StackCreateOptions, *mcp.CallToolResult) {
+	stack, err := s.cli.GetStack(id, models.StackKindRegular)
+	if err != nil {
+		return models.StackCreateOptions{}, newToolResultAPIError("failed to get stack", err)
+	}
+
+	switch {
+	case stack.Kind == models.StackKindEdge:
+		return models.StackCreateOptions{}, mcp.NewToolResultError("edge stacks cannot be copied, they are deployed to environment groups")
+	case stack.Kind == models.StackKindKubernetes:
+		return models.StackCreateOptions{}, mcp.NewToolResultError("kubernetes stacks cannot be copied")
+	case stack.Git != nil:
+		return models.StackCreateOptions{}, mcp.NewToolResultError("git stacks cannot be copied, create the stack from the repository with createStackFromGit instead")
+	}
+
+	if name == "" {
+		name = stack.Name
+	}
+	if stack.EnvironmentID == environmentID && stack.Name == name {
+		return models.StackCreateOptions{}, mcp.NewToolResultError(fmt.Sprintf("stack %d is already named %s on environment %d", id, name, environmentID))
+	}
+
+	environment, err := s.cli.GetEnvironment(environmentID)
+	if err != nil {
+		return models.StackCreateOptions{}, newToolResultAPIError("failed to get target environment", err)
+	}
+
+	stackType, ok := stackTypeForPlatform(stack.Kind, environment.Platform)
+	if !ok {
+		return models.StackCreateOptions{}, mcp.NewToolResultError(fmt.Sprintf("a %s stack cannot be deployed on environment %d, which is a %s environment", stack.Kind, environmentID, environment.Platform))
+	}
+
+	stacks, err := s.cli.GetStacks()
+	if err != nil {
+		return models.StackCreateOptions{}, newToolResultAPIError("failed to get stacks", err)
+	}
+	for _, existing := range stacks {
+		if existing.Name == name && existing.EnvironmentID == environmentID {
+			return models.StackCreateOptions{}, mcp.NewToolResultError(fmt.Sprintf("a stack named %s already exists on environment %d (stack %d)", name, environmentID, existing.ID))
+		}
+	}
+
+	file, err := s.cli.GetStackFile(id, models.StackKindRegular)
+	if err != nil {
+		return models.StackCreateOptions{}, newToolResultAPIError("failed to get stack file", err)
+	}
+
+	env, err := s.cli.GetStackEnv(id)
+	if err != nil {
+		return models.StackCreateOptions{}, newToolResultAPIError("failed to get stack env", err)
+	}
+
+	return models.StackCreateOptions{
+		Name:          name,
+		File:          file,
+		EnvironmentID: environmentID,
+		Type:          stackType,
+		Env:           env,
+	}, nil
+}
+
+// stackTypeForPlatform returns the type of the regular stack to create for a stack kind on an environment platform
+func stackTypeForPlatform(kind, platform string) (string, bool) {
+	switch {
+	case kind == models.StackKindCompose && (platform == models.EnvironmentPlatformDockerStandalone || platform == models.EnvironmentPlatformPodman):
+		return models.StackTypeStandalone, true
+	case kind == models.StackKindSwarm && platform == models.EnvironmentPlatformDockerSwarm:
+		return models.StackTypeSwarm, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDuplicateStack(t *testing.T) {
+	source := models.Stack{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 2}
+	env := []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}
+
+	tests := []struct {
+		name        string
+		stack       models.Stack
+		platform    string
+		stacks      []models.Stack
+		expectError string
+		expectType  string
+	}{
+		{
+			name:       "compose stack to standalone environment",
+			stack:      source,
+			platform:   models.EnvironmentPlatformDockerStandalone,
+			stacks:     []models.Stack{source, {ID: 7, Name: "web-prod", EnvironmentID: 4}},
+			expectType: models.StackTypeStandalone,
+		},
+		{
+			name:       "swarm stack to swarm environment",
+			stack:      models.Stack{ID: 1, Name: "web", Kind: models.StackKindSwarm, EnvironmentID: 2},
+			platform:   models.EnvironmentPlatformDockerSwarm,
+			expectType: models.StackTypeSwarm,
+		},
+		{
+			name:        "environment type mismatch",
+			stack:       source,
+			platform:    models.EnvironmentPlatformDockerSwarm,
+			expectError: "a compose stack cannot be deployed on environment 3, which is a docker-swarm environment",
+		},
+		{
+			name:        "name conflict",
+			stack:       source,
+			platform:    models.EnvironmentPlatformDockerStandalone,
+			stacks:      []models.Stack{{ID: 8, Name: "web-prod", EnvironmentID: 3}},
+			expectError: "a stack named web-prod already exists on environment 3 (stack 8)",
+		},
+		{
+			name:        "edge stack",
+			stack:       models.Stack{ID: 1, Name: "web", Kind: models.StackKindEdge},
+			expectError: "edge stacks cannot be copied",
+		},
+		{
+			name: "git stack",
+			stack: models.Stack{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 2,
+				Git: &models.StackGitConfig{RepositoryURL: "https://git.example.com/ops/stacks.git"}},
+			expectError: "git stacks cannot be copied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStack", 1, models.StackKindRegular).Return(tt.stack, nil)
+			mockClient.On("GetEnvironment", 3).Return(models.EnvironmentDetails{Environment: models.Environment{ID: 3}, Platform: tt.platform}, nil)
+			mockClient.On("GetStacks").Return(tt.stacks, nil)
+			mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("services: {}", nil)
+			mockClient.On("GetStackEnv", 1).Return(env, nil)
+			mockClient.On("CreateStack", models.StackCreateOptions{
+				Name:          "web-prod",
+				File:          "services: {}",
+				EnvironmentID: 3,
+				Type:          tt.expectType,
+				Env:           env,
+			}).Return(9, nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleDuplicateStack()(context.Background(), CreateMCPRequest(map[string]any{
+				"id":            float64(1),
+				"name":          "web-prod",
+				"environmentId": float64(3),
+			}))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				mockClient.AssertNotCalled(t, "CreateStack", mock.Anything)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, "Stack duplicated successfully with ID: 9", resultText(t, result))
+			mockClient.AssertCalled(t, "CreateStack", mock.Anything)
+		})
+	}
+}
+
+func TestHandleMigrateStack(t *testing.T) {
+	source := models.Stack{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 2}
+	options := models.StackCreateOptions{
+		Name:          "web",
+		File:          "services: {}",
+		EnvironmentID: 3,
+		Type:          models.StackTypeStandalone,
+		Env:           []models.StackEnvVar{},
+	}
+
+	setup := func(deleteErr error) *MockPortainerClient {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, models.StackKindRegular).Return(source, nil)
+		mockClient.On("GetEnvironment", 3).Return(models.EnvironmentDetails{Platform: models.EnvironmentPlatformPodman}, nil)
+		mockClient.On("GetStacks").Return([]models.Stack{source}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return("services: {}", nil)
+		mockClient.On("GetStackEnv", 1).Return([]models.StackEnvVar{}, nil)
+		mockClient.On("CreateStack", options).Return(9, nil)
+		mockClient.On("DeleteStack", 1, models.StackKindRegular, false).Return(deleteErr)
+		return mockClient
+	}
+
+	t.Run("successful migration", func(t *testing.T) {
+		mockClient := setup(nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleMigrateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":            float64(1),
+			"environmentId": float64(3),
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Equal(t, "Stack migrated successfully, new stack ID: 9", resultText(t, result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("source deletion failure", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: setup(assert.AnError)}
+
+		result, err := server.HandleMigrateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":            float64(1),
+			"environmentId": float64(3),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "stack created on the target environment with ID 9, but the source stack could not be deleted")
+	})
+
+	t.Run("same environment and name", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, models.StackKindRegular).Return(source, nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleMigrateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":            float64(1),
+			"environmentId": float64(2),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "stack 1 is already named web on environment 2")
+	})
+}
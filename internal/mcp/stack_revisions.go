@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/revisions"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleListStackRevisions() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.revisions == nil {
+			return mcp.NewToolResultError("stack revisions are not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		includeFile, err := parser.GetBoolean("includeFile", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid includeFile parameter", err), nil
+		}
+
+		if kind == "" {
+			if kind, err = s.resolveStackKind(id, ""); err != nil {
+				return newToolResultAPIError("failed to get the kind of the stack", err), nil
+			}
+		}
+
+		// The current state of the stack is recorded first, so that changes made outside of the MCP server are listed.
+		// This is best-effort: the revisions of a deleted stack are still listed.
+		_ = s.observeStackRevision(id, kind)
+
+		list, err := s.revisions.List(kind, id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list stack revisions", err), nil
+		}
+
+		if !includeFile {
+			for i := range list {
+				list[i].File = ""
+			}
+		}
+
+		data, err := json.Marshal(list)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack revisions", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleRollbackStack() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.revisions == nil {
+			return mcp.NewToolResultError("stack revisions are not enabled"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		number, err := parser.GetInt("revision", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid revision parameter", err), nil
+		}
+
+		kind, err := parseStackKind(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		stack, err := s.cli.GetStack(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack", err), nil
+		}
+		kind = regularOrEdge(stack.Kind)
+
+		currentNames, err := s.stackEnvNames(id, kind)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack env names", err), nil
+		}
+
+		revision, ok, err := s.revisions.Get(kind, id, number)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack revision", err), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("revision %d of %s stack %d not found", number, kind, id)), nil
+		}
+
+		// The state replaced by the rollback is kept, so that the rollback can itself be rolled back
+		observed := s.observeStackWarning(id, kind)
+
+		change := s.captureChange(stackChangeKind(kind), id)
+
+		err = s.cli.UpdateStack(id, kind, revision.File, stack.EnvironmentGroupIds, nil)
+		if err != nil {
+			return newToolResultAPIError("failed to rollback stack", err), nil
+		}
+
+		result := fmt.Sprintf("Stack rolled back to revision %d successfully", number) +
+			observed +
+			s.recordChange(ToolRollbackStack, change) +
+			s.recordStackRevision(ToolRollbackStack, id, kind)
+
+		var missing, kept []string
+		for _, name := range revision.EnvNames {
+			if !slices.Contains(currentNames, name) {
+				missing = append(missing, name)
+			}
+		}
+		for _, name := range currentNames {
+			if !slices.Contains(revision.EnvNames, name) {
+				kept = append(kept, name)
+			}
+		}
+		if len(missing) > 0 {
+			result += fmt.Sprintf("\n\nThe environment variables %s of the revision are not set on the stack anymore. Revisions do not store values, set them with updateStack envOverrides.", strings.Join(missing, ", "))
+		}
+		if len(kept) > 0 {
+			result += fmt.Sprintf("\n\nThe environment variables %s were not part of the revision and have been kept.", strings.Join(kept, ", "))
+		}
+
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// observeStackRevision records the current file and environment variable names of a stack of the given kind
+// as an observed revision. It does nothing if stack revisions are disabled.
+func (s *PortainerMCPServer) observeStackRevision(id int, kind string) error {
+	if s.revisions == nil {
+		return nil
+	}
+
+	file, err := s.cli.GetStackFile(id, kind)
+	if err != nil {
+		return err
+	}
+
+	return s.observeStackFile(id, kind, file)
+}
+
+// observeStackWarning records the state of a stack before a write as an observed revision, so that the write can
+// be rolled back. Observing is best-effort: it returns a warning to append to the tool result if it failed, and an
+// empty string otherwise.
+func (s *PortainerMCPServer) observeStackWarning(id int, kind string) string {
+	if err := s.observeStackRevision(id, kind); err != nil {
+		return fmt.Sprintf(" (warning: the previous state of the stack was not saved as a revision: %v)", err)
+	}
+	return ""
+}
+
+// observeStackFile records a stack file that has just been read as an observed revision, along with the
+// environment variable names of the stack. It does nothing if stack revisions are disabled.
+func (s *PortainerMCPServer) observeStackFile(id int, kind string, file string) error {
+	if s.revisions == nil {
+		return nil
+	}
+
+	names, err := s.stackEnvNames(id, kind)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.revisions.Record(kind, id, file, names, revisions.SourceObserved, "")
+	return err
+}
+
+// recordStackRevision records the state of a stack of the given kind after a write as a new revision.
+// It returns a note to append to the tool result, which is empty if stack revisions are disabled.
+func (s *PortainerMCPServer) recordStackRevision(tool string, id int, kind string) string {
+	if s.revisions == nil {
+		return ""
+	}
+
+	file, err := s.cli.GetStackFile(id, kind)
+	if err != nil {
+		return fmt.Sprintf(" (warning: stack revision not saved: %v)", err)
+	}
+
+	names, err := s.stackEnvNames(id, kind)
+	if err != nil {
+		return fmt.Sprintf(" (warning: stack revision not saved: %v)", err)
+	}
+
+	revision, recorded, err := s.revisions.Record(kind, id, file, names, revisions.SourceWrite, tool)
+	if err != nil {
+		return fmt.Sprintf(" (warning: stack revision not saved: %v)", err)
+	}
+	if !recorded {
+		return fmt.Sprintf(" (stack unchanged since revision %d)", revision.Number)
+	}
+
+	return fmt.Sprintf(" (stack revision %d saved)", revision.Number)
+}
+
+// stackEnvNames returns the environment variable names of a stack of the given kind.
+// Edge stacks have no environment variables.
+func (s *PortainerMCPServer) stackEnvNames(id int, kind string) ([]string, error) {
+	if kind == models.StackKindEdge {
+		return []string{}, nil
+	}
+	return s.cli.GetStackEnvNames(id)
+}
@@ -0,0 +1,234 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/internal/revisions"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	revisionFileV1 = "services:\n  web:\n    image: nginx:1.26\n"
+	revisionFileV2 = "services:\n  web:\n    image: nginx:1.27\n"
+)
+
+func newTestRevisionStore(t *testing.T) *revisions.Store {
+	t.Helper()
+
+	store, err := revisions.Open("", "")
+	require.NoError(t, err)
+	return store
+}
+
+func TestHandleListStackRevisions(t *testing.T) {
+	t.Run("records the observed state", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindRegular, 1, revisionFileV1, []string{"TOKEN"}, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return(revisionFileV2, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"TOKEN"}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleListStackRevisions()(context.Background(), CreateMCPRequest(map[string]any{
+			"id": float64(1),
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		var list []revisions.Revision
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &list))
+		require.Len(t, list, 2)
+		assert.Equal(t, 2, list[0].Number)
+		assert.Equal(t, revisions.SourceObserved, list[0].Source)
+		assert.Empty(t, list[0].File, "files must only be returned with includeFile")
+		assert.Equal(t, ToolCreateStack, list[1].Tool)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("edge stack with files", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 2, "").Return(models.Stack{ID: 2, Kind: models.StackKindEdge}, nil)
+		mockClient.On("GetStackFile", 2, models.StackKindEdge).Return(revisionFileV1, nil)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: newTestRevisionStore(t)}
+
+		result, err := server.HandleListStackRevisions()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":          float64(2),
+			"includeFile": true,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		var list []revisions.Revision
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &list))
+		require.Len(t, list, 1)
+		assert.Equal(t, revisionFileV1, list[0].File)
+		assert.Equal(t, models.StackKindEdge, list[0].Kind)
+		assert.Empty(t, list[0].EnvNames)
+	})
+
+	t.Run("kind of a deleted stack", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindEdge, 3, revisionFileV1, nil, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+		_, _, err = store.Record(models.StackKindRegular, 3, revisionFileV2, nil, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStackFile", 3, models.StackKindEdge).Return("", client.ErrNotFound)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleListStackRevisions()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":          float64(3),
+			"kind":        models.StackKindEdge,
+			"includeFile": true,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		var list []revisions.Revision
+		require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &list))
+		require.Len(t, list, 1, "the revisions of the regular stack with the same ID must not be listed")
+		assert.Equal(t, revisionFileV1, list[0].File)
+	})
+
+	t.Run("deleted stack without kind", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 3, "").Return(models.Stack{}, client.ErrNotFound)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: newTestRevisionStore(t)}
+
+		result, err := server.HandleListStackRevisions()(context.Background(), CreateMCPRequest(map[string]any{
+			"id": float64(3),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "failed to get the kind of the stack")
+	})
+
+	t.Run("revisions disabled", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+
+		result, err := server.HandleListStackRevisions()(context.Background(), CreateMCPRequest(map[string]any{
+			"id": float64(1),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "stack revisions are not enabled")
+	})
+}
+
+func TestHandleRollbackStack(t *testing.T) {
+	t.Run("re-applies the revision file", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindRegular, 1, revisionFileV1, []string{"TOKEN", "OLD"}, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+		_, _, err = store.Record(models.StackKindRegular, 1, revisionFileV2, []string{"TOKEN"}, revisions.SourceWrite, ToolUpdateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose, EnvironmentGroupIds: []int{}}, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"TOKEN", "NEW"}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return(revisionFileV2, nil).Once()
+		mockClient.On("UpdateStack", 1, models.StackKindRegular, revisionFileV1, []int{}, []models.StackEnvVar(nil)).Return(nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return(revisionFileV1, nil).Once()
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleRollbackStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":       float64(1),
+			"revision": float64(1),
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+
+		text := resultText(t, result)
+		assert.Contains(t, text, "Stack rolled back to revision 1 successfully")
+		assert.Contains(t, text, "(stack revision 4 saved)")
+		assert.Contains(t, text, "The environment variables OLD of the revision are not set on the stack anymore")
+		assert.Contains(t, text, "The environment variables NEW were not part of the revision and have been kept")
+
+		list, err := store.List(models.StackKindRegular, 1)
+		require.NoError(t, err)
+		require.Len(t, list, 4)
+		assert.Equal(t, ToolRollbackStack, list[0].Tool)
+		assert.Equal(t, revisionFileV1, list[0].File)
+		assert.Equal(t, revisions.SourceObserved, list[1].Source, "the replaced state must be saved before the rollback")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("edge stack sharing the ID of a regular stack", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindEdge, 1, revisionFileV1, nil, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, models.StackKindEdge).Return(models.Stack{ID: 1, Kind: models.StackKindEdge, EnvironmentGroupIds: []int{2}}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindEdge).Return(revisionFileV2, nil).Once()
+		mockClient.On("UpdateStack", 1, models.StackKindEdge, revisionFileV1, []int{2}, []models.StackEnvVar(nil)).Return(nil)
+		mockClient.On("GetStackFile", 1, models.StackKindEdge).Return(revisionFileV1, nil).Once()
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleRollbackStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":       float64(1),
+			"revision": float64(1),
+			"kind":     models.StackKindEdge,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindEdge, 1, revisionFileV1, nil, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleRollbackStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":       float64(1),
+			"revision": float64(1),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "revision 1 of regular stack 1 not found", "the revisions of the edge stack with the same ID must not be used")
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		store := newTestRevisionStore(t)
+		_, _, err := store.Record(models.StackKindRegular, 1, revisionFileV1, []string{}, revisions.SourceWrite, ToolCreateStack)
+		require.NoError(t, err)
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{}, nil)
+		mockClient.On("GetStackFile", 1, models.StackKindRegular).Return(revisionFileV2, nil)
+		mockClient.On("UpdateStack", 1, models.StackKindRegular, revisionFileV1, []int(nil), []models.StackEnvVar(nil)).Return(assert.AnError)
+
+		server := &PortainerMCPServer{cli: mockClient, revisions: store}
+
+		result, err := server.HandleRollbackStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":       float64(1),
+			"revision": float64(1),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "failed to rollback stack")
+	})
+}
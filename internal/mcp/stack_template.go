@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/templates"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleListStackTemplates() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var result []templates.Template
+		if s.templates != nil {
+			result = append(result, s.templates.List()...)
+		}
+
+		customTemplates, err := s.cli.GetCustomTemplates()
+		if err != nil {
+			return newToolResultAPIError("failed to get custom templates", err), nil
+		}
+		for _, customTemplate := range customTemplates {
+			if customTemplate.Type != models.StackTypeStandalone && customTemplate.Type != models.StackTypeSwarm {
+				continue
+			}
+			result = append(result, templates.FromCustomTemplate(customTemplate, ""))
+		}
+
+		for i := range result {
+			result[i].File = ""
+		}
+		if result == nil {
+			result = []templates.Template{}
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack templates", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleCreateStackTemplate() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		var t templates.Template
+		var err error
+
+		if t.Name, err = parser.GetString("name", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if t.Description, err = parser.GetString("description", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid description parameter", err), nil
+		}
+		if t.File, err = parser.GetString("file", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+		}
+		if t.Type, err = parser.GetString("type", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid type parameter", err), nil
+		}
+		if t.Type == "" {
+			t.Type = models.StackTypeStandalone
+		}
+
+		variablesRaw, err := parser.GetArrayOfObjects("variables", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid variables parameter", err), nil
+		}
+		if t.Variables, err = parseTemplateVariables(variablesRaw); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid variables parameter", err), nil
+		}
+
+		storage, err := parser.GetString("storage", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid storage parameter", err), nil
+		}
+		if storage == "" {
+			storage = templates.SourceLocal
+		}
+
+		if err := templates.Check(t); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stack template", err), nil
+		}
+
+		switch storage {
+		case templates.SourceLocal:
+			if s.templates == nil {
+				return mcp.NewToolResultError("local stack templates are not enabled"), nil
+			}
+			if t, err = s.templates.Save(t); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to save stack template", err), nil
+			}
+		case templates.SourcePortainer:
+			id, err := s.cli.CreateCustomTemplate(templates.ToCustomTemplate(t))
+			if err != nil {
+				return newToolResultAPIError("failed to create custom template", err), nil
+			}
+			t.ID = templates.PortainerID(id)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid storage parameter: %s", storage)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack template registered successfully with ID: %s", t.ID)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleDeployFromTemplate() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		templateID, err := parser.GetString("templateId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid templateId parameter", err), nil
+		}
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		environmentID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		variablesRaw, err := parser.GetArrayOfObjects("variables", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid variables parameter", err), nil
+		}
+		values, err := parseTemplateValues(variablesRaw)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid variables parameter", err), nil
+		}
+
+		skipValidation, err := parser.GetBoolean("skipValidation", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid skipValidation parameter", err), nil
+		}
+
+		t, failed := s.getStackTemplate(templateID)
+		if failed != nil {
+			return failed, nil
+		}
+
+		file, err := templates.Render(t, values)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to render stack template", err), nil
+		}
+
+		environment, err := s.cli.GetEnvironment(environmentID)
+		if err != nil {
+			return newToolResultAPIError("failed to get environment", err), nil
+		}
+
+		kind := models.StackKindCompose
+		if t.Type == models.StackTypeSwarm {
+			kind = models.StackKindSwarm
+		}
+		stackType, ok := stackTypeForPlatform(kind, environment.Platform)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("a %s stack cannot be deployed on environment %d, which is a %s environment", kind, environmentID, environment.Platform)), nil
+		}
+
+		var warnings string
+		if !skipValidation {
+			var failed *mcp.CallToolResult
+			if failed, warnings = checkStackFile(file, []string{}); failed != nil {
+				return failed, nil
+			}
+		}
+
+		id, err := s.cli.CreateStack(models.StackCreateOptions{
+			Name:          name,
+			File:          file,
+			EnvironmentID: environmentID,
+			Type:          stackType,
+		})
+		if err != nil {
+			return newToolResultAPIError("error creating stack", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack created successfully from template %s with ID: %d", t.ID, id) + s.recordStackRevision(ToolDeployFromTemplate, id, models.StackKindRegular) + warnings), nil
+	}
+}
+
+// getStackTemplate returns a local template or a Portainer custom template, with its file
+func (s *PortainerMCPServer) getStackTemplate(templateID string) (templates.Template, *mcp.CallToolResult) {
+	source, ref, err := templates.ParseID(templateID)
+	if err != nil {
+		return templates.Template{}, mcp.NewToolResultErrorFromErr("invalid templateId parameter", err)
+	}
+
+	if source == templates.SourceLocal {
+		if s.templates == nil {
+			return templates.Template{}, mcp.NewToolResultError("local stack templates are not enabled")
+		}
+		t, ok := s.templates.Get(ref)
+		if !ok {
+			return templates.Template{}, mcp.NewToolResultError(fmt.Sprintf("stack template %s not found", templateID))
+		}
+		return t, nil
+	}
+
+	id, _ := strconv.Atoi(ref)
+	customTemplates, err := s.cli.GetCustomTemplates()
+	if err != nil {
+		return templates.Template{}, newToolResultAPIError("failed to get custom templates", err)
+	}
+	for _, customTemplate := range customTemplates {
+		if customTemplate.ID != id {
+			continue
+		}
+		if customTemplate.Type != models.StackTypeStandalone && customTemplate.Type != models.StackTypeSwarm {
+			return templates.Template{}, mcp.NewToolResultError(fmt.Sprintf("custom template %d is a %s template, only compose and swarm templates can be deployed", id, customTemplate.Type))
+		}
+
+		file, err := s.cli.GetCustomTemplateFile(id)
+		if err != nil {
+			return templates.Template{}, newToolResultAPIError("failed to get custom template file", err)
+		}
+		return templates.FromCustomTemplate(customTemplate, file), nil
+	}
+
+	return templates.Template{}, mcp.NewToolResultError(fmt.Sprintf("stack template %s not found", templateID))
+}
+
+// parseTemplateVariables parses the variables declared by a template
+func parseTemplateVariables(entries []any) ([]templates.Variable, error) {
+	variables := make([]templates.Variable, 0, len(entries))
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid variable: %v", entry)
+		}
+
+		name, ok := entryMap["name"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable name: %v", entryMap["name"])
+		}
+
+		variable := templates.Variable{Name: name, Type: templates.TypeString}
+		if variableType, ok := entryMap["type"].(string); ok && variableType != "" {
+			variable.Type = variableType
+		}
+		if description, ok := entryMap["description"].(string); ok {
+			variable.Description = description
+		}
+		if defaultValue, ok := entryMap["default"]; ok && defaultValue != nil {
+			variable.Default = templateValueString(defaultValue)
+		}
+
+		variables = append(variables, variable)
+	}
+
+	return variables, nil
+}
+
+// parseTemplateValues parses the values of the variables of a template to deploy
+func parseTemplateValues(entries []any) (map[string]string, error) {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid variable: %v", entry)
+		}
+
+		name, ok := entryMap["name"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable name: %v", entryMap["name"])
+		}
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("variable %s is given more than once", name)
+		}
+
+		value, ok := entryMap["value"]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("missing value of variable %s", name)
+		}
+		values[name] = templateValueString(value)
+	}
+
+	return values, nil
+}
+
+// templateValueString returns the string form of a variable value, which may be passed as a JSON number or boolean
+func templateValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
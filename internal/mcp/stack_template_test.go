@@ -0,0 +1,258 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/internal/templates"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplateFile = "services:\n  web:\n    image: {{ image }}\n    ports:\n      - \"{{ port }}:80\"\n"
+
+func newTestTemplateStore(t *testing.T) *templates.Store {
+	t.Helper()
+
+	store, err := templates.Open("")
+	require.NoError(t, err)
+	return store
+}
+
+func TestHandleCreateStackTemplate(t *testing.T) {
+	params := map[string]any{
+		"name":        "web",
+		"description": "Web server",
+		"file":        testTemplateFile,
+		"variables": []any{
+			map[string]any{"name": "image", "type": "image", "default": "nginx:1.27"},
+			map[string]any{"name": "port", "type": "port", "description": "Published port"},
+		},
+	}
+
+	t.Run("local", func(t *testing.T) {
+		store := newTestTemplateStore(t)
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}, templates: store}
+
+		result, err := server.HandleCreateStackTemplate()(context.Background(), CreateMCPRequest(params))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Equal(t, "Stack template registered successfully with ID: local:web", resultText(t, result))
+
+		saved, ok := store.Get("web")
+		require.True(t, ok)
+		assert.Equal(t, models.StackTypeStandalone, saved.Type)
+		assert.Equal(t, []templates.Variable{
+			{Name: "image", Type: templates.TypeImage, Default: "nginx:1.27"},
+			{Name: "port", Type: templates.TypePort, Description: "Published port"},
+		}, saved.Variables)
+	})
+
+	t.Run("portainer", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("CreateCustomTemplate", models.CustomTemplateCreateOptions{
+			Title:       "web",
+			Description: "Web server",
+			Type:        models.StackTypeStandalone,
+			File:        testTemplateFile,
+			Variables: []models.CustomTemplateVariable{
+				{Name: "image", Label: "image", DefaultValue: "nginx:1.27", Description: "[image]"},
+				{Name: "port", Label: "port", Description: "[port] Published port"},
+			},
+		}).Return(12, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		portainerParams := map[string]any{"storage": "portainer"}
+		for key, value := range params {
+			portainerParams[key] = value
+		}
+
+		result, err := server.HandleCreateStackTemplate()(context.Background(), CreateMCPRequest(portainerParams))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Equal(t, "Stack template registered successfully with ID: portainer:12", resultText(t, result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("undeclared variable", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}, templates: newTestTemplateStore(t)}
+
+		result, err := server.HandleCreateStackTemplate()(context.Background(), CreateMCPRequest(map[string]any{
+			"name": "web",
+			"file": testTemplateFile,
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "the template file uses the undeclared variable image")
+	})
+}
+
+func TestHandleListStackTemplates(t *testing.T) {
+	store := newTestTemplateStore(t)
+	_, err := store.Save(templates.Template{
+		Name:      "web",
+		Type:      models.StackTypeStandalone,
+		Variables: []templates.Variable{{Name: "port", Type: templates.TypePort, Default: "8080"}},
+		File:      "services: {}",
+	})
+	require.NoError(t, err)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetCustomTemplates").Return([]models.CustomTemplate{
+		{ID: 4, Title: "db", Type: models.StackTypeSwarm, Variables: []models.CustomTemplateVariable{{Name: "replicas", DefaultValue: "1", Description: "[int] Replicas"}}},
+		{ID: 5, Title: "app", Type: models.StackKindKubernetes},
+	}, nil)
+
+	server := &PortainerMCPServer{cli: mockClient, templates: store}
+
+	result, err := server.HandleListStackTemplates()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var listed []templates.Template
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &listed))
+	assert.Equal(t, []templates.Template{
+		{
+			ID:        "local:web",
+			Name:      "web",
+			Source:    templates.SourceLocal,
+			Type:      models.StackTypeStandalone,
+			Variables: []templates.Variable{{Name: "port", Type: templates.TypePort, Default: "8080"}},
+		},
+		{
+			ID:        "portainer:4",
+			Name:      "db",
+			Source:    templates.SourcePortainer,
+			Type:      models.StackTypeSwarm,
+			Variables: []templates.Variable{{Name: "replicas", Type: templates.TypeInt, Default: "1", Description: "Replicas"}},
+		},
+	}, listed)
+}
+
+func TestHandleDeployFromTemplate(t *testing.T) {
+	localTemplate := templates.Template{
+		Name: "web",
+		Type: models.StackTypeStandalone,
+		Variables: []templates.Variable{
+			{Name: "image", Type: templates.TypeImage, Default: "nginx:1.27"},
+			{Name: "port", Type: templates.TypePort},
+		},
+		File: testTemplateFile,
+	}
+	rendered := "services:\n  web:\n    image: nginx:1.27\n    ports:\n      - \"9000:80\"\n"
+
+	tests := []struct {
+		name         string
+		params       map[string]any
+		platform     string
+		expectCreate *models.StackCreateOptions
+		expectError  string
+	}{
+		{
+			name: "local template",
+			params: map[string]any{
+				"templateId": "local:web", "name": "web-9000", "environmentId": float64(3),
+				"variables": []any{map[string]any{"name": "port", "value": float64(9000)}},
+			},
+			platform: models.EnvironmentPlatformDockerStandalone,
+			expectCreate: &models.StackCreateOptions{
+				Name: "web-9000", File: rendered, EnvironmentID: 3, Type: models.StackTypeStandalone,
+			},
+		},
+		{
+			name: "portainer template",
+			params: map[string]any{
+				"templateId": "portainer:4", "name": "db", "environmentId": float64(3),
+				"variables": []any{map[string]any{"name": "port", "value": "9000"}},
+			},
+			platform: models.EnvironmentPlatformDockerSwarm,
+			expectCreate: &models.StackCreateOptions{
+				Name: "db", File: rendered, EnvironmentID: 3, Type: models.StackTypeSwarm,
+			},
+		},
+		{
+			name: "missing required value",
+			params: map[string]any{
+				"templateId": "local:web", "name": "web", "environmentId": float64(3),
+			},
+			platform:    models.EnvironmentPlatformDockerStandalone,
+			expectError: "a value is required for variable port",
+		},
+		{
+			name: "invalid value",
+			params: map[string]any{
+				"templateId": "local:web", "name": "web", "environmentId": float64(3),
+				"variables": []any{map[string]any{"name": "port", "value": "http"}},
+			},
+			platform:    models.EnvironmentPlatformDockerStandalone,
+			expectError: "the value of variable port must be a port between 1 and 65535",
+		},
+		{
+			name: "environment platform mismatch",
+			params: map[string]any{
+				"templateId": "local:web", "name": "web", "environmentId": float64(3),
+				"variables": []any{map[string]any{"name": "port", "value": "9000"}},
+			},
+			platform:    models.EnvironmentPlatformKubernetes,
+			expectError: "a compose stack cannot be deployed on environment 3, which is a kubernetes environment",
+		},
+		{
+			name: "unknown template",
+			params: map[string]any{
+				"templateId": "local:api", "name": "api", "environmentId": float64(3),
+			},
+			expectError: "stack template local:api not found",
+		},
+		{
+			name: "invalid template ID",
+			params: map[string]any{
+				"templateId": "web", "name": "web", "environmentId": float64(3),
+			},
+			expectError: "invalid template ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestTemplateStore(t)
+			_, err := store.Save(localTemplate)
+			require.NoError(t, err)
+
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetCustomTemplates").Return([]models.CustomTemplate{{
+				ID:    4,
+				Title: "db",
+				Type:  models.StackTypeSwarm,
+				Variables: []models.CustomTemplateVariable{
+					{Name: "image", DefaultValue: "nginx:1.27", Description: "[image]"},
+					{Name: "port", Description: "[port]"},
+				},
+			}}, nil)
+			mockClient.On("GetCustomTemplateFile", 4).Return(testTemplateFile, nil)
+			mockClient.On("GetEnvironment", 3).Return(models.EnvironmentDetails{Environment: models.Environment{ID: 3}, Platform: tt.platform}, nil)
+			if tt.expectCreate != nil {
+				mockClient.On("CreateStack", *tt.expectCreate).Return(21, nil)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient, templates: store}
+
+			result, err := server.HandleDeployFromTemplate()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, resultText(t, result), tt.expectError)
+				mockClient.AssertNotCalled(t, "CreateStack", mock.Anything)
+				return
+			}
+
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Contains(t, resultText(t, result), "Stack created successfully from template "+tt.params["templateId"].(string)+" with ID: 21")
+			mockClient.AssertCalled(t, "CreateStack", *tt.expectCreate)
+		})
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandleGetStacks(t *testing.T) {
@@ -76,6 +80,75 @@ func TestHandleGetStacks(t *testing.T) {
 	}
 }
 
+func TestHandleGetStack(t *testing.T) {
+	stack := models.Stack{
+		ID:            3,
+		Name:          "web",
+		Kind:          models.StackKindCompose,
+		Status:        models.StackStatusRunning,
+		EnvironmentID: 2,
+		Git:           &models.StackGitConfig{RepositoryURL: "https://git.example.com/ops/stacks.git"},
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStack", 3, "").Return(stack, nil)
+	mockClient.On("GetStack", 4, models.StackKindEdge).Return(models.Stack{}, fmt.Errorf("stack 4 not found"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetStack()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(3)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var got models.Stack
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &got))
+	assert.Equal(t, stack, got)
+
+	result, err = server.HandleGetStack()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(4), "kind": models.StackKindEdge}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "stack 4 not found")
+
+	result, err = server.HandleGetStack()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleGetEdgeStackStatus(t *testing.T) {
+	status := models.EdgeStackStatus{
+		StackID: 5,
+		Name:    "edge",
+		Summary: models.EdgeStackStatusSummary{Total: 2, Running: 1, Error: 1},
+		Environments: []models.EdgeStackEnvironmentStatus{
+			{EnvironmentID: 1, Status: models.EdgeStackStatusRunning},
+			{EnvironmentID: 2, Status: models.EdgeStackStatusError, Error: "pull access denied"},
+		},
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEdgeStackStatus", 5).Return(status, nil)
+	mockClient.On("GetEdgeStackStatus", 6).Return(models.EdgeStackStatus{}, fmt.Errorf("stack 6 not found"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetEdgeStackStatus()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(5)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var got models.EdgeStackStatus
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &got))
+	assert.Equal(t, status, got)
+
+	result, err = server.HandleGetEdgeStackStatus()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(6)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "stack 6 not found")
+
+	mockClient.AssertExpectations(t)
+}
+
 func TestHandleGetStackFile(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -125,7 +198,8 @@ func TestHandleGetStackFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
 			if !tt.expectError || tt.mockError != nil {
-				mockClient.On("GetStackFile", tt.inputID).Return(tt.mockContent, tt.mockError)
+				mockClient.On("GetStack", tt.inputID, "").Return(models.Stack{ID: tt.inputID, Kind: models.StackKindCompose}, nil)
+				mockClient.On("GetStackFile", tt.inputID, models.StackKindRegular).Return(tt.mockContent, tt.mockError)
 			}
 
 			server := &PortainerMCPServer{
@@ -255,129 +329,150 @@ func TestHandleGetStackEnvNames(t *testing.T) {
 }
 
 func TestHandleCreateStack(t *testing.T) {
+	stackFile := "version: '3'\nservices:\n  web:\n    image: nginx"
+
 	tests := []struct {
-		name             string
-		inputName        string
-		inputFile        string
-		inputEnvGroupIDs []int
-		mockID           int
-		mockError        error
-		expectError      bool
-		setupParams      func(request *mcp.CallToolRequest)
+		name         string
+		args         map[string]any
+		expectedOpts *models.StackCreateOptions
+		mockID       int
+		mockError    error
+		expectError  bool
 	}{
 		{
-			name:             "successful stack creation",
-			inputName:        "test-stack",
-			inputFile:        "version: '3'\nservices:\n  web:\n    image: nginx",
-			inputEnvGroupIDs: []int{1, 2},
-			mockID:           1,
-			mockError:        nil,
-			expectError:      false,
-			setupParams: func(request *mcp.CallToolRequest) {
-				request.Params.Arguments = map[string]any{
-					"name":                "test-stack",
-					"file":                "version: '3'\nservices:\n  web:\n    image: nginx",
-					"environmentGroupIds": []any{float64(1), float64(2)},
-				}
+			name: "edge stack creation",
+			args: map[string]any{
+				"name":                "test-stack",
+				"file":                stackFile,
+				"environmentGroupIds": []any{float64(1), float64(2)},
 			},
+			expectedOpts: &models.StackCreateOptions{
+				Name:                "test-stack",
+				File:                stackFile,
+				EnvironmentGroupIds: []int{1, 2},
+			},
+			mockID: 1,
 		},
 		{
-			name:             "api error",
-			inputName:        "test-stack",
-			inputFile:        "version: '3'\nservices:\n  web:\n    image: nginx",
-			inputEnvGroupIDs: []int{1, 2},
-			mockID:           0,
-			mockError:        fmt.Errorf("api error"),
-			expectError:      true,
-			setupParams: func(request *mcp.CallToolRequest) {
-				request.Params.Arguments = map[string]any{
-					"name":                "test-stack",
-					"file":                "version: '3'\nservices:\n  web:\n    image: nginx",
-					"environmentGroupIds": []any{float64(1), float64(2)},
-				}
+			name: "regular stack creation",
+			args: map[string]any{
+				"name":          "test-stack",
+				"file":          stackFile,
+				"environmentId": float64(3),
+				"type":          models.StackTypeSwarm,
+				"env":           []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+			},
+			expectedOpts: &models.StackCreateOptions{
+				Name:                "test-stack",
+				File:                stackFile,
+				EnvironmentID:       3,
+				Type:                models.StackTypeSwarm,
+				EnvironmentGroupIds: []int{},
+				Env:                 []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
 			},
+			mockID: 4,
 		},
 		{
-			name:             "missing name parameter",
-			inputName:        "",
-			inputFile:        "version: '3'\nservices:\n  web:\n    image: nginx",
-			inputEnvGroupIDs: []int{1, 2},
-			mockID:           0,
-			mockError:        nil,
-			expectError:      true,
-			setupParams: func(request *mcp.CallToolRequest) {
-				request.Params.Arguments = map[string]any{
-					"file":                "version: '3'\nservices:\n  web:\n    image: nginx",
-					"environmentGroupIds": []any{float64(1), float64(2)},
-				}
+			name: "api error",
+			args: map[string]any{
+				"name":          "test-stack",
+				"file":          stackFile,
+				"environmentId": float64(3),
+			},
+			expectedOpts: &models.StackCreateOptions{
+				Name:                "test-stack",
+				File:                stackFile,
+				EnvironmentID:       3,
+				EnvironmentGroupIds: []int{},
 			},
+			mockError:   fmt.Errorf("api error"),
+			expectError: true,
 		},
 		{
-			name:             "missing file parameter",
-			inputName:        "test-stack",
-			inputFile:        "",
-			inputEnvGroupIDs: []int{1, 2},
-			mockID:           0,
-			mockError:        nil,
-			expectError:      true,
-			setupParams: func(request *mcp.CallToolRequest) {
-				request.Params.Arguments = map[string]any{
-					"name":                "test-stack",
-					"environmentGroupIds": []any{float64(1), float64(2)},
-				}
+			name: "missing name parameter",
+			args: map[string]any{
+				"file":                stackFile,
+				"environmentGroupIds": []any{float64(1), float64(2)},
 			},
+			expectError: true,
 		},
 		{
-			name:             "missing environmentGroupIds parameter",
-			inputName:        "test-stack",
-			inputFile:        "version: '3'\nservices:\n  web:\n    image: nginx",
-			inputEnvGroupIDs: nil,
-			mockID:           0,
-			mockError:        nil,
-			expectError:      true,
-			setupParams: func(request *mcp.CallToolRequest) {
-				request.Params.Arguments = map[string]any{
-					"name": "test-stack",
-					"file": "version: '3'\nservices:\n  web:\n    image: nginx",
-				}
+			name: "missing file parameter",
+			args: map[string]any{
+				"name":                "test-stack",
+				"environmentGroupIds": []any{float64(1), float64(2)},
 			},
+			expectError: true,
+		},
+		{
+			name: "missing target",
+			args: map[string]any{
+				"name": "test-stack",
+				"file": stackFile,
+			},
+			expectError: true,
+		},
+		{
+			name: "both environment and environment groups",
+			args: map[string]any{
+				"name":                "test-stack",
+				"file":                stackFile,
+				"environmentId":       float64(3),
+				"environmentGroupIds": []any{float64(1)},
+			},
+			expectError: true,
+		},
+		{
+			name: "env on edge stack",
+			args: map[string]any{
+				"name":                "test-stack",
+				"file":                stackFile,
+				"environmentGroupIds": []any{float64(1)},
+				"env":                 []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid type",
+			args: map[string]any{
+				"name":          "test-stack",
+				"file":          stackFile,
+				"environmentId": float64(3),
+				"type":          "kubernetes",
+			},
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
-			if !tt.expectError || tt.mockError != nil {
-				mockClient.On("CreateStack", tt.inputName, tt.inputFile, tt.inputEnvGroupIDs).Return(tt.mockID, tt.mockError)
+			if tt.expectedOpts != nil {
+				mockClient.On("CreateStack", *tt.expectedOpts).Return(tt.mockID, tt.mockError)
 			}
 
 			server := &PortainerMCPServer{
 				cli: mockClient,
 			}
 
-			request := CreateMCPRequest(map[string]any{})
-			tt.setupParams(&request)
-
 			handler := server.HandleCreateStack()
-			result, err := handler(context.Background(), request)
+			result, err := handler(context.Background(), CreateMCPRequest(tt.args))
+
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+			assert.Len(t, result.Content, 1)
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
 
 			if tt.expectError {
-				assert.NoError(t, err)
-				assert.NotNil(t, result)
 				assert.True(t, result.IsError, "result.IsError should be true for expected errors")
-				assert.Len(t, result.Content, 1)
-				textContent, ok := result.Content[0].(mcp.TextContent)
-				assert.True(t, ok, "Result content should be mcp.TextContent for errors")
 				if tt.mockError != nil {
 					assert.Contains(t, textContent.Text, tt.mockError.Error())
 				} else {
 					assert.NotEmpty(t, textContent.Text, "Error message should not be empty for parameter errors")
 				}
 			} else {
-				assert.NoError(t, err)
-				assert.Len(t, result.Content, 1)
-				textContent, ok := result.Content[0].(mcp.TextContent)
-				assert.True(t, ok)
+				assert.False(t, result.IsError)
 				assert.Contains(t, textContent.Text, fmt.Sprintf("ID: %d", tt.mockID))
 			}
 
@@ -502,7 +597,9 @@ func TestHandleUpdateStack(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
 			if !tt.expectError || tt.mockError != nil {
-				mockClient.On("UpdateStack", tt.inputID, tt.inputFile, tt.inputEnvGroupIDs, tt.inputOverrides).Return(tt.mockError)
+				mockClient.On("GetStack", tt.inputID, "").Return(models.Stack{ID: tt.inputID, Kind: models.StackKindCompose}, nil)
+				mockClient.On("GetStackEnvNames", tt.inputID).Return([]string{}, nil)
+				mockClient.On("UpdateStack", tt.inputID, models.StackKindRegular, tt.inputFile, tt.inputEnvGroupIDs, tt.inputOverrides).Return(tt.mockError)
 			}
 
 			server := &PortainerMCPServer{
@@ -539,3 +636,99 @@ func TestHandleUpdateStack(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleUpdateStackKind(t *testing.T) {
+	file := "services:\n  web:\n    image: nginx:1.27\n"
+
+	t.Run("edge stack sharing the ID of a regular stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, models.StackKindEdge).Return(models.Stack{ID: 1, Kind: models.StackKindEdge}, nil)
+		mockClient.On("UpdateStack", 1, models.StackKindEdge, file, []int{2}, []models.StackEnvVar{}).Return(nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":                  float64(1),
+			"kind":                models.StackKindEdge,
+			"file":                file,
+			"environmentGroupIds": []any{float64(2)},
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "GetStackEnvNames", 1)
+	})
+
+	t.Run("ambiguous ID", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{}, client.ErrAmbiguousStackID)
+
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":                  float64(1),
+			"file":                file,
+			"environmentGroupIds": []any{},
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		mockClient.AssertNotCalled(t, "UpdateStack", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleStackLifecycle(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("StartStack", 1, "").Return(nil)
+	mockClient.On("StopStack", 1, "").Return(nil)
+	mockClient.On("RedeployStack", 1, "", models.StackRedeployOptions{PullImage: true, Prune: true}).Return(nil)
+	mockClient.On("DeleteStack", 1, "", true).Return(nil)
+	mockClient.On("DeleteStack", 3, models.StackKindEdge, false).Return(nil)
+	mockClient.On("StopStack", 5, "").Return(fmt.Errorf("failed to stop stack 5: operation not supported for edge stacks"))
+	mockClient.On("StartStack", 4, "").Return(fmt.Errorf("stack 4: %w", client.ErrAmbiguousStackID))
+
+	mcpServer := &PortainerMCPServer{cli: mockClient}
+
+	tests := []struct {
+		name     string
+		handler  server.ToolHandlerFunc
+		args     map[string]any
+		expected string
+		isError  bool
+	}{
+		{name: "start", handler: mcpServer.HandleStartStack(), args: map[string]any{"id": float64(1)}, expected: "Stack started successfully"},
+		{name: "stop", handler: mcpServer.HandleStopStack(), args: map[string]any{"id": float64(1)}, expected: "Stack stopped successfully"},
+		{
+			name:     "redeploy",
+			handler:  mcpServer.HandleRedeployStack(),
+			args:     map[string]any{"id": float64(1), "pullImage": true, "prune": true},
+			expected: "Stack redeployed successfully",
+		},
+		{
+			name:     "delete",
+			handler:  mcpServer.HandleDeleteStack(),
+			args:     map[string]any{"id": float64(1), "removeVolumes": true},
+			expected: "Stack deleted successfully",
+		},
+		{
+			name:     "delete edge stack",
+			handler:  mcpServer.HandleDeleteStack(),
+			args:     map[string]any{"id": float64(3), "kind": models.StackKindEdge},
+			expected: "Stack deleted successfully",
+		},
+		{name: "stop edge stack", handler: mcpServer.HandleStopStack(), args: map[string]any{"id": float64(5)}, expected: "not supported for edge stacks", isError: true},
+		{name: "ambiguous id", handler: mcpServer.HandleStartStack(), args: map[string]any{"id": float64(4)}, expected: "the kind of the stack must be given", isError: true},
+		{name: "invalid kind", handler: mcpServer.HandleDeleteStack(), args: map[string]any{"id": float64(1), "kind": "compose"}, expected: "invalid kind parameter", isError: true},
+		{name: "missing id", handler: mcpServer.HandleDeleteStack(), args: map[string]any{}, expected: "invalid id parameter", isError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			assert.Equal(t, tt.isError, result.IsError)
+			assert.Contains(t, resultText(t, result), tt.expected)
+		})
+	}
+
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/internal/compose"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleValidateStackFile() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		file, err := parser.GetString("file", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+		}
+
+		id, err := parser.GetInt("id", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		envNames, err := parser.GetArrayOfStrings("envNames", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid envNames parameter", err), nil
+		}
+
+		var knownVariables []string
+		if id != 0 {
+			names, err := s.cli.GetStackEnvNames(id)
+			if err != nil && !errors.Is(err, client.ErrEdgeStackEnv) {
+				return newToolResultAPIError("failed to get stack env names", err), nil
+			}
+			knownVariables = append([]string{}, names...)
+		}
+		if len(envNames) > 0 {
+			knownVariables = append(knownVariables, envNames...)
+		}
+
+		report := compose.Validate(file, compose.Options{KnownVariables: knownVariables})
+
+		data, err := json.Marshal(report)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal validation report", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// checkStackFile validates a stack file before it is sent to Portainer.
+// It returns an error result if the file has errors, otherwise the warnings to append to the result of the write.
+func checkStackFile(file string, knownVariables []string) (*mcp.CallToolResult, string) {
+	return checkValidationReport(compose.Validate(file, compose.Options{KnownVariables: knownVariables}))
+}
+
+// checkValidationReport returns an error result if a validation report has errors, otherwise its warnings
+func checkValidationReport(report compose.Report) (*mcp.CallToolResult, string) {
+	var lines []string
+	for _, finding := range report.Findings {
+		if finding.Severity != compose.SeverityInfo {
+			lines = append(lines, "- "+finding.String())
+		}
+	}
+
+	if !report.Valid {
+		return mcp.NewToolResultError("stack file validation failed, fix the errors below or set skipValidation to deploy it anyway:\n" + strings.Join(lines, "\n")), ""
+	}
+	if len(lines) == 0 {
+		return nil, ""
+	}
+
+	return nil, "\n\nStack file validation warnings:\n" + strings.Join(lines, "\n")
+}
+
+// stackVariableNames returns the names of the environment variables available to a stack file,
+// or nil if they cannot be determined, in which case the variables of the file are not checked
+func (s *PortainerMCPServer) stackVariableNames(id int, kind string, overrides []models.StackEnvVar) []string {
+	var names []string
+	if kind != models.StackKindEdge {
+		var err error
+		names, err = s.cli.GetStackEnvNames(id)
+		if err != nil && !errors.Is(err, client.ErrEdgeStackEnv) {
+			return nil
+		}
+	}
+
+	result := append([]string{}, names...)
+	for _, override := range overrides {
+		result = append(result, override.Name)
+	}
+	return result
+}
+
+func envVarNames(env []models.StackEnvVar) []string {
+	names := make([]string, 0, len(env))
+	for _, entry := range env {
+		names = append(names, entry.Name)
+	}
+	return names
+}
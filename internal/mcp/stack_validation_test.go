@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/internal/compose"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleValidateStackFile(t *testing.T) {
+	file := "services:\n  web:\n    image: nginx:1.27\n    environment:\n      TOKEN: ${TOKEN}\n      HOST: ${HOST}"
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		setupMock     func(mockClient *MockPortainerClient)
+		expectedRules []string
+	}{
+		{
+			name:          "without variables",
+			args:          map[string]any{"file": file},
+			expectedRules: []string{compose.RuleHealthcheck},
+		},
+		{
+			name: "with the variables of a stack",
+			args: map[string]any{"file": file, "id": float64(1)},
+			setupMock: func(mockClient *MockPortainerClient) {
+				mockClient.On("GetStackEnvNames", 1).Return([]string{"TOKEN"}, nil)
+			},
+			expectedRules: []string{compose.RuleUndefinedVariable, compose.RuleHealthcheck},
+		},
+		{
+			name: "with the variables of an edge stack and env names",
+			args: map[string]any{"file": file, "id": float64(2), "envNames": []any{"HOST"}},
+			setupMock: func(mockClient *MockPortainerClient) {
+				mockClient.On("GetStackEnvNames", 2).Return(nil, client.ErrEdgeStackEnv)
+			},
+			expectedRules: []string{compose.RuleUndefinedVariable, compose.RuleHealthcheck},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleValidateStackFile()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+
+			var report compose.Report
+			require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &report))
+			assert.True(t, report.Valid)
+
+			rules := []string{}
+			for _, finding := range report.Findings {
+				rules = append(rules, finding.Rule)
+			}
+			assert.Equal(t, tt.expectedRules, rules)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStackWritesAreValidated(t *testing.T) {
+	invalidFile := "services:\n  web:\n    imag: nginx"
+	untaggedFile := "services:\n  web:\n    image: nginx"
+
+	t.Run("create is blocked by errors", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleCreateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"name":          "web",
+			"file":          invalidFile,
+			"environmentId": float64(1),
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), `unknown service key "imag"`)
+		mockClient.AssertNotCalled(t, "CreateStack", mock.Anything)
+	})
+
+	t.Run("create with skipValidation", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("CreateStack", mock.Anything).Return(3, nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleCreateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"name":           "web",
+			"file":           invalidFile,
+			"environmentId":  float64(1),
+			"skipValidation": true,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Equal(t, "Stack created successfully with ID: 3", resultText(t, result))
+	})
+
+	t.Run("update returns warnings", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{}, nil)
+		mockClient.On("UpdateStack", 1, models.StackKindRegular, untaggedFile, []int{1}, []models.StackEnvVar{}).Return(nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":                  float64(1),
+			"file":                untaggedFile,
+			"environmentGroupIds": []any{float64(1)},
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, resultText(t, result))
+		assert.Contains(t, resultText(t, result), "Stack updated successfully")
+		assert.Contains(t, resultText(t, result), "warning [image-tag] line 3, service web")
+		assert.NotContains(t, resultText(t, result), "healthcheck")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("update checks required variables", func(t *testing.T) {
+		file := "services:\n  web:\n    image: nginx:1.27\n    environment:\n      TOKEN: ${TOKEN:?}\n      HOST: ${HOST:?}"
+
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetStack", 1, "").Return(models.Stack{ID: 1, Kind: models.StackKindCompose}, nil)
+		mockClient.On("GetStackEnvNames", 1).Return([]string{"TOKEN"}, nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleUpdateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id":                  float64(1),
+			"file":                file,
+			"environmentGroupIds": []any{float64(1)},
+			"envOverrides":        []any{map[string]any{"name": "DEBUG", "value": "1"}},
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "variable HOST is required")
+		assert.NotContains(t, resultText(t, result), "variable TOKEN")
+		mockClient.AssertNotCalled(t, "UpdateStack", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
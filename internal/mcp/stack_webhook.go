@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/portainer/portainer-mcp/pkg/toolgen"
+)
+
+func (s *PortainerMCPServer) HandleGetStackWebhook() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		webhook, err := s.cli.GetStackWebhook(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack webhook", err), nil
+		}
+
+		data, err := json.Marshal(webhook)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to marshal stack webhook", err), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleUpdateStackWebhook() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		enabled, err := parser.GetBoolean("enabled", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid enabled parameter", err), nil
+		}
+
+		current, err := s.cli.GetStackWebhook(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack webhook", err), nil
+		}
+
+		if current.Enabled == enabled {
+			if enabled {
+				return mcp.NewToolResultText(fmt.Sprintf("Stack webhook is already enabled: %s", current.URL)), nil
+			}
+			return mcp.NewToolResultText("Stack webhook is already disabled"), nil
+		}
+
+		webhook := ""
+		if enabled {
+			if webhook, err = newUUID(); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to generate webhook id", err), nil
+			}
+		}
+
+		change := s.captureChange(ChangeKindStackWebhook, id)
+
+		if err := s.cli.SetStackWebhook(id, webhook); err != nil {
+			return newToolResultAPIError("failed to update stack webhook", err), nil
+		}
+
+		message := "Stack webhook disabled successfully"
+		if enabled {
+			updated, err := s.cli.GetStackWebhook(id)
+			if err != nil {
+				return newToolResultAPIError("stack webhook enabled, but failed to get its URL", err), nil
+			}
+			message = fmt.Sprintf("Stack webhook enabled successfully: %s", updated.URL)
+		}
+
+		return mcp.NewToolResultText(message + s.recordChange(ToolUpdateStackWebhook, change)), nil
+	}
+}
+
+func (s *PortainerMCPServer) HandleTriggerStackWebhook() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		webhook, err := s.cli.GetStackWebhook(id)
+		if err != nil {
+			return newToolResultAPIError("failed to get stack webhook", err), nil
+		}
+		if !webhook.Enabled {
+			return mcp.NewToolResultError(fmt.Sprintf("the webhook of stack %d is disabled, enable it with %s first", id, ToolUpdateStackWebhook)), nil
+		}
+
+		// Stacks deployed from git are pulled by the webhook, so their file may change
+		observed := s.observeStackWarning(id, models.StackKindRegular)
+
+		if err := s.cli.TriggerStackWebhook(webhook.Webhook); err != nil {
+			return newToolResultAPIError("failed to trigger stack webhook", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Stack %d redeployed through its webhook", id) + observed + s.recordStackRevision(ToolTriggerStackWebhook, id, models.StackKindRegular)), nil
+	}
+}
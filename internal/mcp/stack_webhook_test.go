@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testStackWebhook = "05de31a2-79fa-4644-9c12-faa67e5c49f0"
+
+func enabledStackWebhook(id int) models.StackWebhook {
+	return models.StackWebhook{
+		StackID: id,
+		Enabled: true,
+		Webhook: testStackWebhook,
+		URL:     "https://portainer.example.com/api/stacks/webhooks/" + testStackWebhook,
+	}
+}
+
+func TestHandleGetStackWebhook(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackWebhook", 1).Return(enabledStackWebhook(1), nil)
+	mockClient.On("GetStackWebhook", 2).Return(models.StackWebhook{}, fmt.Errorf("operation not supported for edge stacks"))
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetStackWebhook()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var got models.StackWebhook
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &got))
+	assert.Equal(t, enabledStackWebhook(1), got)
+
+	result, err = server.HandleGetStackWebhook()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "not supported for edge stacks")
+}
+
+func TestHandleUpdateStackWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     models.StackWebhook
+		enabled     bool
+		expectSet   bool
+		message     string
+		expectError bool
+	}{
+		{
+			name:      "enable",
+			current:   models.StackWebhook{StackID: 1},
+			enabled:   true,
+			expectSet: true,
+			message:   "Stack webhook enabled successfully: https://portainer.example.com/api/stacks/webhooks/" + testStackWebhook,
+		},
+		{
+			name:      "disable",
+			current:   enabledStackWebhook(1),
+			enabled:   false,
+			expectSet: true,
+			message:   "Stack webhook disabled successfully",
+		},
+		{
+			name:    "already enabled",
+			current: enabledStackWebhook(1),
+			enabled: true,
+			message: "Stack webhook is already enabled: https://portainer.example.com/api/stacks/webhooks/" + testStackWebhook,
+		},
+		{
+			name:    "already disabled",
+			current: models.StackWebhook{StackID: 1},
+			enabled: false,
+			message: "Stack webhook is already disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStackWebhook", 1).Return(tt.current, nil).Once()
+			if tt.expectSet {
+				mockClient.On("SetStackWebhook", 1, mock.AnythingOfType("string")).Return(nil)
+				mockClient.On("GetStackWebhook", 1).Return(enabledStackWebhook(1), nil)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleUpdateStackWebhook()(context.Background(), CreateMCPRequest(map[string]any{
+				"id":      float64(1),
+				"enabled": tt.enabled,
+			}))
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tt.message, resultText(t, result))
+
+			if !tt.expectSet {
+				mockClient.AssertNotCalled(t, "SetStackWebhook", mock.Anything, mock.Anything)
+				return
+			}
+			for _, call := range mockClient.Calls {
+				if call.Method != "SetStackWebhook" {
+					continue
+				}
+				if tt.enabled {
+					assert.Len(t, call.Arguments.String(1), 36, "a webhook id must be generated")
+				} else {
+					assert.Empty(t, call.Arguments.String(1))
+				}
+			}
+		})
+	}
+}
+
+func TestStackWebhookCaptureAndApply(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackWebhook", 1).Return(enabledStackWebhook(1), nil)
+	mockClient.On("SetStackWebhook", 1, testStackWebhook).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	state, err := server.captureState(ChangeKindStackWebhook, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"`+testStackWebhook+`"`, string(state))
+
+	require.NoError(t, server.applyState(ChangeKindStackWebhook, 1, state))
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleTriggerStackWebhook(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackWebhook", 1).Return(enabledStackWebhook(1), nil)
+	mockClient.On("GetStackWebhook", 2).Return(models.StackWebhook{StackID: 2}, nil)
+	mockClient.On("TriggerStackWebhook", testStackWebhook).Return(nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleTriggerStackWebhook()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "Stack 1 redeployed through its webhook", resultText(t, result))
+
+	result, err = server.HandleTriggerStackWebhook()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "the webhook of stack 2 is disabled")
+	mockClient.AssertNumberOfCalls(t, "TriggerStackWebhook", 1)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
 	"github.com/portainer/portainer-mcp/pkg/toolgen"
 )
 
@@ -20,9 +21,15 @@ func (s *PortainerMCPServer) AddTagFeatures() {
 
 func (s *PortainerMCPServer) HandleGetEnvironmentTags() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		if err := s.refreshCache(parser, client.CacheKeyTags); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid refresh parameter", err), nil
+		}
+
 		environmentTags, err := s.cli.GetEnvironmentTags()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get environment tags", err), nil
+			return newToolResultAPIError("failed to get environment tags", err), nil
 		}
 
 		data, err := json.Marshal(environmentTags)
@@ -45,7 +52,7 @@ func (s *PortainerMCPServer) HandleCreateEnvironmentTag() server.ToolHandlerFunc
 
 		id, err := s.cli.CreateEnvironmentTag(name)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to create environment tag", err), nil
+			return newToolResultAPIError("failed to create environment tag", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Environment tag created successfully with ID: %d", id)), nil
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
 	"github.com/portainer/portainer-mcp/pkg/toolgen"
 )
 
@@ -31,7 +32,7 @@ func (s *PortainerMCPServer) HandleCreateTeam() server.ToolHandlerFunc {
 
 		teamID, err := s.cli.CreateTeam(name)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to create team", err), nil
+			return newToolResultAPIError("failed to create team", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Team created successfully with ID: %d", teamID)), nil
@@ -40,9 +41,15 @@ func (s *PortainerMCPServer) HandleCreateTeam() server.ToolHandlerFunc {
 
 func (s *PortainerMCPServer) HandleGetTeams() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		if err := s.refreshCache(parser, client.CacheKeyTeams); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid refresh parameter", err), nil
+		}
+
 		teams, err := s.cli.GetTeams()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get teams", err), nil
+			return newToolResultAPIError("failed to get teams", err), nil
 		}
 
 		data, err := json.Marshal(teams)
@@ -68,12 +75,14 @@ func (s *PortainerMCPServer) HandleUpdateTeamName() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindTeamName, id)
+
 		err = s.cli.UpdateTeamName(id, name)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update team name", err), nil
+			return newToolResultAPIError("failed to update team name", err), nil
 		}
 
-		return mcp.NewToolResultText("Team name updated successfully"), nil
+		return mcp.NewToolResultText("Team name updated successfully" + s.recordChange(ToolUpdateTeamName, change)), nil
 	}
 }
 
@@ -91,11 +100,13 @@ func (s *PortainerMCPServer) HandleUpdateTeamMembers() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid userIds parameter", err), nil
 		}
 
+		change := s.captureChange(ChangeKindTeamMembers, id)
+
 		err = s.cli.UpdateTeamMembers(id, userIDs)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update team members", err), nil
+			return newToolResultAPIError("failed to update team members", err), nil
 		}
 
-		return mcp.NewToolResultText("Team members updated successfully"), nil
+		return mcp.NewToolResultText("Team members updated successfully" + s.recordChange(ToolUpdateTeamMembers, change)), nil
 	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
 	"github.com/portainer/portainer-mcp/pkg/toolgen"
 )
 
@@ -20,9 +21,15 @@ func (s *PortainerMCPServer) AddUserFeatures() {
 
 func (s *PortainerMCPServer) HandleGetUsers() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		if err := s.refreshCache(parser, client.CacheKeyUsers); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid refresh parameter", err), nil
+		}
+
 		users, err := s.cli.GetUsers()
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to get users", err), nil
+			return newToolResultAPIError("failed to get users", err), nil
 		}
 
 		data, err := json.Marshal(users)
@@ -52,11 +59,13 @@ func (s *PortainerMCPServer) HandleUpdateUserRole() server.ToolHandlerFunc {
 			return mcp.NewToolResultError(fmt.Sprintf("invalid role %s: must be one of: %v", role, AllUserRoles)), nil
 		}
 
+		change := s.captureChange(ChangeKindUserRole, id)
+
 		err = s.cli.UpdateUserRole(id, role)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("failed to update user role", err), nil
+			return newToolResultAPIError("failed to update user role", err), nil
 		}
 
-		return mcp.NewToolResultText("User updated successfully"), nil
+		return mcp.NewToolResultText("User updated successfully" + s.recordChange(ToolUpdateUserRole, change)), nil
 	}
 }
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"crypto/rand"
 	"fmt"
 	"slices"
 
@@ -69,6 +70,18 @@ func isValidHTTPMethod(method string) bool {
 	return slices.Contains(validMethods, method)
 }
 
+// newUUID generates a random version 4 UUID, such as the ID of an edge agent or of a stack webhook
+func newUUID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]), nil
+}
+
 // CreateMCPRequest creates a new MCP tool request with the given arguments
 func CreateMCPRequest(args map[string]any) mcp.CallToolRequest {
 	return mcp.CallToolRequest{
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"maps"
+	"sync"
+)
+
+// Registry is a minimal in-memory store of named counters and gauges.
+// It is safe for concurrent use and a nil Registry ignores all updates.
+type Registry struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		values: map[string]int64{},
+	}
+}
+
+// Add increments the named metric by delta. Use a negative delta to decrement a gauge.
+func (r *Registry) Add(name string, delta int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] += delta
+}
+
+// Set sets the named metric to value
+func (r *Registry) Set(name string, value int64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = value
+}
+
+// Get returns the current value of the named metric, or 0 if it was never recorded
+func (r *Registry) Get(name string) int64 {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[name]
+}
+
+// Snapshot returns a copy of all recorded metrics
+func (r *Registry) Snapshot() map[string]int64 {
+	if r == nil {
+		return map[string]int64{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.values)
+}
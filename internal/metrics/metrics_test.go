@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+
+	registry.Add("requests", 2)
+	registry.Add("requests", 3)
+	registry.Add("in_flight", 1)
+	registry.Add("in_flight", -1)
+	registry.Set("limit", 10)
+	registry.Set("limit", 20)
+
+	assert.Equal(t, int64(5), registry.Get("requests"))
+	assert.Equal(t, int64(0), registry.Get("in_flight"))
+	assert.Equal(t, int64(20), registry.Get("limit"))
+	assert.Equal(t, int64(0), registry.Get("unknown"))
+
+	snapshot := registry.Snapshot()
+	assert.Equal(t, map[string]int64{"requests": 5, "in_flight": 0, "limit": 20}, snapshot)
+
+	snapshot["requests"] = 100
+	assert.Equal(t, int64(5), registry.Get("requests"), "snapshot must be a copy")
+}
+
+func TestRegistryConcurrentAdd(t *testing.T) {
+	registry := NewRegistry()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.Add("requests", 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(50), registry.Get("requests"))
+}
+
+func TestNilRegistry(t *testing.T) {
+	var registry *Registry
+
+	assert.NotPanics(t, func() {
+		registry.Add("requests", 1)
+		registry.Set("limit", 1)
+	})
+	assert.Equal(t, int64(0), registry.Get("requests"))
+	assert.Empty(t, registry.Snapshot())
+}
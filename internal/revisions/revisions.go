@@ -0,0 +1,250 @@
+// Package revisions keeps a local history of the definitions of the stacks managed through the MCP server.
+package revisions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Sources of a revision
+const (
+	// SourceWrite is a revision written by a tool of the MCP server
+	SourceWrite = "write"
+	// SourceObserved is a revision observed when reading the stack, usually a change made outside of the MCP server
+	SourceObserved = "observed"
+)
+
+// MaxRevisions is the number of revisions kept for each stack, older revisions are dropped
+const MaxRevisions = 50
+
+// Revision is a version of the definition of a stack.
+// Only the names of the environment variables are kept, as their values often contain secrets.
+type Revision struct {
+	// Number is the sequential number of the revision for the stack
+	Number  int `json:"revision"`
+	StackID int `json:"stack_id"`
+	// Kind is models.StackKindRegular or models.StackKindEdge, as Portainer numbers regular and edge stacks separately
+	Kind     string   `json:"kind"`
+	File     string   `json:"file,omitempty"`
+	EnvNames []string `json:"env_names"`
+	Source   string   `json:"source"`
+	// Tool is the name of the tool that wrote the revision
+	Tool      string    `json:"tool,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// stackKey identifies a stack within a Portainer server
+type stackKey struct {
+	kind string
+	id   int
+}
+
+// Store keeps the revisions of each stack of a Portainer server in a JSON file of its directory.
+// A Store created with an empty directory is kept in memory only.
+type Store struct {
+	dir string
+	now func() time.Time
+
+	mu     sync.Mutex
+	stacks map[stackKey][]Revision
+}
+
+// Open returns the store of the stacks of the Portainer server at serverURL, kept in a subdirectory of dir
+// named after the server so that stacks with the same ID on different servers are not mixed up.
+// The directory is created if needed.
+func Open(dir, serverURL string) (*Store, error) {
+	s := &Store{
+		now:    time.Now,
+		stacks: map[stackKey][]Revision{},
+	}
+
+	if dir == "" {
+		return s, nil
+	}
+
+	dir = filepath.Join(dir, serverDirName(serverURL))
+	s.dir = dir
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create stack revisions directory: %w", err)
+	}
+
+	return s, nil
+}
+
+// Record adds a revision of a stack, unless the file and environment variable names
+// are the same as in the latest revision of the stack.
+// It returns the recorded revision, or the latest one and false if nothing changed.
+func (s *Store) Record(kind string, stackID int, file string, envNames []string, source, tool string) (Revision, bool, error) {
+	key, err := newStackKey(kind, stackID)
+	if err != nil {
+		return Revision{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions, err := s.load(key)
+	if err != nil {
+		return Revision{}, false, err
+	}
+
+	envNames = slices.Sorted(slices.Values(envNames))
+	if envNames == nil {
+		envNames = []string{}
+	}
+
+	number := 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if latest.File == file && slices.Equal(latest.EnvNames, envNames) {
+			return latest, false, nil
+		}
+		number = latest.Number + 1
+	}
+
+	revision := Revision{
+		Number:    number,
+		StackID:   stackID,
+		Kind:      kind,
+		File:      file,
+		EnvNames:  envNames,
+		Source:    source,
+		Tool:      tool,
+		CreatedAt: s.now().UTC(),
+	}
+
+	revisions = append(slices.Clone(revisions), revision)
+	if len(revisions) > MaxRevisions {
+		revisions = revisions[len(revisions)-MaxRevisions:]
+	}
+
+	if err := s.save(key, revisions); err != nil {
+		return Revision{}, false, err
+	}
+	s.stacks[key] = revisions
+
+	return revision, true, nil
+}
+
+// List returns the revisions of a stack, most recent first
+func (s *Store) List(kind string, stackID int) ([]Revision, error) {
+	key, err := newStackKey(kind, stackID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions, err := s.load(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Revision, len(revisions))
+	for i, revision := range revisions {
+		result[len(revisions)-1-i] = revision
+	}
+
+	return result, nil
+}
+
+// Get returns a revision of a stack
+func (s *Store) Get(kind string, stackID, number int) (Revision, bool, error) {
+	key, err := newStackKey(kind, stackID)
+	if err != nil {
+		return Revision{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions, err := s.load(key)
+	if err != nil {
+		return Revision{}, false, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Number == number {
+			return revision, true, nil
+		}
+	}
+
+	return Revision{}, false, nil
+}
+
+// load returns the revisions of a stack, reading them from disk on first use. The caller must hold s.mu.
+func (s *Store) load(key stackKey) ([]Revision, error) {
+	if revisions, ok := s.stacks[key]; ok || s.dir == "" {
+		return revisions, nil
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack revisions: %w", err)
+	}
+
+	var revisions []Revision
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		return nil, fmt.Errorf("failed to parse stack revisions %s: %w", s.path(key), err)
+	}
+	s.stacks[key] = revisions
+
+	return revisions, nil
+}
+
+// save writes the revisions of a stack to its file, replacing it atomically. The caller must hold s.mu.
+func (s *Store) save(key stackKey, revisions []Revision) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(revisions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stack revisions: %w", err)
+	}
+
+	path := s.path(key)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write stack revisions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace stack revisions: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) path(key stackKey) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s-stack-%d.json", key.kind, key.id))
+}
+
+func newStackKey(kind string, id int) (stackKey, error) {
+	if kind != models.StackKindRegular && kind != models.StackKindEdge {
+		return stackKey{}, fmt.Errorf("invalid stack kind: %s", kind)
+	}
+	return stackKey{kind: kind, id: id}, nil
+}
+
+// serverDirName returns the name of the directory of the revisions of a Portainer server,
+// a hash of its URL as URLs are not valid file names
+func serverDirName(serverURL string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimRight(serverURL, "/"))))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,147 @@
+package revisions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreInMemory(t *testing.T) {
+	s, err := Open("", "")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	first, recorded, err := s.Record(models.StackKindRegular, 1, "services: {}", []string{"B", "A"}, SourceObserved, "")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, []string{"A", "B"}, first.EnvNames, "env names must be sorted")
+	assert.Equal(t, now, first.CreatedAt)
+
+	same, recorded, err := s.Record(models.StackKindRegular, 1, "services: {}", []string{"A", "B"}, SourceObserved, "")
+	require.NoError(t, err)
+	assert.False(t, recorded, "an unchanged stack must not create a revision")
+	assert.Equal(t, first, same)
+
+	second, recorded, err := s.Record(models.StackKindRegular, 1, "services:\n  web: {}", []string{"A", "B"}, SourceWrite, "updateStack")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, 2, second.Number)
+
+	other, _, err := s.Record(models.StackKindRegular, 2, "services: {}", nil, SourceWrite, "createStack")
+	require.NoError(t, err)
+	assert.Equal(t, 1, other.Number, "numbers are sequential per stack")
+	assert.Equal(t, []string{}, other.EnvNames)
+
+	list, err := s.List(models.StackKindRegular, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, 2, list[0].Number, "revisions must be listed most recent first")
+	assert.Equal(t, "updateStack", list[0].Tool)
+
+	revision, ok, err := s.Get(models.StackKindRegular, 1, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "services: {}", revision.File)
+
+	_, ok, err = s.Get(models.StackKindRegular, 1, 42)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	list, err = s.List(models.StackKindRegular, 3)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = s.List("compose", 1)
+	assert.Error(t, err, "the kind must be regular or edge")
+}
+
+func TestStoreSeparatesKindsAndServers(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(dir, "https://portainer-a.example.com:9443")
+	require.NoError(t, err)
+
+	_, _, err = s.Record(models.StackKindRegular, 1, "services:\n  regular: {}", nil, SourceWrite, "createStack")
+	require.NoError(t, err)
+	edge, recorded, err := s.Record(models.StackKindEdge, 1, "services:\n  edge: {}", nil, SourceWrite, "createStack")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, 1, edge.Number, "regular and edge stacks with the same ID must have their own revisions")
+	assert.Equal(t, models.StackKindEdge, edge.Kind)
+
+	list, err := s.List(models.StackKindRegular, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "services:\n  regular: {}", list[0].File)
+
+	other, err := Open(dir, "https://portainer-b.example.com:9443")
+	require.NoError(t, err)
+
+	list, err = other.List(models.StackKindRegular, 1)
+	require.NoError(t, err)
+	assert.Empty(t, list, "the revisions of another server must not be listed")
+}
+
+func TestStoreMaxRevisions(t *testing.T) {
+	s, err := Open("", "")
+	require.NoError(t, err)
+
+	for i := range MaxRevisions + 5 {
+		_, _, err := s.Record(models.StackKindRegular, 1, "version "+string(rune('a'+i%26))+string(rune('a'+i/26)), nil, SourceWrite, "updateStack")
+		require.NoError(t, err)
+	}
+
+	list, err := s.List(models.StackKindRegular, 1)
+	require.NoError(t, err)
+	require.Len(t, list, MaxRevisions)
+	assert.Equal(t, MaxRevisions+5, list[0].Number)
+	assert.Equal(t, 6, list[len(list)-1].Number, "the oldest revisions must be dropped")
+}
+
+func TestStorePersistence(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "stack-revisions")
+
+	s, err := Open(dir, "https://portainer.example.com:9443")
+	require.NoError(t, err)
+
+	_, _, err = s.Record(models.StackKindRegular, 4, "services: {}", []string{"TOKEN"}, SourceWrite, "createStack")
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, serverDirName("https://portainer.example.com:9443"), "regular-stack-4.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened, err := Open(dir, "https://portainer.example.com:9443/")
+	require.NoError(t, err)
+
+	list, err := reopened.List(models.StackKindRegular, 4)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, []string{"TOKEN"}, list[0].EnvNames)
+
+	revision, recorded, err := reopened.Record(models.StackKindRegular, 4, "services:\n  web: {}", nil, SourceObserved, "")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, 2, revision.Number, "numbers must continue after the loaded revisions")
+}
+
+func TestStoreInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, "https://portainer.example.com:9443")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(s.path(stackKey{kind: models.StackKindRegular, id: 1}), []byte("not json"), 0600))
+
+	s, err = Open(dir, "https://portainer.example.com:9443")
+	require.NoError(t, err)
+
+	_, err = s.List(models.StackKindRegular, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,362 @@
+// Package stackbundle exports the stacks of a Portainer server to an archive and imports them on another server.
+//
+// A bundle is a gzip compressed tar archive or a zip archive containing a manifest.json file that describes
+// the stacks, and the file of each stack. The values of the stack environment variables are optional:
+// when they are included, they are encrypted with a key derived from a passphrase.
+package stackbundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Formats of a bundle
+const (
+	// FormatTar is a gzip compressed tar archive
+	FormatTar = "tar"
+	// FormatZip is a zip archive
+	FormatZip = "zip"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase that encrypts the environment variable
+// values of a bundle. It is never taken from the arguments of a tool, so that it is not exposed to the model.
+const PassphraseEnv = "PORTAINER_MCP_BUNDLE_PASSPHRASE"
+
+// ManifestVersion is the version of the manifest written in new bundles
+const ManifestVersion = 1
+
+const manifestPath = "manifest.json"
+
+// Limits on the content of a bundle read, so that a crafted archive cannot exhaust the memory
+const (
+	// maxFileSize is the maximum size of a file of a bundle once uncompressed
+	maxFileSize = 8 << 20
+	// maxBundleSize is the maximum size of all the files of a bundle once uncompressed
+	maxBundleSize = 64 << 20
+)
+
+// Manifest describes the stacks of a bundle
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Encryption is set when the bundle contains environment variable values
+	Encryption *Encryption  `json:"encryption,omitempty"`
+	Stacks     []StackEntry `json:"stacks"`
+}
+
+// StackEntry describes a stack of a bundle
+type StackEntry struct {
+	// ID is the ID of the stack on the server it was exported from
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// EnvironmentID and EnvironmentName identify the environment of a regular stack
+	EnvironmentID   int    `json:"environment_id,omitempty"`
+	EnvironmentName string `json:"environment_name,omitempty"`
+	// EnvironmentGroups are the environment groups of an edge stack
+	EnvironmentGroups []EnvironmentGroup `json:"environment_groups,omitempty"`
+	// File is the path of the stack file in the bundle
+	File     string   `json:"file"`
+	EnvNames []string `json:"env_names"`
+	// EnvFile is the path of the encrypted environment variable values in the bundle, if they are included
+	EnvFile string `json:"env_file,omitempty"`
+	// Git is the repository of a git stack, informative only as the stack is imported from its file
+	Git *models.StackGitConfig `json:"git,omitempty"`
+}
+
+// EnvironmentGroup identifies an environment group of an edge stack
+type EnvironmentGroup struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Stack is a stack with the content of its file
+type Stack struct {
+	StackEntry
+	Content string
+	// Env holds the environment variables with their values. It is nil if the values are not available.
+	Env []models.StackEnvVar
+}
+
+// FormatFromPath returns the format of a bundle from its file name: zip for .zip files, tar otherwise
+func FormatFromPath(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".zip") {
+		return FormatZip
+	}
+	return FormatTar
+}
+
+// Write writes a bundle containing stacks. The environment variable values of the stacks that have them
+// are encrypted with passphrase, which is required in that case.
+func Write(w io.Writer, format string, stacks []Stack, passphrase string) error {
+	manifest := Manifest{
+		Version:   ManifestVersion,
+		CreatedAt: time.Now().UTC(),
+		Stacks:    make([]StackEntry, 0, len(stacks)),
+	}
+
+	var cipher *envCipher
+	files := map[string][]byte{}
+	var order []string
+	addFile := func(name string, data []byte) {
+		files[name] = data
+		order = append(order, name)
+	}
+
+	for _, stack := range stacks {
+		entry := stack.StackEntry
+		// Regular and edge stacks are numbered separately, edge stacks are prefixed so that their directories
+		// do not collide
+		dirName := fmt.Sprintf("%d-%s", stack.ID, sanitizeName(stack.Name))
+		if stack.Kind == models.StackKindEdge {
+			dirName = "edge-" + dirName
+		}
+		dir := path.Join("stacks", dirName)
+
+		entry.File = path.Join(dir, stackFileName(stack.Kind))
+		addFile(entry.File, []byte(stack.Content))
+
+		entry.EnvFile = ""
+		if stack.Env != nil {
+			if cipher == nil {
+				if passphrase == "" {
+					return fmt.Errorf("a passphrase is required to include environment variable values")
+				}
+				var err error
+				if cipher, err = newEnvCipher(passphrase); err != nil {
+					return err
+				}
+				manifest.Encryption = cipher.encryption()
+			}
+
+			entry.EnvFile = path.Join(dir, "env.enc")
+			data, err := cipher.seal(entry.EnvFile, stack.Env)
+			if err != nil {
+				return err
+			}
+			addFile(entry.EnvFile, data)
+		}
+
+		manifest.Stacks = append(manifest.Stacks, entry)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	order = append([]string{manifestPath}, order...)
+	files[manifestPath] = manifestData
+
+	switch format {
+	case FormatTar:
+		return writeTar(w, order, files, manifest.CreatedAt)
+	case FormatZip:
+		return writeZip(w, order, files, manifest.CreatedAt)
+	default:
+		return fmt.Errorf("unsupported bundle format: %s", format)
+	}
+}
+
+// Read reads a bundle. The format is detected from the content.
+// The environment variable values are decrypted with passphrase if the bundle includes them and passphrase is set,
+// otherwise the Env of the stacks is nil.
+func Read(data []byte, passphrase string) (Manifest, []Stack, error) {
+	files, err := readFiles(data)
+	if err != nil {
+		return Manifest{}, nil, err
+	}
+
+	manifestData, ok := files[manifestPath]
+	if !ok {
+		return Manifest{}, nil, fmt.Errorf("the bundle does not contain a %s file", manifestPath)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return Manifest{}, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Version != ManifestVersion {
+		return Manifest{}, nil, fmt.Errorf("unsupported bundle version: %d", manifest.Version)
+	}
+
+	var cipher *envCipher
+	if manifest.Encryption != nil && passphrase != "" {
+		if cipher, err = openEnvCipher(*manifest.Encryption, passphrase); err != nil {
+			return Manifest{}, nil, err
+		}
+	}
+
+	stacks := make([]Stack, 0, len(manifest.Stacks))
+	for _, entry := range manifest.Stacks {
+		content, ok := files[entry.File]
+		if !ok {
+			return Manifest{}, nil, fmt.Errorf("the bundle does not contain the file %s of stack %s", entry.File, entry.Name)
+		}
+
+		stack := Stack{StackEntry: entry, Content: string(content)}
+
+		if entry.EnvFile != "" && cipher != nil {
+			sealed, ok := files[entry.EnvFile]
+			if !ok {
+				return Manifest{}, nil, fmt.Errorf("the bundle does not contain the file %s of stack %s", entry.EnvFile, entry.Name)
+			}
+			if stack.Env, err = cipher.open(entry.EnvFile, sealed); err != nil {
+				return Manifest{}, nil, err
+			}
+		}
+
+		stacks = append(stacks, stack)
+	}
+
+	return manifest, stacks, nil
+}
+
+func writeTar(w io.Writer, order []string, files map[string][]byte, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range order {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+func writeZip(w io.Writer, order []string, files map[string][]byte, modTime time.Time) error {
+	zw := zip.NewWriter(w)
+
+	for _, name := range order {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+		header.SetMode(0600)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := fw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// readFiles returns the regular files of a zip archive, or of a tar archive that may be gzip compressed
+func readFiles(data []byte) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	var total int
+	readFile := func(name string, r io.Reader) error {
+		content, err := io.ReadAll(io.LimitReader(r, maxFileSize+1))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if len(content) > maxFileSize {
+			return fmt.Errorf("the file %s of the bundle is larger than %d bytes", name, maxFileSize)
+		}
+		total += len(content)
+		if total > maxBundleSize {
+			return fmt.Errorf("the files of the bundle are larger than %d bytes", maxBundleSize)
+		}
+		files[name] = content
+		return nil
+	}
+
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip bundle: %w", err)
+		}
+		for _, file := range zr.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+			}
+			err = readFile(file.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return files, nil
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar bundle: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := readFile(header.Name, tr); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func sanitizeName(name string) string {
+	name = unsafeNameChars.ReplaceAllString(name, "_")
+	if name == "" || strings.Trim(name, ".") == "" {
+		return "stack"
+	}
+	return name
+}
+
+func stackFileName(kind string) string {
+	if kind == models.StackKindKubernetes {
+		return "manifest.yml"
+	}
+	return "docker-compose.yml"
+}
@@ -0,0 +1,133 @@
+package stackbundle
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStacks() []Stack {
+	return []Stack{
+		{
+			StackEntry: StackEntry{ID: 1, Name: "web app", Kind: models.StackKindCompose, EnvironmentID: 3, EnvironmentName: "staging", EnvNames: []string{"TOKEN"}},
+			Content:    "services:\n  web:\n    image: nginx:1.27\n",
+			Env:        []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+		},
+		{
+			StackEntry: StackEntry{ID: 2, Name: "agent", Kind: models.StackKindEdge, EnvironmentGroups: []EnvironmentGroup{{ID: 4, Name: "stores"}}, EnvNames: []string{}},
+			Content:    "services:\n  agent:\n    image: agent:2\n",
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, format := range []string{FormatTar, FormatZip} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, format, testStacks(), "correct horse"))
+			assert.NotContains(t, buf.String(), "secret", "env values must be encrypted")
+
+			manifest, stacks, err := Read(buf.Bytes(), "correct horse")
+			require.NoError(t, err)
+			assert.Equal(t, ManifestVersion, manifest.Version)
+			require.NotNil(t, manifest.Encryption)
+			assert.Equal(t, KDFPBKDF2SHA256, manifest.Encryption.KDF)
+
+			require.Len(t, stacks, 2)
+			assert.Equal(t, "stacks/1-web_app/docker-compose.yml", stacks[0].File)
+			assert.Equal(t, "stacks/1-web_app/env.enc", stacks[0].EnvFile)
+			assert.Equal(t, testStacks()[0].Content, stacks[0].Content)
+			assert.Equal(t, []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}, stacks[0].Env)
+			assert.Equal(t, "stacks/edge-2-agent/docker-compose.yml", stacks[1].File)
+			assert.Equal(t, []EnvironmentGroup{{ID: 4, Name: "stores"}}, stacks[1].EnvironmentGroups)
+			assert.Nil(t, stacks[1].Env)
+		})
+	}
+}
+
+func TestReadWithoutPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatTar, testStacks(), "correct horse"))
+
+	_, stacks, err := Read(buf.Bytes(), "")
+	require.NoError(t, err)
+	assert.Nil(t, stacks[0].Env, "values must not be available without the passphrase")
+	assert.Equal(t, []string{"TOKEN"}, stacks[0].EnvNames)
+
+	_, _, err = Read(buf.Bytes(), "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestWriteWithoutValues(t *testing.T) {
+	stacks := testStacks()
+	stacks[0].Env = nil
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatZip, stacks, ""))
+
+	manifest, read, err := Read(buf.Bytes(), "")
+	require.NoError(t, err)
+	assert.Nil(t, manifest.Encryption)
+	assert.Empty(t, read[0].EnvFile)
+}
+
+func TestWriteValuesRequirePassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatTar, testStacks(), "")
+	assert.ErrorContains(t, err, "a passphrase is required")
+}
+
+func TestWriteSameIDs(t *testing.T) {
+	stacks := []Stack{
+		{StackEntry: StackEntry{ID: 2, Name: "agent", Kind: models.StackKindCompose, EnvNames: []string{}}, Content: "regular file"},
+		{StackEntry: StackEntry{ID: 2, Name: "agent", Kind: models.StackKindEdge, EnvNames: []string{}}, Content: "edge file"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatTar, stacks, ""))
+
+	_, read, err := Read(buf.Bytes(), "")
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Equal(t, "regular file", read[0].Content)
+	assert.Equal(t, "edge file", read[1].Content, "a regular and an edge stack with the same ID must not share their files")
+}
+
+func TestReadInvalidBundle(t *testing.T) {
+	_, _, err := Read([]byte("not a bundle"), "")
+	assert.Error(t, err)
+}
+
+func TestReadLimits(t *testing.T) {
+	large := map[string][]byte{manifestPath: bytes.Repeat([]byte(" "), maxFileSize+1)}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		FormatTar: func(buf *bytes.Buffer) error { return writeTar(buf, []string{manifestPath}, large, time.Now()) },
+		FormatZip: func(buf *bytes.Buffer) error { return writeZip(buf, []string{manifestPath}, large, time.Now()) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, write(&buf))
+
+			_, _, err := Read(buf.Bytes(), "")
+			assert.ErrorContains(t, err, "is larger than")
+		})
+	}
+}
+
+func TestOpenEnvCipherIterations(t *testing.T) {
+	for _, iterations := range []int{0, maxKDFRounds + 1} {
+		_, err := openEnvCipher(Encryption{KDF: KDFPBKDF2SHA256, Iterations: iterations, Salt: []byte("salt"), Cipher: CipherAES256GCM}, "correct horse")
+		assert.ErrorContains(t, err, "unsupported number of key derivation iterations")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, FormatZip, FormatFromPath("backup/stacks.ZIP"))
+	assert.Equal(t, FormatTar, FormatFromPath("stacks.tar.gz"))
+	assert.Equal(t, FormatTar, FormatFromPath("stacks"))
+}
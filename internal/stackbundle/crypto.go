@@ -0,0 +1,123 @@
+package stackbundle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Encryption algorithms of the environment variable values
+const (
+	KDFPBKDF2SHA256  = "pbkdf2-sha256"
+	CipherAES256GCM  = "aes-256-gcm"
+	defaultKDFRounds = 600000
+	saltSize         = 16
+	keySize          = 32
+	// maxKDFRounds bounds the iterations read from a bundle, so that a crafted bundle cannot stall the import
+	maxKDFRounds = 10 * defaultKDFRounds
+)
+
+// ErrWrongPassphrase is returned when the environment variable values of a bundle cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase, the environment variable values cannot be decrypted")
+
+// Encryption describes how the environment variable values of a bundle are encrypted
+type Encryption struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Cipher     string `json:"cipher"`
+}
+
+// envCipher encrypts the environment variable values of the stacks of a bundle with AES-256-GCM,
+// using a key derived from a passphrase with PBKDF2-SHA256
+type envCipher struct {
+	salt       []byte
+	iterations int
+	aead       cipher.AEAD
+}
+
+func newEnvCipher(passphrase string) (*envCipher, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return deriveEnvCipher(passphrase, salt, defaultKDFRounds)
+}
+
+func openEnvCipher(encryption Encryption, passphrase string) (*envCipher, error) {
+	if encryption.KDF != KDFPBKDF2SHA256 || encryption.Cipher != CipherAES256GCM {
+		return nil, fmt.Errorf("unsupported bundle encryption: %s with %s", encryption.KDF, encryption.Cipher)
+	}
+	if encryption.Iterations <= 0 || encryption.Iterations > maxKDFRounds {
+		return nil, fmt.Errorf("unsupported number of key derivation iterations: %d, at most %d are supported", encryption.Iterations, maxKDFRounds)
+	}
+	return deriveEnvCipher(passphrase, encryption.Salt, encryption.Iterations)
+}
+
+func deriveEnvCipher(passphrase string, salt []byte, iterations int) (*envCipher, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &envCipher{salt: salt, iterations: iterations, aead: aead}, nil
+}
+
+func (c *envCipher) encryption() *Encryption {
+	return &Encryption{
+		KDF:        KDFPBKDF2SHA256,
+		Iterations: c.iterations,
+		Salt:       c.salt,
+		Cipher:     CipherAES256GCM,
+	}
+}
+
+// seal encrypts environment variables. The name of the file is authenticated, so that the values
+// of a stack cannot be swapped with the values of another stack.
+func (c *envCipher) seal(name string, env []models.StackEnvVar) ([]byte, error) {
+	plaintext, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal env: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, []byte(name)), nil
+}
+
+func (c *envCipher) open(name string, sealed []byte) ([]models.StackEnvVar, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("invalid encrypted env file %s", name)
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	env := []models.StackEnvVar{}
+	if err := json.Unmarshal(plaintext, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse env of %s: %w", name, err)
+	}
+	return env, nil
+}
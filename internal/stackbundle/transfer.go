@@ -0,0 +1,310 @@
+package stackbundle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Client is the part of the Portainer client used to export and import stacks
+type Client interface {
+	GetStacks() ([]models.Stack, error)
+	GetStackFile(id int, kind string) (string, error)
+	GetEdgeStackFile(id int) (string, error)
+	GetStackEnv(id int) ([]models.StackEnvVar, error)
+	GetEnvironments() ([]models.Environment, error)
+	GetEnvironmentGroups() ([]models.Group, error)
+	CreateStack(opts models.StackCreateOptions) (int, error)
+}
+
+// ExportOptions defines which stacks are exported
+type ExportOptions struct {
+	// StackIDs and EdgeStackIDs restrict the export to some regular and edge stacks, as Portainer numbers them
+	// separately. All the stacks are exported if both are empty.
+	StackIDs     []int
+	EdgeStackIDs []int
+	// IncludeEnvValues includes the values of the environment variables of the regular stacks
+	IncludeEnvValues bool
+}
+
+// Export reads the stacks to write in a bundle
+func Export(c Client, opts ExportOptions) ([]Stack, error) {
+	stacks, err := c.GetStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stacks: %w", err)
+	}
+
+	for _, id := range opts.StackIDs {
+		if !slices.ContainsFunc(stacks, func(stack models.Stack) bool { return stack.ID == id && stack.Kind != models.StackKindEdge }) {
+			return nil, fmt.Errorf("stack %d not found", id)
+		}
+	}
+	for _, id := range opts.EdgeStackIDs {
+		if !slices.ContainsFunc(stacks, func(stack models.Stack) bool { return stack.ID == id && stack.Kind == models.StackKindEdge }) {
+			return nil, fmt.Errorf("edge stack %d not found", id)
+		}
+	}
+	filtered := len(opts.StackIDs) > 0 || len(opts.EdgeStackIDs) > 0
+
+	environments, err := c.GetEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+	environmentNames := map[int]string{}
+	for _, environment := range environments {
+		environmentNames[environment.ID] = environment.Name
+	}
+
+	groups, err := c.GetEnvironmentGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment groups: %w", err)
+	}
+	groupNames := map[int]string{}
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+	}
+
+	result := []Stack{}
+	for _, stack := range stacks {
+		edge := stack.Kind == models.StackKindEdge
+		ids := opts.StackIDs
+		if edge {
+			ids = opts.EdgeStackIDs
+		}
+		if filtered && !slices.Contains(ids, stack.ID) {
+			continue
+		}
+
+		// A regular and an edge stack can have the same ID, the file is read from the stack of the listed kind
+		var content string
+		if edge {
+			content, err = c.GetEdgeStackFile(stack.ID)
+		} else {
+			content, err = c.GetStackFile(stack.ID, models.StackKindRegular)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the file of %s stack %d: %w", stack.Kind, stack.ID, err)
+		}
+
+		exported := Stack{
+			StackEntry: StackEntry{
+				ID:       stack.ID,
+				Name:     stack.Name,
+				Kind:     stack.Kind,
+				EnvNames: []string{},
+				Git:      stack.Git,
+			},
+			Content: content,
+		}
+
+		if edge {
+			// Edge stacks have no environment variables
+			for _, id := range stack.EnvironmentGroupIds {
+				exported.EnvironmentGroups = append(exported.EnvironmentGroups, EnvironmentGroup{ID: id, Name: groupNames[id]})
+			}
+			result = append(result, exported)
+			continue
+		}
+
+		exported.EnvironmentID = stack.EnvironmentID
+		exported.EnvironmentName = environmentNames[stack.EnvironmentID]
+
+		env, err := c.GetStackEnv(stack.ID)
+		switch {
+		case errors.Is(err, client.ErrEdgeStackEnv):
+		case err != nil:
+			return nil, fmt.Errorf("failed to get the env of stack %d: %w", stack.ID, err)
+		default:
+			for _, entry := range env {
+				exported.EnvNames = append(exported.EnvNames, entry.Name)
+			}
+			if opts.IncludeEnvValues {
+				exported.Env = append([]models.StackEnvVar{}, env...)
+			}
+		}
+
+		result = append(result, exported)
+	}
+
+	return result, nil
+}
+
+// Actions of an import plan
+const (
+	ActionCreate = "create"
+	ActionSkip   = "skip"
+	ActionError  = "error"
+)
+
+// PlannedStack is the planned import of a stack of a bundle
+type PlannedStack struct {
+	Name string `json:"name"`
+	// SourceID is the ID of the stack on the server it was exported from
+	SourceID int    `json:"source_id"`
+	Kind     string `json:"kind"`
+	Action   string `json:"action"`
+	// Reason explains why a stack is skipped or cannot be imported, or notes a limitation of its import
+	Reason              string `json:"reason,omitempty"`
+	EnvironmentID       int    `json:"environment_id,omitempty"`
+	EnvironmentGroupIds []int  `json:"environment_group_ids,omitempty"`
+	// EnvWithoutValues lists the variables that are created with an empty value, as their values are not available
+	EnvWithoutValues []string `json:"env_without_values,omitempty"`
+	// StackID is the ID of the created stack, once the plan is applied
+	StackID int `json:"stack_id,omitempty"`
+	// Error is set when the creation of the stack failed
+	Error string `json:"error,omitempty"`
+
+	opts models.StackCreateOptions
+}
+
+// ImportOptions defines how the stacks of a bundle are mapped to the target server
+type ImportOptions struct {
+	// EnvironmentMap maps the names of the environments of the bundle to environment IDs on the target server.
+	// Environments that are not mapped are matched by name.
+	EnvironmentMap map[string]int
+}
+
+// Plan computes how the stacks of a bundle would be imported on the target server, without changing anything.
+// Environments and environment groups are matched by name, and stacks that already exist are skipped.
+func Plan(c Client, stacks []Stack, opts ImportOptions) ([]PlannedStack, error) {
+	existing, err := c.GetStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stacks: %w", err)
+	}
+
+	environments, err := c.GetEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+
+	groups, err := c.GetEnvironmentGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment groups: %w", err)
+	}
+
+	plan := make([]PlannedStack, 0, len(stacks))
+	for _, stack := range stacks {
+		planned := PlannedStack{Name: stack.Name, SourceID: stack.ID, Kind: stack.Kind, Action: ActionCreate}
+
+		switch stack.Kind {
+		case models.StackKindEdge:
+			planEdgeStack(&planned, stack, groups, existing)
+		case models.StackKindCompose, models.StackKindSwarm:
+			planRegularStack(&planned, stack, environments, existing, opts)
+		default:
+			planned.Action = ActionSkip
+			planned.Reason = fmt.Sprintf("%s stacks cannot be imported", stack.Kind)
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return plan, nil
+}
+
+func planRegularStack(planned *PlannedStack, stack Stack, environments []models.Environment, existing []models.Stack, opts ImportOptions) {
+	environmentID, mapped := opts.EnvironmentMap[stack.EnvironmentName]
+	index := slices.IndexFunc(environments, func(environment models.Environment) bool {
+		if mapped {
+			return environment.ID == environmentID
+		}
+		return environment.Name == stack.EnvironmentName
+	})
+	if index < 0 {
+		planned.Action = ActionError
+		if mapped {
+			planned.Reason = fmt.Sprintf("environment %d not found on the target server", environmentID)
+		} else {
+			planned.Reason = fmt.Sprintf("no environment named %q on the target server, map it to an environment ID", stack.EnvironmentName)
+		}
+		return
+	}
+	planned.EnvironmentID = environments[index].ID
+
+	if slices.ContainsFunc(existing, func(s models.Stack) bool {
+		return s.Name == stack.Name && s.EnvironmentID == planned.EnvironmentID
+	}) {
+		planned.Action = ActionSkip
+		planned.Reason = "a stack with the same name already exists on the environment"
+		return
+	}
+
+	env := stack.Env
+	if env == nil {
+		env = make([]models.StackEnvVar, 0, len(stack.EnvNames))
+		for _, name := range stack.EnvNames {
+			env = append(env, models.StackEnvVar{Name: name})
+		}
+		planned.EnvWithoutValues = stack.EnvNames
+	}
+
+	stackType := models.StackTypeStandalone
+	if stack.Kind == models.StackKindSwarm {
+		stackType = models.StackTypeSwarm
+	}
+
+	if stack.Git != nil {
+		planned.Reason = "git stack imported from its file, it is not linked to its repository"
+	}
+
+	planned.opts = models.StackCreateOptions{
+		Name:          stack.Name,
+		File:          stack.Content,
+		EnvironmentID: planned.EnvironmentID,
+		Type:          stackType,
+		Env:           env,
+	}
+}
+
+func planEdgeStack(planned *PlannedStack, stack Stack, groups []models.Group, existing []models.Stack) {
+	for _, group := range stack.EnvironmentGroups {
+		index := slices.IndexFunc(groups, func(g models.Group) bool { return g.Name == group.Name })
+		if index < 0 {
+			planned.Action = ActionError
+			planned.Reason = fmt.Sprintf("no environment group named %q on the target server", group.Name)
+			return
+		}
+		planned.EnvironmentGroupIds = append(planned.EnvironmentGroupIds, groups[index].ID)
+	}
+	if len(planned.EnvironmentGroupIds) == 0 {
+		planned.Action = ActionError
+		planned.Reason = "the edge stack has no environment group"
+		return
+	}
+
+	if slices.ContainsFunc(existing, func(s models.Stack) bool {
+		return s.Name == stack.Name && s.Kind == models.StackKindEdge
+	}) {
+		planned.Action = ActionSkip
+		planned.Reason = "an edge stack with the same name already exists"
+		return
+	}
+
+	planned.opts = models.StackCreateOptions{
+		Name:                stack.Name,
+		File:                stack.Content,
+		EnvironmentGroupIds: planned.EnvironmentGroupIds,
+	}
+}
+
+// Apply creates the stacks of a plan. A failed creation does not stop the import of the other stacks,
+// its error is set on the planned stack.
+func Apply(c Client, plan []PlannedStack) []PlannedStack {
+	for i := range plan {
+		if plan[i].Action != ActionCreate {
+			continue
+		}
+
+		id, err := c.CreateStack(plan[i].opts)
+		if err != nil {
+			plan[i].Error = err.Error()
+			continue
+		}
+		plan[i].StackID = id
+	}
+
+	return plan
+}
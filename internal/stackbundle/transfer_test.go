@@ -0,0 +1,163 @@
+package stackbundle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/client"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is an in-memory Portainer server
+type fakeClient struct {
+	stacks       []models.Stack
+	files        map[int]string
+	edgeFiles    map[int]string
+	env          map[int][]models.StackEnvVar
+	environments []models.Environment
+	groups       []models.Group
+	created      []models.StackCreateOptions
+	createErr    map[string]error
+}
+
+func (c *fakeClient) GetStacks() ([]models.Stack, error) { return c.stacks, nil }
+
+func (c *fakeClient) GetStackFile(id int, kind string) (string, error) { return c.files[id], nil }
+
+func (c *fakeClient) GetEdgeStackFile(id int) (string, error) { return c.edgeFiles[id], nil }
+
+func (c *fakeClient) GetStackEnv(id int) ([]models.StackEnvVar, error) {
+	env, ok := c.env[id]
+	if !ok {
+		return nil, client.ErrEdgeStackEnv
+	}
+	return env, nil
+}
+
+func (c *fakeClient) GetEnvironments() ([]models.Environment, error) { return c.environments, nil }
+
+func (c *fakeClient) GetEnvironmentGroups() ([]models.Group, error) { return c.groups, nil }
+
+func (c *fakeClient) CreateStack(opts models.StackCreateOptions) (int, error) {
+	if err := c.createErr[opts.Name]; err != nil {
+		return 0, err
+	}
+	c.created = append(c.created, opts)
+	return 100 + len(c.created), nil
+}
+
+func TestExport(t *testing.T) {
+	source := &fakeClient{
+		stacks: []models.Stack{
+			{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 3},
+			{ID: 2, Name: "agent", Kind: models.StackKindEdge, EnvironmentGroupIds: []int{4}},
+			{ID: 5, Name: "other", Kind: models.StackKindSwarm, EnvironmentID: 3},
+		},
+		files:        map[int]string{1: "web file", 5: "other file"},
+		edgeFiles:    map[int]string{2: "agent file"},
+		env:          map[int][]models.StackEnvVar{1: {{Name: "TOKEN", Value: "secret"}}, 5: {}},
+		environments: []models.Environment{{ID: 3, Name: "staging"}},
+		groups:       []models.Group{{ID: 4, Name: "stores"}},
+	}
+
+	stacks, err := Export(source, ExportOptions{StackIDs: []int{1}, EdgeStackIDs: []int{2}})
+	require.NoError(t, err)
+	require.Len(t, stacks, 2)
+
+	assert.Equal(t, "staging", stacks[0].EnvironmentName)
+	assert.Equal(t, []string{"TOKEN"}, stacks[0].EnvNames)
+	assert.Nil(t, stacks[0].Env, "values must only be exported when requested")
+	assert.Equal(t, []EnvironmentGroup{{ID: 4, Name: "stores"}}, stacks[1].EnvironmentGroups)
+	assert.Equal(t, "agent file", stacks[1].Content)
+
+	stacks, err = Export(source, ExportOptions{IncludeEnvValues: true})
+	require.NoError(t, err)
+	require.Len(t, stacks, 3)
+	assert.Equal(t, []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}, stacks[0].Env)
+	assert.Nil(t, stacks[1].Env, "edge stacks do not have env values")
+	assert.Equal(t, []models.StackEnvVar{}, stacks[2].Env)
+
+	_, err = Export(source, ExportOptions{StackIDs: []int{9}})
+	assert.ErrorContains(t, err, "stack 9 not found")
+
+	_, err = Export(source, ExportOptions{StackIDs: []int{2}})
+	assert.ErrorContains(t, err, "stack 2 not found", "stackIds must only match regular stacks")
+}
+
+func TestExportSameIDs(t *testing.T) {
+	source := &fakeClient{
+		stacks: []models.Stack{
+			{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentID: 3},
+			{ID: 1, Name: "agent", Kind: models.StackKindEdge, EnvironmentGroupIds: []int{4}},
+		},
+		files:        map[int]string{1: "web file"},
+		edgeFiles:    map[int]string{1: "agent file"},
+		env:          map[int][]models.StackEnvVar{1: {{Name: "TOKEN", Value: "secret"}}},
+		environments: []models.Environment{{ID: 3, Name: "staging"}},
+		groups:       []models.Group{{ID: 4, Name: "stores"}},
+	}
+
+	stacks, err := Export(source, ExportOptions{IncludeEnvValues: true})
+	require.NoError(t, err)
+	require.Len(t, stacks, 2)
+	assert.Equal(t, "web file", stacks[0].Content)
+	assert.Equal(t, models.StackKindEdge, stacks[1].Kind)
+	assert.Equal(t, "agent file", stacks[1].Content)
+	assert.Empty(t, stacks[1].EnvNames, "the env of the regular stack with the same ID must not be exported with the edge stack")
+	assert.Nil(t, stacks[1].Env)
+
+	stacks, err = Export(source, ExportOptions{EdgeStackIDs: []int{1}})
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	assert.Equal(t, "agent", stacks[0].Name)
+}
+
+func TestPlanAndApply(t *testing.T) {
+	stacks := []Stack{
+		{StackEntry: StackEntry{ID: 1, Name: "web", Kind: models.StackKindCompose, EnvironmentName: "staging", EnvNames: []string{"TOKEN"}}, Content: "web file"},
+		{StackEntry: StackEntry{ID: 2, Name: "agent", Kind: models.StackKindEdge, EnvironmentGroups: []EnvironmentGroup{{ID: 4, Name: "stores"}}}, Content: "agent file"},
+		{StackEntry: StackEntry{ID: 3, Name: "db", Kind: models.StackKindSwarm, EnvironmentName: "swarm"}, Content: "db file", Env: []models.StackEnvVar{{Name: "PASSWORD", Value: "secret"}}},
+		{StackEntry: StackEntry{ID: 4, Name: "existing", Kind: models.StackKindCompose, EnvironmentName: "staging"}},
+		{StackEntry: StackEntry{ID: 5, Name: "lost", Kind: models.StackKindCompose, EnvironmentName: "qa"}},
+		{StackEntry: StackEntry{ID: 6, Name: "k8s", Kind: models.StackKindKubernetes, EnvironmentName: "cluster"}},
+		{StackEntry: StackEntry{ID: 7, Name: "broken", Kind: models.StackKindCompose, EnvironmentName: "staging"}, Content: "broken file"},
+	}
+
+	target := &fakeClient{
+		stacks:       []models.Stack{{ID: 40, Name: "existing", Kind: models.StackKindCompose, EnvironmentID: 10}},
+		environments: []models.Environment{{ID: 10, Name: "staging"}, {ID: 11, Name: "production-swarm"}},
+		groups:       []models.Group{{ID: 20, Name: "stores"}},
+		createErr:    map[string]error{"broken": errors.New("invalid stack file")},
+	}
+
+	plan, err := Plan(target, stacks, ImportOptions{EnvironmentMap: map[string]int{"swarm": 11}})
+	require.NoError(t, err)
+	require.Len(t, plan, 7)
+	assert.Empty(t, target.created, "planning must not create stacks")
+
+	assert.Equal(t, ActionCreate, plan[0].Action)
+	assert.Equal(t, 10, plan[0].EnvironmentID)
+	assert.Equal(t, []string{"TOKEN"}, plan[0].EnvWithoutValues)
+	assert.Equal(t, ActionCreate, plan[1].Action)
+	assert.Equal(t, []int{20}, plan[1].EnvironmentGroupIds)
+	assert.Equal(t, ActionCreate, plan[2].Action)
+	assert.Equal(t, 11, plan[2].EnvironmentID)
+	assert.Empty(t, plan[2].EnvWithoutValues)
+	assert.Equal(t, ActionSkip, plan[3].Action)
+	assert.Equal(t, ActionError, plan[4].Action)
+	assert.Contains(t, plan[4].Reason, `no environment named "qa"`)
+	assert.Equal(t, ActionSkip, plan[5].Action)
+
+	plan = Apply(target, plan)
+	require.Len(t, target.created, 3)
+	assert.Equal(t, models.StackCreateOptions{Name: "web", File: "web file", EnvironmentID: 10, Type: models.StackTypeStandalone, Env: []models.StackEnvVar{{Name: "TOKEN"}}}, target.created[0])
+	assert.Equal(t, models.StackCreateOptions{Name: "agent", File: "agent file", EnvironmentGroupIds: []int{20}}, target.created[1])
+	assert.Equal(t, models.StackTypeSwarm, target.created[2].Type)
+	assert.Equal(t, []models.StackEnvVar{{Name: "PASSWORD", Value: "secret"}}, target.created[2].Env)
+
+	assert.Equal(t, 101, plan[0].StackID)
+	assert.Equal(t, 0, plan[3].StackID)
+	assert.Equal(t, "invalid stack file", plan[6].Error)
+}
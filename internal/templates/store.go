@@ -0,0 +1,117 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Store keeps the local templates, persisted as a JSON file.
+// A Store created with an empty path is kept in memory only.
+type Store struct {
+	path string
+
+	mu        sync.Mutex
+	templates []Template
+}
+
+// Open loads the templates stored at path, or creates an empty store if the file does not exist.
+// The parent directory is created if needed.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	if path == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create stack templates directory: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack templates: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.templates); err != nil {
+		return nil, fmt.Errorf("failed to parse stack templates %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// List returns the local templates, sorted by name
+func (s *Store) List() []Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.templates)
+}
+
+// Get returns the local template with the given name
+func (s *Store) Get(name string) (Template, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return Template{}, false
+}
+
+// Save adds a local template, replacing the template with the same name if any
+func (s *Store) Save(t Template) (Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.ID = LocalID(t.Name)
+	t.Source = SourceLocal
+
+	templates := slices.DeleteFunc(slices.Clone(s.templates), func(existing Template) bool {
+		return existing.Name == t.Name
+	})
+	templates = append(templates, t)
+	slices.SortFunc(templates, func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if err := s.save(templates); err != nil {
+		return Template{}, err
+	}
+	s.templates = templates
+
+	return t, nil
+}
+
+// save writes the templates to the file of the store, replacing it atomically. The caller must hold s.mu.
+func (s *Store) save(templates []Template) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stack templates: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write stack templates: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace stack templates: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "stack-templates.json")
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	assert.Empty(t, store.List())
+
+	saved, err := store.Save(testTemplate())
+	require.NoError(t, err)
+	assert.Equal(t, "local:web", saved.ID)
+	assert.Equal(t, SourceLocal, saved.Source)
+
+	other := testTemplate()
+	other.Name = "api"
+	_, err = store.Save(other)
+	require.NoError(t, err)
+
+	replaced := testTemplate()
+	replaced.Description = "Web server"
+	_, err = store.Save(replaced)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+
+	templates := reopened.List()
+	require.Len(t, templates, 2)
+	assert.Equal(t, "api", templates[0].Name)
+	assert.Equal(t, "web", templates[1].Name)
+
+	web, ok := reopened.Get("web")
+	require.True(t, ok)
+	assert.Equal(t, "Web server", web.Description)
+	assert.Equal(t, testFile, web.File)
+
+	_, ok = reopened.Get("db")
+	assert.False(t, ok)
+}
+
+func TestStoreInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stack-templates.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := Open(path)
+	assert.ErrorContains(t, err, "failed to parse stack templates")
+}
@@ -0,0 +1,261 @@
+// Package templates renders stack templates: compose files with typed variables,
+// stored locally or as Portainer custom templates.
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Sources of a template
+const (
+	SourceLocal     = "local"
+	SourcePortainer = "portainer"
+)
+
+// Types of the variables of a template
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeBool   = "bool"
+	TypePort   = "port"
+	TypeImage  = "image"
+)
+
+var variableTypes = []string{TypeString, TypeInt, TypeBool, TypePort, TypeImage}
+
+// Variable is a variable declared by a template.
+// A variable without a default value is required.
+type Variable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Required returns true if a value must be given for the variable
+func (v Variable) Required() bool {
+	return v.Default == ""
+}
+
+// Template is a compose file whose {{ name }} placeholders are replaced by the values of its variables
+type Template struct {
+	// ID identifies the template across sources, local:<name> or portainer:<custom template ID>
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"`
+	// Type is the type of the stacks deployed from the template, standalone or swarm
+	Type      string     `json:"type"`
+	Variables []Variable `json:"variables"`
+	File      string     `json:"file,omitempty"`
+}
+
+var (
+	namePattern        = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	variablePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	imagePattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@-]*$`)
+	typePrefixPattern  = regexp.MustCompile(`^\[([a-z]+)\]\s*`)
+)
+
+// LocalID returns the ID of a local template
+func LocalID(name string) string {
+	return SourceLocal + ":" + name
+}
+
+// PortainerID returns the ID of a template stored as a Portainer custom template
+func PortainerID(id int) string {
+	return SourcePortainer + ":" + strconv.Itoa(id)
+}
+
+// ParseID returns the source of a template and its reference in the source:
+// the name of a local template, or the ID of a Portainer custom template
+func ParseID(id string) (string, string, error) {
+	source, ref, ok := strings.Cut(id, ":")
+	if !ok || ref == "" || (source != SourceLocal && source != SourcePortainer) {
+		return "", "", fmt.Errorf("invalid template ID %q, expected local:<name> or portainer:<id>", id)
+	}
+	if source == SourcePortainer {
+		if _, err := strconv.Atoi(ref); err != nil {
+			return "", "", fmt.Errorf("invalid template ID %q, expected local:<name> or portainer:<id>", id)
+		}
+	}
+	return source, ref, nil
+}
+
+// Check returns an error if a template is not valid: its name, its type and its variables
+// must be valid, and all the placeholders of its file must be declared variables
+func Check(t Template) error {
+	if !namePattern.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q, only letters, digits, '.', '_' and '-' are allowed", t.Name)
+	}
+	if t.Type != models.StackTypeStandalone && t.Type != models.StackTypeSwarm {
+		return fmt.Errorf("invalid template type: %s", t.Type)
+	}
+	if strings.TrimSpace(t.File) == "" {
+		return fmt.Errorf("the template file is empty")
+	}
+
+	declared := map[string]bool{}
+	for _, variable := range t.Variables {
+		if !variablePattern.MatchString(variable.Name) {
+			return fmt.Errorf("invalid variable name %q", variable.Name)
+		}
+		if declared[variable.Name] {
+			return fmt.Errorf("variable %s is declared more than once", variable.Name)
+		}
+		declared[variable.Name] = true
+
+		if !slices.Contains(variableTypes, variable.Type) {
+			return fmt.Errorf("invalid type %q of variable %s, expected one of %s", variable.Type, variable.Name, strings.Join(variableTypes, ", "))
+		}
+		if variable.Default != "" {
+			if err := checkValue(variable, variable.Default); err != nil {
+				return fmt.Errorf("invalid default value: %w", err)
+			}
+		}
+	}
+
+	for _, name := range Placeholders(t.File) {
+		if !declared[name] {
+			return fmt.Errorf("the template file uses the undeclared variable %s", name)
+		}
+	}
+
+	return nil
+}
+
+// Placeholders returns the names of the variables used in a template file, in order of first use
+func Placeholders(file string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(file, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Render replaces the placeholders of a template file with the given values, or the defaults of the variables.
+// It returns an error if a value is missing for a required variable, if a value is given for an undeclared
+// variable, or if a value does not match the type of its variable.
+func Render(t Template, values map[string]string) (string, error) {
+	resolved := make(map[string]string, len(t.Variables))
+	for _, variable := range t.Variables {
+		value, ok := values[variable.Name]
+		if !ok {
+			if variable.Required() {
+				return "", fmt.Errorf("a value is required for variable %s", variable.Name)
+			}
+			value = variable.Default
+		}
+		if err := checkValue(variable, value); err != nil {
+			return "", err
+		}
+		resolved[variable.Name] = value
+	}
+
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return "", fmt.Errorf("the template does not declare variable %s", name)
+		}
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(t.File, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := resolved[name]; ok {
+			return value
+		}
+		return placeholder
+	}), nil
+}
+
+// checkValue returns an error if a value does not match the type of its variable.
+// Values are inserted in a YAML file, so they must fit on a single line.
+func checkValue(variable Variable, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("the value of variable %s must be on a single line", variable.Name)
+	}
+
+	switch variable.Type {
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("the value of variable %s must be an integer: %q", variable.Name, value)
+		}
+	case TypeBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("the value of variable %s must be true or false: %q", variable.Name, value)
+		}
+	case TypePort:
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("the value of variable %s must be a port between 1 and 65535: %q", variable.Name, value)
+		}
+	case TypeImage:
+		if !imagePattern.MatchString(value) {
+			return fmt.Errorf("the value of variable %s must be an image reference: %q", variable.Name, value)
+		}
+	}
+
+	return nil
+}
+
+// FromCustomTemplate returns the template of a Portainer custom template.
+// Portainer does not type the variables of custom templates, the type is read from a [type] prefix
+// of their description, and defaults to string.
+func FromCustomTemplate(customTemplate models.CustomTemplate, file string) Template {
+	t := Template{
+		ID:          PortainerID(customTemplate.ID),
+		Name:        customTemplate.Title,
+		Description: customTemplate.Description,
+		Source:      SourcePortainer,
+		Type:        customTemplate.Type,
+		Variables:   make([]Variable, 0, len(customTemplate.Variables)),
+		File:        file,
+	}
+
+	for _, customVariable := range customTemplate.Variables {
+		variable := Variable{
+			Name:        customVariable.Name,
+			Type:        TypeString,
+			Default:     customVariable.DefaultValue,
+			Description: customVariable.Description,
+		}
+		if match := typePrefixPattern.FindStringSubmatch(variable.Description); match != nil && slices.Contains(variableTypes, match[1]) {
+			variable.Type = match[1]
+			variable.Description = variable.Description[len(match[0]):]
+		}
+		t.Variables = append(t.Variables, variable)
+	}
+
+	return t
+}
+
+// ToCustomTemplate returns the options to create a Portainer custom template from a template,
+// keeping the type of each variable as a [type] prefix of its description
+func ToCustomTemplate(t Template) models.CustomTemplateCreateOptions {
+	opts := models.CustomTemplateCreateOptions{
+		Title:       t.Name,
+		Description: t.Description,
+		Type:        t.Type,
+		File:        t.File,
+		Variables:   make([]models.CustomTemplateVariable, 0, len(t.Variables)),
+	}
+
+	for _, variable := range t.Variables {
+		opts.Variables = append(opts.Variables, models.CustomTemplateVariable{
+			Name:         variable.Name,
+			Label:        variable.Name,
+			DefaultValue: variable.Default,
+			Description:  strings.TrimSpace(fmt.Sprintf("[%s] %s", variable.Type, variable.Description)),
+		})
+	}
+
+	return opts
+}
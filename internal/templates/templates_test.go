@@ -0,0 +1,178 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = `services:
+  web:
+    image: {{ image }}
+    ports:
+      - "{{port}}:80"
+    deploy:
+      replicas: {{ replicas }}
+`
+
+func testTemplate() Template {
+	return Template{
+		Name: "web",
+		Type: models.StackTypeStandalone,
+		Variables: []Variable{
+			{Name: "image", Type: TypeImage},
+			{Name: "port", Type: TypePort, Default: "8080"},
+			{Name: "replicas", Type: TypeInt, Default: "1"},
+		},
+		File: testFile,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	require.NoError(t, Check(testTemplate()))
+
+	tests := []struct {
+		name   string
+		modify func(*Template)
+		err    string
+	}{
+		{
+			name:   "invalid name",
+			modify: func(t *Template) { t.Name = "web app" },
+			err:    `invalid template name "web app"`,
+		},
+		{
+			name:   "invalid type",
+			modify: func(t *Template) { t.Type = "kubernetes" },
+			err:    "invalid template type: kubernetes",
+		},
+		{
+			name:   "undeclared variable",
+			modify: func(t *Template) { t.Variables = t.Variables[:2] },
+			err:    "the template file uses the undeclared variable replicas",
+		},
+		{
+			name:   "duplicate variable",
+			modify: func(t *Template) { t.Variables = append(t.Variables, Variable{Name: "port", Type: TypeString}) },
+			err:    "variable port is declared more than once",
+		},
+		{
+			name:   "invalid variable type",
+			modify: func(t *Template) { t.Variables[0].Type = "float" },
+			err:    `invalid type "float" of variable image`,
+		},
+		{
+			name:   "invalid default",
+			modify: func(t *Template) { t.Variables[1].Default = "http" },
+			err:    "the value of variable port must be a port between 1 and 65535",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := testTemplate()
+			tt.modify(&template)
+			assert.ErrorContains(t, Check(template), tt.err)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	file, err := Render(testTemplate(), map[string]string{"image": "nginx:1.27", "port": "9000"})
+	require.NoError(t, err)
+	assert.Equal(t, `services:
+  web:
+    image: nginx:1.27
+    ports:
+      - "9000:80"
+    deploy:
+      replicas: 1
+`, file)
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		err    string
+	}{
+		{
+			name: "missing required value",
+			err:  "a value is required for variable image",
+		},
+		{
+			name:   "undeclared variable",
+			values: map[string]string{"image": "nginx:1.27", "tag": "1.27"},
+			err:    "the template does not declare variable tag",
+		},
+		{
+			name:   "invalid port",
+			values: map[string]string{"image": "nginx:1.27", "port": "70000"},
+			err:    "the value of variable port must be a port between 1 and 65535",
+		},
+		{
+			name:   "invalid int",
+			values: map[string]string{"image": "nginx:1.27", "replicas": "two"},
+			err:    "the value of variable replicas must be an integer",
+		},
+		{
+			name:   "invalid image",
+			values: map[string]string{"image": "nginx latest"},
+			err:    "the value of variable image must be an image reference",
+		},
+		{
+			name:   "multi-line value",
+			values: map[string]string{"image": "nginx\n    privileged: true"},
+			err:    "the value of variable image must be on a single line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render(testTemplate(), tt.values)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseID(t *testing.T) {
+	source, ref, err := ParseID("local:web")
+	require.NoError(t, err)
+	assert.Equal(t, SourceLocal, source)
+	assert.Equal(t, "web", ref)
+
+	source, ref, err = ParseID(PortainerID(12))
+	require.NoError(t, err)
+	assert.Equal(t, SourcePortainer, source)
+	assert.Equal(t, "12", ref)
+
+	for _, id := range []string{"web", "portainer:web", "git:web", "local:"} {
+		_, _, err := ParseID(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestCustomTemplateRoundTrip(t *testing.T) {
+	template := testTemplate()
+	template.Description = "Web server"
+	template.Variables[0].Description = "The image to run"
+
+	opts := ToCustomTemplate(template)
+	assert.Equal(t, "[image] The image to run", opts.Variables[0].Description)
+	assert.Equal(t, "[port]", opts.Variables[1].Description)
+
+	customTemplate := models.CustomTemplate{
+		ID:          12,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Type:        opts.Type,
+		Variables:   append(opts.Variables, models.CustomTemplateVariable{Name: "domain", Description: "Created in Portainer"}),
+	}
+
+	got := FromCustomTemplate(customTemplate, testFile)
+	assert.Equal(t, "portainer:12", got.ID)
+	assert.Equal(t, SourcePortainer, got.Source)
+	assert.Equal(t, template.Variables, got.Variables[:3])
+	assert.Equal(t, Variable{Name: "domain", Type: TypeString, Description: "Created in Portainer"}, got.Variables[3])
+	assert.Equal(t, testFile, got.File)
+}
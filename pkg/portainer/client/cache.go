@@ -0,0 +1,155 @@
+package client
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// Keys of the list calls cached by the client, to be used with InvalidateCache
+const (
+	CacheKeyEnvironments = "environments"
+	CacheKeyTags         = "tags"
+	CacheKeyTeams        = "teams"
+	CacheKeyUsers        = "users"
+)
+
+// Metric names reported for the list cache
+const (
+	// MetricCacheHits is the number of list calls served from the cache
+	MetricCacheHits = "client.cache.hits"
+	// MetricCacheMisses is the number of list calls sent to the Portainer server while the cache is enabled
+	MetricCacheMisses = "client.cache.misses"
+)
+
+// cacheEntry is a cached list with its expiration time
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// listCache is a TTL cache for the results of list calls.
+// A nil cache does not cache anything.
+type listCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	metrics MetricsRecorder
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newListCache(ttl time.Duration, metrics MetricsRecorder) *listCache {
+	return &listCache{
+		ttl:     ttl,
+		now:     time.Now,
+		metrics: metrics,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns the cached value for key if it has not expired
+func (c *listCache) get(key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		c.metrics.Add(MetricCacheMisses, 1)
+		return nil, false
+	}
+
+	c.metrics.Add(MetricCacheHits, 1)
+	return entry.value, true
+}
+
+// set caches value for key until the TTL expires
+func (c *listCache) set(key string, value any) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{value: value, expires: c.now().Add(c.ttl)}
+}
+
+// invalidate removes the given keys from the cache, or all the keys if none is given
+func (c *listCache) invalidate(keys ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(keys) == 0 {
+		clear(c.entries)
+		return
+	}
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// cachedList returns the cached list for key, or loads it and caches it.
+// Callers get their own deep copy of the list so that they cannot modify the cached one: clone copies
+// the slices and maps held by an item, and can be nil if items do not hold any.
+func cachedList[T any](cache *listCache, key string, load func() ([]T, error), clone func(T) T) ([]T, error) {
+	if value, ok := cache.get(key); ok {
+		return cloneList(value.([]T), clone), nil
+	}
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.set(key, cloneList(list, clone))
+	return list, nil
+}
+
+// cloneList returns a copy of list, with each item copied by clone if it is not nil
+func cloneList[T any](list []T, clone func(T) T) []T {
+	copied := slices.Clone(list)
+	if clone != nil {
+		for i, item := range copied {
+			copied[i] = clone(item)
+		}
+	}
+	return copied
+}
+
+func cloneEnvironment(environment models.Environment) models.Environment {
+	environment.TagIds = slices.Clone(environment.TagIds)
+	environment.UserAccesses = maps.Clone(environment.UserAccesses)
+	environment.TeamAccesses = maps.Clone(environment.TeamAccesses)
+	return environment
+}
+
+func cloneEnvironmentTag(tag models.EnvironmentTag) models.EnvironmentTag {
+	tag.EnvironmentIds = slices.Clone(tag.EnvironmentIds)
+	return tag
+}
+
+func cloneTeam(team models.Team) models.Team {
+	team.MemberIDs = slices.Clone(team.MemberIDs)
+	return team
+}
+
+// InvalidateCache removes the given keys (e.g. CacheKeyEnvironments) from the list cache,
+// or clears the whole cache if no key is given. The next list calls are sent to the Portainer server.
+// It does nothing if the cache is disabled.
+func (c *PortainerClient) InvalidateCache(keys ...string) {
+	c.cache.invalidate(keys...)
+}
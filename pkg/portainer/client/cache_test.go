@@ -0,0 +1,196 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestListCache creates a list cache with a clock that can be advanced by the test
+func newTestListCache(ttl time.Duration, recorder MetricsRecorder) (*listCache, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newListCache(ttl, recorder)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestListCache(t *testing.T) {
+	recorder := newTestMetricsRecorder()
+	cache, now := newTestListCache(time.Minute, recorder)
+
+	_, ok := cache.get(CacheKeyTags)
+	assert.False(t, ok, "empty cache must miss")
+
+	cache.set(CacheKeyTags, []int{1})
+	value, ok := cache.get(CacheKeyTags)
+	assert.True(t, ok)
+	assert.Equal(t, []int{1}, value)
+
+	*now = now.Add(time.Minute)
+	_, ok = cache.get(CacheKeyTags)
+	assert.False(t, ok, "expired entries must miss")
+
+	assert.Equal(t, int64(1), recorder.get(MetricCacheHits))
+	assert.Equal(t, int64(2), recorder.get(MetricCacheMisses))
+}
+
+func TestListCacheInvalidate(t *testing.T) {
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+
+	cache.set(CacheKeyTags, []int{1})
+	cache.set(CacheKeyTeams, []int{2})
+	cache.set(CacheKeyUsers, []int{3})
+
+	cache.invalidate(CacheKeyTags)
+	_, ok := cache.get(CacheKeyTags)
+	assert.False(t, ok)
+	_, ok = cache.get(CacheKeyTeams)
+	assert.True(t, ok, "other keys must be kept")
+
+	cache.invalidate()
+	_, ok = cache.get(CacheKeyTeams)
+	assert.False(t, ok)
+	_, ok = cache.get(CacheKeyUsers)
+	assert.False(t, ok)
+}
+
+func TestNilListCache(t *testing.T) {
+	var cache *listCache
+
+	cache.set(CacheKeyTags, []int{1})
+	_, ok := cache.get(CacheKeyTags)
+	assert.False(t, ok)
+	cache.invalidate()
+
+	client := &PortainerClient{}
+	client.InvalidateCache(CacheKeyTags)
+}
+
+func TestCachedList(t *testing.T) {
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+
+	calls := 0
+	load := func() ([]int, error) {
+		calls++
+		return []int{1, 2}, nil
+	}
+
+	list, err := cachedList(cache, CacheKeyTags, load, nil)
+	require.NoError(t, err)
+	list[0] = 42
+
+	list, err = cachedList(cache, CacheKeyTags, load, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, list, "callers must not be able to modify the cached list")
+	assert.Equal(t, 1, calls)
+
+	_, err = cachedList(cache, CacheKeyTeams, func() ([]int, error) {
+		return nil, errors.New("api error")
+	}, nil)
+	require.Error(t, err)
+	_, ok := cache.get(CacheKeyTeams)
+	assert.False(t, ok, "errors must not be cached")
+}
+
+func TestCachedListDeepCopy(t *testing.T) {
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+
+	load := func() ([]models.Environment, error) {
+		return []models.Environment{{ID: 1, TagIds: []int{1}, UserAccesses: map[int]string{1: "admin"}}}, nil
+	}
+
+	list, err := cachedList(cache, CacheKeyEnvironments, load, cloneEnvironment)
+	require.NoError(t, err)
+	list[0].TagIds[0] = 42
+	list[0].UserAccesses[2] = "user"
+
+	list, err = cachedList(cache, CacheKeyEnvironments, load, cloneEnvironment)
+	require.NoError(t, err)
+	list[0].TagIds[0] = 43
+	list[0].UserAccesses[3] = "user"
+
+	list, err = cachedList(cache, CacheKeyEnvironments, load, cloneEnvironment)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, list[0].TagIds, "callers must not be able to modify the cached items")
+	assert.Equal(t, map[int]string{1: "admin"}, list[0].UserAccesses)
+}
+
+func TestGetEnvironmentTagsCache(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListTags").Return([]*apimodels.PortainerTag{{ID: 1, Name: "tag"}}, nil)
+	mockAPI.On("CreateTag", "new").Return(int64(2), nil)
+
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+	client := &PortainerClient{cli: mockAPI, cache: cache}
+
+	for range 2 {
+		tags, err := client.GetEnvironmentTags()
+		require.NoError(t, err)
+		assert.Len(t, tags, 1)
+	}
+	mockAPI.AssertNumberOfCalls(t, "ListTags", 1)
+
+	_, err := client.CreateEnvironmentTag("new")
+	require.NoError(t, err)
+
+	_, err = client.GetEnvironmentTags()
+	require.NoError(t, err)
+	mockAPI.AssertNumberOfCalls(t, "ListTags", 2)
+
+	client.InvalidateCache(CacheKeyTags)
+	_, err = client.GetEnvironmentTags()
+	require.NoError(t, err)
+	mockAPI.AssertNumberOfCalls(t, "ListTags", 3)
+}
+
+func TestUpdateEnvironmentTagsInvalidatesTags(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListTags").Return([]*apimodels.PortainerTag{{ID: 1, Name: "tag"}}, nil).Once()
+	mockAPI.On("ListTags").Return([]*apimodels.PortainerTag{{ID: 1, Name: "tag", Endpoints: map[string]bool{"1": true}}}, nil).Once()
+	mockAPI.On("UpdateEndpoint", int64(1), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+	client := &PortainerClient{cli: mockAPI, cache: cache}
+
+	tags, err := client.GetEnvironmentTags()
+	require.NoError(t, err)
+	assert.Empty(t, tags[0].EnvironmentIds)
+
+	err = client.UpdateEnvironmentTags(1, []int{1})
+	require.NoError(t, err)
+
+	tags, err = client.GetEnvironmentTags()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, tags[0].EnvironmentIds, "the tag memberships changed by the update must be reloaded")
+	mockAPI.AssertNumberOfCalls(t, "ListTags", 2)
+}
+
+func TestGetTeamsCache(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListTeams").Return([]*apimodels.PortainerTeam{{ID: 1, Name: "team"}}, nil)
+	mockAPI.On("ListTeamMemberships").Return([]*apimodels.PortainerTeamMembership{}, nil)
+	mockAPI.On("CreateTeamMembership", 1, 100).Return(errors.New("api error"))
+
+	cache, _ := newTestListCache(time.Minute, noopMetricsRecorder{})
+	client := &PortainerClient{cli: mockAPI, cache: cache}
+
+	for range 2 {
+		_, err := client.GetTeams()
+		require.NoError(t, err)
+	}
+	mockAPI.AssertNumberOfCalls(t, "ListTeams", 1)
+	mockAPI.AssertNumberOfCalls(t, "ListTeamMemberships", 1)
+
+	err := client.UpdateTeamMembers(1, []int{100})
+	require.Error(t, err)
+
+	_, err = client.GetTeams()
+	require.NoError(t, err)
+	mockAPI.AssertNumberOfCalls(t, "ListTeams", 2)
+}
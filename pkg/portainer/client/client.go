@@ -2,6 +2,7 @@ package client
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/portainer/client-api-go/v2/client"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
@@ -47,6 +48,12 @@ type PortainerClient struct {
 	serverURL     string
 	token         string
 	skipTLSVerify bool
+	limiter       *concurrencyLimiter
+	retrier       *retrier
+	httpClient    *http.Client
+	readTimeout   time.Duration
+	metrics       MetricsRecorder
+	cache         *listCache
 }
 
 // ClientOption defines a function that configures a PortainerClient.
@@ -54,7 +61,11 @@ type ClientOption func(*clientOptions)
 
 // clientOptions holds configuration options for the PortainerClient.
 type clientOptions struct {
-	skipTLSVerify bool
+	skipTLSVerify         bool
+	maxConcurrentRequests int
+	metrics               MetricsRecorder
+	retryPolicy           RetryPolicy
+	cacheTTL              time.Duration
 }
 
 // WithSkipTLSVerify configures whether to skip TLS certificate verification.
@@ -65,6 +76,38 @@ func WithSkipTLSVerify(skip bool) ClientOption {
 	}
 }
 
+// WithMaxConcurrentRequests caps the number of requests that can be in flight
+// to the Portainer server at the same time. A value of 0 or less disables the cap.
+// Requests that cannot get a slot in time fail with a ConcurrencyLimitError.
+func WithMaxConcurrentRequests(max int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxConcurrentRequests = max
+	}
+}
+
+// WithMetrics configures a recorder that receives the metrics reported by the client.
+func WithMetrics(metrics MetricsRecorder) ClientOption {
+	return func(o *clientOptions) {
+		o.metrics = metrics
+	}
+}
+
+// WithRetryPolicy configures the retries of idempotent requests failing with a transient error.
+// DefaultRetryPolicy is used if this option is not set.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithCacheTTL enables the caching of the environment, tag, team and user lists for the given duration.
+// Writes made through the client invalidate the affected lists. A value of 0 or less disables the cache.
+func WithCacheTTL(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
 // NewPortainerClient creates a new PortainerClient instance with the provided
 // server URL and authentication token.
 //
@@ -78,16 +121,40 @@ func WithSkipTLSVerify(skip bool) ClientOption {
 func NewPortainerClient(serverURL string, token string, opts ...ClientOption) *PortainerClient {
 	options := clientOptions{
 		skipTLSVerify: false, // Default to secure TLS verification
+		metrics:       noopMetricsRecorder{},
+		retryPolicy:   DefaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
 		opt(&options)
 	}
 
+	var cli PortainerAPIClient = client.NewPortainerClient(serverURL, token, client.WithSkipTLSVerify(options.skipTLSVerify))
+
+	var limiter *concurrencyLimiter
+	if options.maxConcurrentRequests > 0 {
+		limiter = newConcurrencyLimiter(options.maxConcurrentRequests, defaultConcurrencyWait, options.metrics)
+		cli = &limitedAPIClient{cli: cli, limiter: limiter}
+	}
+
+	// Retries are applied outside of the limiter so that each attempt waits for its own slot
+	retrier := newRetrier(options.retryPolicy, options.metrics)
+	cli = &retryAPIClient{cli: cli, retrier: retrier}
+
+	var cache *listCache
+	if options.cacheTTL > 0 {
+		cache = newListCache(options.cacheTTL, options.metrics)
+	}
+
 	return &PortainerClient{
-		cli:           client.NewPortainerClient(serverURL, token, client.WithSkipTLSVerify(options.skipTLSVerify)),
+		cli:           cli,
 		serverURL:     serverURL,
 		token:         token,
 		skipTLSVerify: options.skipTLSVerify,
+		limiter:       limiter,
+		retrier:       retrier,
+		httpClient:    newHTTPClient(options.skipTLSVerify),
+		metrics:       options.metrics,
+		cache:         cache,
 	}
 }
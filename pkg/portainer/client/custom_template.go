@@ -0,0 +1,98 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// customTemplatePlatformLinux is the platform of the custom templates created by the client
+const customTemplatePlatformLinux = 1
+
+// customTemplateCreatePayload is the payload of POST /custom_templates/create/string
+type customTemplateCreatePayload struct {
+	Title       string                                                 `json:"title"`
+	Description string                                                 `json:"description"`
+	Platform    int                                                    `json:"platform"`
+	Type        int64                                                  `json:"type"`
+	FileContent string                                                 `json:"fileContent"`
+	Variables   []*apimodels.PortainerCustomTemplateVariableDefinition `json:"variables"`
+}
+
+// GetCustomTemplates retrieves the custom templates of the Portainer server.
+//
+// Returns:
+//   - A slice of CustomTemplate objects
+//   - An error if the operation fails
+func (c *PortainerClient) GetCustomTemplates() ([]models.CustomTemplate, error) {
+	var rawTemplates []*apimodels.PortainereeCustomTemplate
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: "/custom_templates"}, &rawTemplates); err != nil {
+		return nil, fmt.Errorf("failed to list custom templates: %w", err)
+	}
+
+	customTemplates := make([]models.CustomTemplate, 0, len(rawTemplates))
+	for _, rawTemplate := range rawTemplates {
+		customTemplates = append(customTemplates, models.ConvertCustomTemplate(rawTemplate))
+	}
+
+	return customTemplates, nil
+}
+
+// GetCustomTemplateFile retrieves the file of a custom template.
+//
+// Parameters:
+//   - id: The ID of the custom template
+//
+// Returns:
+//   - The content of the file
+//   - An error if the operation fails
+func (c *PortainerClient) GetCustomTemplateFile(id int) (string, error) {
+	var file apimodels.CustomtemplatesFileResponse
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: fmt.Sprintf("/custom_templates/%d/file", id)}, &file); err != nil {
+		return "", fmt.Errorf("failed to get custom template file: %w", err)
+	}
+
+	return file.FileContent, nil
+}
+
+// CreateCustomTemplate creates a custom template from the content of its file.
+//
+// Parameters:
+//   - opts: The title, description, type, file and variables of the template
+//
+// Returns:
+//   - The ID of the created custom template
+//   - An error if the operation fails
+func (c *PortainerClient) CreateCustomTemplate(opts models.CustomTemplateCreateOptions) (int, error) {
+	templateType, ok := models.ConvertCustomTemplateTypeToID(opts.Type)
+	if !ok {
+		return 0, fmt.Errorf("invalid custom template type: %s", opts.Type)
+	}
+
+	payload := customTemplateCreatePayload{
+		Title:       opts.Title,
+		Description: opts.Description,
+		Platform:    customTemplatePlatformLinux,
+		Type:        templateType,
+		FileContent: opts.File,
+		Variables:   make([]*apimodels.PortainerCustomTemplateVariableDefinition, 0, len(opts.Variables)),
+	}
+	for _, variable := range opts.Variables {
+		payload.Variables = append(payload.Variables, &apimodels.PortainerCustomTemplateVariableDefinition{
+			Name:         variable.Name,
+			Label:        variable.Label,
+			DefaultValue: variable.DefaultValue,
+			Description:  variable.Description,
+		})
+	}
+
+	var created apimodels.PortainereeCustomTemplate
+	err := c.doJSON(apiRequest{method: http.MethodPost, path: "/custom_templates/create/string", body: payload}, &created)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create custom template: %w", err)
+	}
+
+	return int(created.ID), nil
+}
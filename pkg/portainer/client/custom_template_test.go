@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomTemplates(t *testing.T) {
+	var (
+		request string
+		payload map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/custom_templates":
+			_, _ = w.Write([]byte(`[{"Id": 4, "Title": "web", "Type": 2, "variables": [{"name": "port", "label": "port", "defaultValue": "8080"}]}]`))
+		case "GET /api/custom_templates/4/file":
+			_, _ = w.Write([]byte(`{"FileContent": "services: {}"}`))
+		case "POST /api/custom_templates/create/string":
+			request = r.Method + " " + r.URL.Path
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			_, _ = w.Write([]byte(`{"Id": 7}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+
+	customTemplates, err := client.GetCustomTemplates()
+	require.NoError(t, err)
+	assert.Equal(t, []models.CustomTemplate{{
+		ID:        4,
+		Title:     "web",
+		Type:      models.StackTypeStandalone,
+		Variables: []models.CustomTemplateVariable{{Name: "port", Label: "port", DefaultValue: "8080"}},
+	}}, customTemplates)
+
+	file, err := client.GetCustomTemplateFile(4)
+	require.NoError(t, err)
+	assert.Equal(t, "services: {}", file)
+
+	_, err = client.GetCustomTemplateFile(5)
+	assert.ErrorContains(t, err, "failed to get custom template file")
+
+	id, err := client.CreateCustomTemplate(models.CustomTemplateCreateOptions{
+		Title:     "db",
+		Type:      models.StackTypeSwarm,
+		File:      "services: {}",
+		Variables: []models.CustomTemplateVariable{{Name: "tag", Label: "tag", Description: "[string]"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, "POST /api/custom_templates/create/string", request)
+	assert.Equal(t, map[string]any{
+		"title":       "db",
+		"description": "",
+		"platform":    float64(1),
+		"type":        float64(1),
+		"fileContent": "services: {}",
+		"variables":   []any{map[string]any{"name": "tag", "label": "tag", "description": "[string]"}},
+	}, payload)
+
+	_, err = client.CreateCustomTemplate(models.CustomTemplateCreateOptions{Title: "db", Type: "helm"})
+	assert.ErrorContains(t, err, "invalid custom template type: helm")
+}
@@ -1,8 +1,15 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/portainer/portainer-mcp/pkg/portainer/utils"
 )
@@ -13,9 +20,222 @@ import (
 //   - A slice of Environment objects
 //   - An error if the operation fails
 func (c *PortainerClient) GetEnvironments() ([]models.Environment, error) {
-	endpoints, err := c.cli.ListEndpoints()
+	return cachedList(c.cache, CacheKeyEnvironments, func() ([]models.Environment, error) {
+		endpoints, err := c.cli.ListEndpoints()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list endpoints: %w", err)
+		}
+
+		environments := make([]models.Environment, len(endpoints))
+		for i, endpoint := range endpoints {
+			environments[i] = models.ConvertEndpointToEnvironment(endpoint)
+		}
+
+		return environments, nil
+	}, cloneEnvironment)
+}
+
+// GetEnvironment retrieves the details of an environment, including its latest snapshot.
+//
+// Parameters:
+//   - id: The ID of the environment
+//
+// Returns:
+//   - The details of the environment
+//   - An error if the operation fails
+func (c *PortainerClient) GetEnvironment(id int) (models.EnvironmentDetails, error) {
+	endpoint, err := c.cli.GetEndpoint(int64(id))
 	if err != nil {
-		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+		return models.EnvironmentDetails{}, fmt.Errorf("failed to get endpoint: %w", err)
+	}
+
+	return models.ConvertEndpointToEnvironmentDetails(endpoint), nil
+}
+
+// defaultTunnelServerPort is the default port of the Portainer tunnel server used by edge agents
+const defaultTunnelServerPort = "8000"
+
+// CreateEnvironment creates an agent or edge agent environment.
+// For edge agent environments, the URL defaults to the URL of the Portainer server used by the client
+// and the tunnel server address defaults to the host of that URL on port 8000.
+//
+// Parameters:
+//   - opts: The settings of the environment to create
+//
+// Returns:
+//   - The created environment, with its edge key for edge agent environments
+//   - An error if the operation fails
+func (c *PortainerClient) CreateEnvironment(opts models.EnvironmentCreateOptions) (models.CreatedEnvironment, error) {
+	if opts.Name == "" {
+		return models.CreatedEnvironment{}, fmt.Errorf("environment name is required")
+	}
+
+	form := url.Values{}
+	form.Set("Name", opts.Name)
+
+	switch opts.Kind {
+	case models.EnvironmentKindAgent:
+		if opts.URL == "" {
+			return models.CreatedEnvironment{}, fmt.Errorf("url is required for agent environments")
+		}
+		// Agents always use TLS with a self-signed certificate
+		form.Set("EndpointCreationType", "2")
+		form.Set("URL", opts.URL)
+		form.Set("TLS", "true")
+		form.Set("TLSSkipVerify", "true")
+		form.Set("TLSSkipClientVerify", "true")
+
+	case models.EnvironmentKindEdgeAgent:
+		portainerURL := opts.URL
+		if portainerURL == "" {
+			portainerURL = c.baseURL()
+		}
+		tunnelServerAddress := opts.TunnelServerAddress
+		if tunnelServerAddress == "" {
+			parsed, err := url.Parse(portainerURL)
+			if err != nil || parsed.Hostname() == "" {
+				return models.CreatedEnvironment{}, fmt.Errorf("invalid portainer url: %s", portainerURL)
+			}
+			tunnelServerAddress = net.JoinHostPort(parsed.Hostname(), defaultTunnelServerPort)
+		}
+
+		form.Set("EndpointCreationType", "4")
+		form.Set("URL", portainerURL)
+		form.Set("EdgeTunnelServerAddress", tunnelServerAddress)
+		if opts.EdgeCheckinInterval > 0 {
+			form.Set("EdgeCheckinInterval", strconv.Itoa(opts.EdgeCheckinInterval))
+		}
+
+	default:
+		return models.CreatedEnvironment{}, fmt.Errorf("invalid environment kind: %s", opts.Kind)
+	}
+
+	if opts.PublicURL != "" {
+		form.Set("PublicURL", opts.PublicURL)
+	}
+	if opts.GroupID > 0 {
+		form.Set("GroupID", strconv.Itoa(opts.GroupID))
+	}
+	if len(opts.TagIds) > 0 {
+		tagIds, err := json.Marshal(opts.TagIds)
+		if err != nil {
+			return models.CreatedEnvironment{}, fmt.Errorf("failed to encode tag ids: %w", err)
+		}
+		form.Set("TagIds", string(tagIds))
+	}
+
+	defer c.cache.invalidate(CacheKeyEnvironments, CacheKeyTags)
+
+	var endpoint apimodels.PortainereeEndpoint
+	err := c.doJSON(apiRequest{
+		method: http.MethodPost,
+		path:   "/endpoints",
+		form:   form,
+	}, &endpoint)
+	if err != nil {
+		return models.CreatedEnvironment{}, fmt.Errorf("failed to create environment: %w", err)
+	}
+
+	environment := models.ConvertEndpointToEnvironment(&endpoint)
+	return models.CreatedEnvironment{
+		ID:      environment.ID,
+		Name:    environment.Name,
+		Type:    environment.Type,
+		EdgeKey: endpoint.EdgeKey,
+	}, nil
+}
+
+// environmentUpdatePayload is the payload of an environment update, fields left nil are not changed
+type environmentUpdatePayload struct {
+	Name      *string `json:"name,omitempty"`
+	URL       *string `json:"url,omitempty"`
+	PublicURL *string `json:"publicURL,omitempty"`
+}
+
+// UpdateEnvironment updates the name, URL and public URL of an environment.
+//
+// Parameters:
+//   - id: The ID of the environment to update
+//   - name: The new name of the environment, or nil to keep the current one
+//   - environmentURL: The new URL of the environment, or nil to keep the current one
+//   - publicURL: The new public URL of the environment, or nil to keep the current one
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) UpdateEnvironment(id int, name *string, environmentURL *string, publicURL *string) error {
+	defer c.cache.invalidate(CacheKeyEnvironments, CacheKeyTags)
+
+	err := c.doJSON(apiRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/endpoints/%d", id),
+		body: environmentUpdatePayload{
+			Name:      name,
+			URL:       environmentURL,
+			PublicURL: publicURL,
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update environment: %w", err)
+	}
+	return nil
+}
+
+// DeleteEnvironment deletes an environment from the Portainer server.
+// The agent deployed for the environment, if any, is not removed.
+//
+// Parameters:
+//   - id: The ID of the environment to delete
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) DeleteEnvironment(id int) error {
+	defer c.cache.invalidate(CacheKeyEnvironments, CacheKeyTags)
+
+	err := c.doJSON(apiRequest{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/endpoints/%d", id),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+	return nil
+}
+
+// ListEnvironments retrieves a filtered, sorted and paginated list of environments.
+// Filters and pagination are applied by the Portainer server through the query parameters of /api/endpoints.
+// The list cache is not used, as every call may return a different subset of the environments.
+//
+// Parameters:
+//   - opts: The filters, sorting and pagination of the list
+//
+// Returns:
+//   - The requested page of environments, with the total number of environments matching the filters
+//   - An error if the operation fails
+func (c *PortainerClient) ListEnvironments(opts models.EnvironmentListOptions) (models.EnvironmentPage, error) {
+	query, err := environmentListQuery(opts)
+	if err != nil {
+		return models.EnvironmentPage{}, err
+	}
+
+	// Portainer only sorts by ID in ascending order, a descending order is applied on the full filtered list
+	reverse := opts.Sort != models.EnvironmentSortName && opts.Order == models.SortOrderDesc
+	if !reverse {
+		if opts.Start > 0 {
+			query.Set("start", strconv.Itoa(opts.Start))
+		}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	var endpoints []*apimodels.PortainereeEndpoint
+	header, err := c.doJSONWithHeader(apiRequest{
+		method: http.MethodGet,
+		path:   "/endpoints",
+		query:  query,
+	}, &endpoints)
+	if err != nil {
+		return models.EnvironmentPage{}, fmt.Errorf("failed to list endpoints: %w", err)
 	}
 
 	environments := make([]models.Environment, len(endpoints))
@@ -23,7 +243,93 @@ func (c *PortainerClient) GetEnvironments() ([]models.Environment, error) {
 		environments[i] = models.ConvertEndpointToEnvironment(endpoint)
 	}
 
-	return environments, nil
+	total := len(environments)
+	if reverse {
+		slices.Reverse(environments)
+		environments = paginate(environments, opts.Start, opts.Limit)
+	} else if count, err := strconv.Atoi(header.Get("X-Total-Count")); err == nil {
+		total = count
+	}
+
+	page := models.EnvironmentPage{
+		Environments: environments,
+		Total:        total,
+		Start:        opts.Start,
+		Limit:        opts.Limit,
+	}
+	if next := opts.Start + len(environments); len(environments) > 0 && next < total {
+		page.NextStart = next
+	}
+
+	return page, nil
+}
+
+// environmentListQuery converts the filters and sort of an environment list to /api/endpoints query parameters.
+// Portainer expects array parameters with a [] suffix.
+func environmentListQuery(opts models.EnvironmentListOptions) (url.Values, error) {
+	if opts.Start < 0 || opts.Limit < 0 {
+		return nil, fmt.Errorf("start and limit must not be negative")
+	}
+
+	query := url.Values{}
+	// Snapshots are not part of the environment model and make up most of the response size
+	query.Set("excludeSnapshots", "true")
+
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	for _, id := range opts.TagIds {
+		query.Add("tagIds[]", strconv.Itoa(id))
+	}
+	for _, id := range opts.AccessGroupIds {
+		query.Add("groupIds[]", strconv.Itoa(id))
+	}
+	for _, id := range opts.EnvironmentGroupIds {
+		query.Add("edgeGroupIds[]", strconv.Itoa(id))
+	}
+	for _, environmentType := range opts.Types {
+		id, ok := models.ConvertEnvironmentTypeToID(environmentType)
+		if !ok {
+			return nil, fmt.Errorf("invalid environment type: %s", environmentType)
+		}
+		query.Add("types[]", strconv.Itoa(id))
+	}
+	if opts.Status != "" {
+		id, ok := models.ConvertEnvironmentStatusToID(opts.Status)
+		if !ok {
+			return nil, fmt.Errorf("invalid environment status: %s", opts.Status)
+		}
+		query.Add("status[]", strconv.Itoa(id))
+	}
+
+	switch opts.Sort {
+	case "", models.EnvironmentSortID:
+	case models.EnvironmentSortName:
+		query.Set("sort", "Name")
+		if opts.Order != "" {
+			query.Set("order", opts.Order)
+		}
+	default:
+		return nil, fmt.Errorf("invalid sort field: %s", opts.Sort)
+	}
+
+	if opts.Order != "" && opts.Order != models.SortOrderAsc && opts.Order != models.SortOrderDesc {
+		return nil, fmt.Errorf("invalid sort order: %s", opts.Order)
+	}
+
+	return query, nil
+}
+
+// paginate returns the items of list starting at start, up to limit items if limit is positive
+func paginate[T any](list []T, start, limit int) []T {
+	if start >= len(list) {
+		return []T{}
+	}
+	list = list[start:]
+	if limit > 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return list
 }
 
 // UpdateEnvironmentTags updates the tags associated with an environment.
@@ -35,6 +341,8 @@ func (c *PortainerClient) GetEnvironments() ([]models.Environment, error) {
 // Returns:
 //   - An error if the operation fails
 func (c *PortainerClient) UpdateEnvironmentTags(id int, tagIds []int) error {
+	defer c.cache.invalidate(CacheKeyEnvironments, CacheKeyTags)
+
 	tags := utils.IntToInt64Slice(tagIds)
 	err := c.cli.UpdateEndpoint(int64(id),
 		&tags,
@@ -63,6 +371,8 @@ func (c *PortainerClient) UpdateEnvironmentTags(id int, tagIds []int) error {
 // Returns:
 //   - An error if the operation fails
 func (c *PortainerClient) UpdateEnvironmentUserAccesses(id int, userAccesses map[int]string) error {
+	defer c.cache.invalidate(CacheKeyEnvironments)
+
 	uac := utils.IntToInt64Map(userAccesses)
 	err := c.cli.UpdateEndpoint(int64(id),
 		nil,
@@ -91,6 +401,8 @@ func (c *PortainerClient) UpdateEnvironmentUserAccesses(id int, userAccesses map
 // Returns:
 //   - An error if the operation fails
 func (c *PortainerClient) UpdateEnvironmentTeamAccesses(id int, teamAccesses map[int]string) error {
+	defer c.cache.invalidate(CacheKeyEnvironments)
+
 	tac := utils.IntToInt64Map(teamAccesses)
 	err := c.cli.UpdateEndpoint(int64(id),
 		nil,
@@ -1,13 +1,20 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetEnvironments(t *testing.T) {
@@ -132,6 +139,68 @@ func TestGetEnvironments(t *testing.T) {
 	}
 }
 
+func TestGetEnvironment(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockEndpoint  *apimodels.PortainereeEndpoint
+		mockError     error
+		expected      models.EnvironmentDetails
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockEndpoint: &apimodels.PortainereeEndpoint{
+				ID:      1,
+				Name:    "env1",
+				Type:    2,
+				Status:  1,
+				URL:     "tcp://10.0.0.1:9001",
+				GroupID: 1,
+				Agent:   &apimodels.PortainereeEnvironmentAgentData{Version: "2.31.2"},
+			},
+			expected: models.EnvironmentDetails{
+				Environment: models.Environment{
+					ID:           1,
+					Name:         "env1",
+					Status:       models.EnvironmentStatusActive,
+					Type:         models.EnvironmentTypeDockerAgent,
+					TagIds:       []int{},
+					UserAccesses: map[int]string{},
+					TeamAccesses: map[int]string{},
+				},
+				URL:          "tcp://10.0.0.1:9001",
+				GroupID:      1,
+				Platform:     models.EnvironmentPlatformDockerStandalone,
+				AgentVersion: "2.31.2",
+			},
+		},
+		{
+			name:          "get endpoint error",
+			mockError:     errors.New("failed to get endpoint"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("GetEndpoint", int64(1)).Return(tt.mockEndpoint, tt.mockError)
+
+			client := &PortainerClient{cli: mockAPI}
+
+			environment, err := client.GetEnvironment(1)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, environment)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUpdateEnvironmentTags(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -285,3 +354,229 @@ func TestUpdateEnvironmentTeamAccesses(t *testing.T) {
 		})
 	}
 }
+
+func TestListEnvironments(t *testing.T) {
+	endpoints := []*apimodels.PortainereeEndpoint{
+		{ID: 1, Name: "edge-1", Type: 4, Heartbeat: true},
+		{ID: 2, Name: "edge-2", Type: 4},
+		{ID: 3, Name: "edge-3", Type: 4, Heartbeat: true},
+	}
+
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/endpoints", r.URL.Path)
+		lastQuery = r.URL.Query()
+
+		result := endpoints
+		if limit := lastQuery.Get("limit"); limit != "" {
+			start, _ := strconv.Atoi(lastQuery.Get("start"))
+			n, _ := strconv.Atoi(limit)
+			result = paginate(endpoints, start, n)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(endpoints)))
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{serverURL: server.URL, token: "test-token"}
+
+	t.Run("filters are pushed down", func(t *testing.T) {
+		page, err := client.ListEnvironments(models.EnvironmentListOptions{
+			Search:              "edge",
+			TagIds:              []int{1, 2},
+			AccessGroupIds:      []int{3},
+			EnvironmentGroupIds: []int{4},
+			Types:               []string{models.EnvironmentTypeDockerEdgeAgent, models.EnvironmentTypeKubernetesEdgeAgent},
+			Status:              models.EnvironmentStatusActive,
+			Sort:                models.EnvironmentSortName,
+			Order:               models.SortOrderDesc,
+			Start:               1,
+			Limit:               1,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "edge", lastQuery.Get("search"))
+		assert.Equal(t, []string{"1", "2"}, lastQuery["tagIds[]"])
+		assert.Equal(t, []string{"3"}, lastQuery["groupIds[]"])
+		assert.Equal(t, []string{"4"}, lastQuery["edgeGroupIds[]"])
+		assert.Equal(t, []string{"4", "7"}, lastQuery["types[]"])
+		assert.Equal(t, []string{"1"}, lastQuery["status[]"])
+		assert.Equal(t, "Name", lastQuery.Get("sort"))
+		assert.Equal(t, "desc", lastQuery.Get("order"))
+		assert.Equal(t, "1", lastQuery.Get("start"))
+		assert.Equal(t, "1", lastQuery.Get("limit"))
+		assert.Equal(t, "true", lastQuery.Get("excludeSnapshots"))
+
+		require.Len(t, page.Environments, 1)
+		assert.Equal(t, 2, page.Environments[0].ID)
+		assert.Equal(t, 3, page.Total)
+		assert.Equal(t, 2, page.NextStart)
+	})
+
+	t.Run("descending ID order is applied locally", func(t *testing.T) {
+		page, err := client.ListEnvironments(models.EnvironmentListOptions{
+			Sort:  models.EnvironmentSortID,
+			Order: models.SortOrderDesc,
+			Start: 0,
+			Limit: 2,
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, lastQuery.Get("limit"), "pagination must not be pushed down when reversing the order")
+		assert.Empty(t, lastQuery.Get("sort"))
+		require.Len(t, page.Environments, 2)
+		assert.Equal(t, 3, page.Environments[0].ID)
+		assert.Equal(t, 2, page.Environments[1].ID)
+		assert.Equal(t, 3, page.Total)
+		assert.Equal(t, 2, page.NextStart)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		page, err := client.ListEnvironments(models.EnvironmentListOptions{Start: 2, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Environments, 1)
+		assert.Zero(t, page.NextStart)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, opts := range []models.EnvironmentListOptions{
+			{Types: []string{"unknown"}},
+			{Status: models.EnvironmentStatusUnknown},
+			{Sort: "status"},
+			{Order: "random"},
+			{Start: -1},
+		} {
+			_, err := client.ListEnvironments(opts)
+			assert.Error(t, err, "options %+v must be rejected", opts)
+		}
+	})
+}
+
+func TestCreateEnvironment(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/endpoints", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+
+		creationType, _ := strconv.Atoi(r.FormValue("EndpointCreationType"))
+		endpoint := apimodels.PortainereeEndpoint{ID: 5, Name: r.FormValue("Name"), Type: int64(creationType)}
+		if creationType == 4 {
+			endpoint.EdgeKey = "edge-key"
+		}
+		_ = json.NewEncoder(w).Encode(endpoint)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		opts         models.EnvironmentCreateOptions
+		expected     models.CreatedEnvironment
+		expectedForm map[string]string
+		expectError  bool
+	}{
+		{
+			name: "edge agent with defaults",
+			opts: models.EnvironmentCreateOptions{
+				Name:    "edge",
+				Kind:    models.EnvironmentKindEdgeAgent,
+				GroupID: 2,
+				TagIds:  []int{1, 3},
+			},
+			expected: models.CreatedEnvironment{ID: 5, Name: "edge", Type: models.EnvironmentTypeDockerEdgeAgent, EdgeKey: "edge-key"},
+			expectedForm: map[string]string{
+				"EndpointCreationType":    "4",
+				"URL":                     server.URL,
+				"EdgeTunnelServerAddress": "127.0.0.1:8000",
+				"GroupID":                 "2",
+				"TagIds":                  "[1,3]",
+			},
+		},
+		{
+			name: "agent",
+			opts: models.EnvironmentCreateOptions{
+				Name:      "agent",
+				Kind:      models.EnvironmentKindAgent,
+				URL:       "10.0.0.1:9001",
+				PublicURL: "10.0.0.1",
+			},
+			expected: models.CreatedEnvironment{ID: 5, Name: "agent", Type: models.EnvironmentTypeDockerAgent},
+			expectedForm: map[string]string{
+				"EndpointCreationType": "2",
+				"URL":                  "10.0.0.1:9001",
+				"PublicURL":            "10.0.0.1",
+				"TLS":                  "true",
+			},
+		},
+		{
+			name:        "agent without url",
+			opts:        models.EnvironmentCreateOptions{Name: "agent", Kind: models.EnvironmentKindAgent},
+			expectError: true,
+		},
+		{
+			name:        "invalid kind",
+			opts:        models.EnvironmentCreateOptions{Name: "local", Kind: "docker-local"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form = nil
+			client := &PortainerClient{serverURL: server.URL, token: "test-token"}
+
+			environment, err := client.CreateEnvironment(tt.opts)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, form, "invalid options must not be sent")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, environment)
+			for key, value := range tt.expectedForm {
+				assert.Equal(t, []string{value}, form[key], "form field %s", key)
+			}
+		})
+	}
+}
+
+func TestUpdateAndDeleteEnvironment(t *testing.T) {
+	var requests []string
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPut {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListEndpoints").Return([]*apimodels.PortainereeEndpoint{{ID: 1, Name: "env"}}, nil)
+
+	cache := newListCache(time.Minute, noopMetricsRecorder{})
+	client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "test-token", cache: cache}
+
+	_, err := client.GetEnvironments()
+	require.NoError(t, err)
+
+	name := "renamed"
+	err = client.UpdateEnvironment(1, &name, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "renamed"}, payload, "fields left nil must not be sent")
+
+	_, err = client.GetEnvironments()
+	require.NoError(t, err)
+
+	err = client.DeleteEnvironment(1)
+	require.NoError(t, err)
+
+	_, err = client.GetEnvironments()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PUT /api/endpoints/1", "DELETE /api/endpoints/1"}, requests)
+	mockAPI.AssertNumberOfCalls(t, "ListEndpoints", 3)
+}
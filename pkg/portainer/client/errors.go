@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+)
+
+// ErrorKind classifies the errors returned by the Portainer API
+type ErrorKind string
+
+// Kinds of errors returned by the client
+const (
+	// ErrorKindNotFound means the requested resource does not exist
+	ErrorKindNotFound ErrorKind = "not_found"
+	// ErrorKindForbidden means the token is valid but not allowed to perform the operation
+	ErrorKindForbidden ErrorKind = "forbidden"
+	// ErrorKindConflict means the operation conflicts with the current state of the resource
+	ErrorKindConflict ErrorKind = "conflict"
+	// ErrorKindUnauthorized means the token is missing, invalid or expired
+	ErrorKindUnauthorized ErrorKind = "unauthorized"
+	// ErrorKindUnavailable means the Portainer server could not be reached or is temporarily unavailable
+	ErrorKindUnavailable ErrorKind = "unavailable"
+	// ErrorKindTimeout means the request did not complete in time
+	ErrorKindTimeout ErrorKind = "timeout"
+	// ErrorKindInvalid means the request was rejected as invalid by the Portainer server
+	ErrorKindInvalid ErrorKind = "invalid"
+	// ErrorKindUnknown is used for errors that do not fit in any other kind
+	ErrorKindUnknown ErrorKind = "unknown"
+)
+
+// Sentinel errors matching the APIError of the corresponding kind with errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrUnavailable  = errors.New("unavailable")
+	ErrTimeout      = errors.New("timeout")
+)
+
+var kindSentinels = map[ErrorKind]error{
+	ErrorKindNotFound:     ErrNotFound,
+	ErrorKindForbidden:    ErrForbidden,
+	ErrorKindConflict:     ErrConflict,
+	ErrorKindUnauthorized: ErrUnauthorized,
+	ErrorKindUnavailable:  ErrUnavailable,
+	ErrorKindTimeout:      ErrTimeout,
+}
+
+// APIError is a classified error returned by a failed Portainer API call.
+type APIError struct {
+	// Kind classifies the error
+	Kind ErrorKind
+	// StatusCode is the HTTP status code returned by the Portainer server, or 0 if no response was received
+	StatusCode int
+	// Message is a short description of the error, without the raw response body
+	Message string
+	// Err is the underlying error, if any
+	Err error
+
+	// body is the raw response body, kept for internal inspection only
+	body string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s (status %d): %s", e.Kind, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches the sentinel error of its kind, e.g. ErrNotFound
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := kindSentinels[e.Kind]
+	return ok && sentinel == target
+}
+
+// Transient returns true if the request may succeed when retried
+func (e *APIError) Transient() bool {
+	var limitErr *ConcurrencyLimitError
+	if errors.As(e.Err, &limitErr) {
+		// The request already waited for a free slot, retrying would only add to the contention
+		return false
+	}
+	return e.Kind == ErrorKindUnavailable || e.Kind == ErrorKindTimeout
+}
+
+// ClassifyError converts an error returned by the Portainer API into an APIError.
+// If the error already wraps an APIError, that error is returned.
+// It returns nil if err is nil.
+func ClassifyError(err error) *APIError {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var limitErr *ConcurrencyLimitError
+	if errors.As(err, &limitErr) {
+		return &APIError{Kind: ErrorKindUnavailable, Message: limitErr.Error(), Err: err}
+	}
+
+	if statusCode := statusCodeOf(err); statusCode != 0 {
+		return &APIError{Kind: kindOfStatus(statusCode), StatusCode: statusCode, Message: err.Error(), Err: err}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &APIError{Kind: ErrorKindTimeout, Message: err.Error(), Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return &APIError{Kind: ErrorKindTimeout, Message: err.Error(), Err: err}
+		}
+		return &APIError{Kind: ErrorKindUnavailable, Message: err.Error(), Err: err}
+	}
+
+	return &APIError{Kind: ErrorKindUnknown, Message: err.Error(), Err: err}
+}
+
+// newStatusError creates an APIError from a failed HTTP response.
+// The message is extracted from the Portainer JSON error body when possible,
+// so that raw response bodies are not passed up to the caller.
+func newStatusError(statusCode int, body []byte) *APIError {
+	return &APIError{
+		Kind:       kindOfStatus(statusCode),
+		StatusCode: statusCode,
+		Message:    errorMessageFromBody(statusCode, body),
+		body:       string(body),
+	}
+}
+
+// maxErrorBodySize is the maximum size of the body of a failed proxied response read to build its error
+const maxErrorBodySize = 64 << 10
+
+// ProxyResponseError returns an APIError if a proxied Docker or Kubernetes API response has a failure status code,
+// or nil otherwise. The body of a failed response is read and closed.
+func ProxyResponseError(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		resp.Body.Close()
+	}
+
+	return newStatusError(resp.StatusCode, body)
+}
+
+// errorMessageFromBody extracts the message of a Portainer error response,
+// falling back to the HTTP status text
+func errorMessageFromBody(statusCode int, body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+		Details string `json:"details"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil && response.Message != "" {
+		if response.Details != "" && response.Details != response.Message {
+			return response.Message + ": " + response.Details
+		}
+		return response.Message
+	}
+
+	if text := http.StatusText(statusCode); text != "" {
+		return strings.ToLower(text)
+	}
+	return fmt.Sprintf("api returned status %d", statusCode)
+}
+
+// statusCodeOf returns the HTTP status code carried by an SDK error, or 0
+func statusCodeOf(err error) int {
+	var runtimeErr *runtime.APIError
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr.Code
+	}
+
+	// Errors generated from the swagger definition expose the status code with a Code method
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+
+	return 0
+}
+
+// kindOfStatus maps an HTTP status code to an error kind
+func kindOfStatus(statusCode int) ErrorKind {
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrorKindNotFound
+	case http.StatusForbidden:
+		return ErrorKindForbidden
+	case http.StatusConflict:
+		return ErrorKindConflict
+	case http.StatusUnauthorized:
+		return ErrorKindUnauthorized
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrorKindTimeout
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrorKindUnavailable
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrorKindInvalid
+	default:
+		return ErrorKindUnknown
+	}
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedKind   ErrorKind
+		expectedStatus int
+		transient      bool
+	}{
+		{
+			name:           "swagger typed error",
+			err:            fmt.Errorf("failed to get endpoint: %w", endpoints.NewEndpointInspectNotFound()),
+			expectedKind:   ErrorKindNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "runtime api error",
+			err:            fmt.Errorf("failed to list tags: %w", runtime.NewAPIError("unknown error", nil, http.StatusServiceUnavailable)),
+			expectedKind:   ErrorKindUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			transient:      true,
+		},
+		{
+			name:           "forbidden",
+			err:            runtime.NewAPIError("forbidden", nil, http.StatusForbidden),
+			expectedKind:   ErrorKindForbidden,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthorized",
+			err:            runtime.NewAPIError("unauthorized", nil, http.StatusUnauthorized),
+			expectedKind:   ErrorKindUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "conflict",
+			err:            runtime.NewAPIError("conflict", nil, http.StatusConflict),
+			expectedKind:   ErrorKindConflict,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "gateway timeout",
+			err:            runtime.NewAPIError("timeout", nil, http.StatusGatewayTimeout),
+			expectedKind:   ErrorKindTimeout,
+			expectedStatus: http.StatusGatewayTimeout,
+			transient:      true,
+		},
+		{
+			name:         "context deadline",
+			err:          fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expectedKind: ErrorKindTimeout,
+			transient:    true,
+		},
+		{
+			name:         "network timeout",
+			err:          &net.OpError{Op: "dial", Err: timeoutError{}},
+			expectedKind: ErrorKindTimeout,
+			transient:    true,
+		},
+		{
+			name:         "connection refused",
+			err:          &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			expectedKind: ErrorKindUnavailable,
+			transient:    true,
+		},
+		{
+			name:         "concurrency limit",
+			err:          &ConcurrencyLimitError{Limit: 2, RetryAfter: time.Second},
+			expectedKind: ErrorKindUnavailable,
+		},
+		{
+			name:         "unknown error",
+			err:          errors.New("something went wrong"),
+			expectedKind: ErrorKindUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ClassifyError(tt.err)
+
+			require.NotNil(t, apiErr)
+			assert.Equal(t, tt.expectedKind, apiErr.Kind)
+			assert.Equal(t, tt.expectedStatus, apiErr.StatusCode)
+			assert.Equal(t, tt.transient, apiErr.Transient())
+			assert.ErrorIs(t, apiErr, tt.err, "the original error must be kept in the chain")
+		})
+	}
+}
+
+func TestClassifyErrorKeepsExistingAPIError(t *testing.T) {
+	original := newStatusError(http.StatusNotFound, nil)
+
+	assert.Same(t, original, ClassifyError(fmt.Errorf("wrapped: %w", original)))
+	assert.Nil(t, ClassifyError(nil))
+}
+
+func TestAPIErrorSentinels(t *testing.T) {
+	err := fmt.Errorf("failed to get stack: %w", newStatusError(http.StatusNotFound, nil))
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, newStatusError(http.StatusForbidden, nil), ErrForbidden)
+	assert.ErrorIs(t, newStatusError(http.StatusServiceUnavailable, nil), ErrUnavailable)
+}
+
+func TestNewStatusError(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusCode      int
+		body            string
+		expectedKind    ErrorKind
+		expectedMessage string
+	}{
+		{
+			name:            "portainer json error",
+			statusCode:      http.StatusConflict,
+			body:            `{"message":"A stack with the same name already exists","details":"conflict"}`,
+			expectedKind:    ErrorKindConflict,
+			expectedMessage: "A stack with the same name already exists: conflict",
+		},
+		{
+			name:            "json error without details",
+			statusCode:      http.StatusBadRequest,
+			body:            `{"message":"Invalid request payload"}`,
+			expectedKind:    ErrorKindInvalid,
+			expectedMessage: "Invalid request payload",
+		},
+		{
+			name:            "raw body is not exposed",
+			statusCode:      http.StatusInternalServerError,
+			body:            "<html>stack trace</html>",
+			expectedKind:    ErrorKindUnknown,
+			expectedMessage: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newStatusError(tt.statusCode, []byte(tt.body))
+
+			assert.Equal(t, tt.expectedKind, err.Kind)
+			assert.Equal(t, tt.expectedMessage, err.Message)
+			assert.NotContains(t, err.Error(), "<html>")
+		})
+	}
+}
+
+func TestProxyResponseError(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("[]"))}
+	assert.NoError(t, ProxyResponseError(ok))
+
+	notFound := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(strings.NewReader(`{"message":"No such container: web"}`)),
+	}
+	err := ProxyResponseError(notFound)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "No such container: web", ClassifyError(err).Message)
+
+	err = ProxyResponseError(&http.Response{StatusCode: http.StatusInternalServerError})
+	assert.Equal(t, ErrorKindUnknown, ClassifyError(err).Kind)
+}
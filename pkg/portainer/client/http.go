@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric names reported for the raw HTTP requests sent to the Portainer API
+const (
+	// MetricHTTPRequests is the number of raw HTTP requests sent to the Portainer API, including retries
+	MetricHTTPRequests = "client.http.requests"
+	// MetricHTTPErrors is the number of raw HTTP requests that failed or returned an error status
+	MetricHTTPErrors = "client.http.errors"
+	// MetricHTTPDurationMs is the cumulated duration of the raw HTTP requests, in milliseconds
+	MetricHTTPDurationMs = "client.http.duration_ms"
+)
+
+// defaultReadTimeout is the timeout of the raw GET requests sent to the Portainer API.
+// Writes have no timeout, as deploying a stack can take minutes, for example to pull its images,
+// and reporting a write that Portainer is still performing as failed leads to duplicate retries.
+const defaultReadTimeout = 60 * time.Second
+
+var (
+	sharedHTTPClientsMu sync.Mutex
+	sharedHTTPClients   = map[bool]*http.Client{}
+)
+
+// newHTTPClient creates an HTTP client with a pooled transport, to be reused for all the raw requests of a client.
+// The client has no overall timeout, the timeout of reads is set on each request.
+func newHTTPClient(skipTLSVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: skipTLSVerify}
+	transport.MaxIdleConnsPerHost = 10
+
+	return &http.Client{Transport: transport}
+}
+
+// sharedHTTPClient returns an HTTP client shared by the clients that were not created with NewPortainerClient
+func sharedHTTPClient(skipTLSVerify bool) *http.Client {
+	sharedHTTPClientsMu.Lock()
+	defer sharedHTTPClientsMu.Unlock()
+
+	httpClient, exists := sharedHTTPClients[skipTLSVerify]
+	if !exists {
+		httpClient = newHTTPClient(skipTLSVerify)
+		sharedHTTPClients[skipTLSVerify] = httpClient
+	}
+	return httpClient
+}
+
+// apiRequest describes a raw request to a Portainer API endpoint that is not covered by the SDK
+type apiRequest struct {
+	// method is the HTTP method of the request
+	method string
+	// path is the path of the endpoint, relative to /api (e.g. "/stacks/1")
+	path string
+	// query holds the optional query parameters of the request
+	query url.Values
+	// body is encoded as JSON and sent as the request body if not nil
+	body any
+	// form is encoded as multipart/form-data and sent as the request body if not nil, for endpoints that do not accept JSON
+	form url.Values
+}
+
+// encode returns the payload of the request and its content type
+func (r apiRequest) encode() ([]byte, string, error) {
+	switch {
+	case r.form != nil:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, key := range slices.Sorted(maps.Keys(r.form)) {
+			for _, value := range r.form[key] {
+				if err := writer.WriteField(key, value); err != nil {
+					return nil, "", fmt.Errorf("failed to encode form field %s: %w", key, err)
+				}
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to encode form: %w", err)
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+
+	case r.body != nil:
+		payload, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		return payload, "application/json", nil
+
+	default:
+		return nil, "", nil
+	}
+}
+
+// apiResponse is the successful response of a raw request
+type apiResponse struct {
+	body   []byte
+	header http.Header
+}
+
+// doJSON sends a raw request to the Portainer API and decodes the JSON response into out, unless out is nil.
+func (c *PortainerClient) doJSON(request apiRequest, out any) error {
+	_, err := c.doJSONWithHeader(request, out)
+	return err
+}
+
+// doJSONWithHeader works like doJSON and also returns the headers of the response,
+// for endpoints that return metadata such as the total count of a paginated list in headers.
+func (c *PortainerClient) doJSONWithHeader(request apiRequest, out any) (http.Header, error) {
+	resp, err := c.doRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if out == nil || len(resp.body) == 0 {
+		return resp.header, nil
+	}
+
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return nil, fmt.Errorf("failed to parse response json: %w", err)
+	}
+
+	return resp.header, nil
+}
+
+// doRequest sends a raw request to the Portainer API and returns the successful response.
+// The request is authenticated with the API token and goes through the concurrency limiter.
+// GET requests are retried on transient failures. Failed requests are returned as an *APIError,
+// without the raw response body.
+func (c *PortainerClient) doRequest(request apiRequest) (*apiResponse, error) {
+	if c.serverURL == "" || c.token == "" {
+		return nil, fmt.Errorf("raw api requests require a server url and token")
+	}
+
+	payload, contentType, err := request.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *apiResponse
+	send := func() error {
+		data, err := c.send(request, payload, contentType)
+		resp = data
+		return err
+	}
+
+	if request.method == http.MethodGet {
+		err = c.retrier.do(send)
+	} else {
+		err = classify(send())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// send performs a single attempt of a raw request
+func (c *PortainerClient) send(request apiRequest, payload []byte, contentType string) (*apiResponse, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	ctx := context.Background()
+	if request.method == http.MethodGet {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.getReadTimeout())
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.method, c.apiURL(request.path, request.query), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-API-Key", c.token)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	release, err := c.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	metrics := c.recorder()
+	metrics.Add(MetricHTTPRequests, 1)
+	start := time.Now()
+	defer func() {
+		metrics.Add(MetricHTTPDurationMs, time.Since(start).Milliseconds())
+	}()
+
+	resp, err := c.rawHTTPClient().Do(req)
+	if err != nil {
+		metrics.Add(MetricHTTPErrors, 1)
+		return nil, fmt.Errorf("failed to make http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		metrics.Add(MetricHTTPErrors, 1)
+		return nil, newStatusError(resp.StatusCode, body)
+	}
+	if err != nil {
+		metrics.Add(MetricHTTPErrors, 1)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return &apiResponse{body: body, header: resp.Header}, nil
+}
+
+// apiURL builds the URL of a Portainer API endpoint. The server URL defaults to HTTPS if it has no scheme.
+func (c *PortainerClient) apiURL(path string, query url.Values) string {
+	apiURL := c.baseURL() + "/api" + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+	return apiURL
+}
+
+// baseURL returns the server URL without trailing slash. It defaults to HTTPS if it has no scheme.
+func (c *PortainerClient) baseURL() string {
+	serverURL := c.serverURL
+	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
+		serverURL = "https://" + serverURL
+	}
+	return strings.TrimSuffix(serverURL, "/")
+}
+
+// rawHTTPClient returns the HTTP client used for raw requests
+func (c *PortainerClient) rawHTTPClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return sharedHTTPClient(c.skipTLSVerify)
+}
+
+// getReadTimeout returns the timeout of the raw GET requests
+func (c *PortainerClient) getReadTimeout() time.Duration {
+	if c.readTimeout > 0 {
+		return c.readTimeout
+	}
+	return defaultReadTimeout
+}
+
+// recorder returns the metrics recorder of the client
+func (c *PortainerClient) recorder() MetricsRecorder {
+	if c.metrics != nil {
+		return c.metrics
+	}
+	return noopMetricsRecorder{}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoJSON(t *testing.T) {
+	type item struct {
+		ID   int    `json:"Id"`
+		Name string `json:"Name"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "test-token" {
+			http.Error(w, `{"message":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/items":
+			assert.Equal(t, "2", r.URL.Query().Get("endpointId"))
+			_ = json.NewEncoder(w).Encode([]item{{ID: 1, Name: "first"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/items":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			var payload item
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			payload.ID = 7
+			_ = json.NewEncoder(w).Encode(payload)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/items/7":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, `{"message":"Object not found inside the database"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	recorder := newTestMetricsRecorder()
+	client := &PortainerClient{serverURL: server.URL, token: "test-token", metrics: recorder}
+
+	var items []item
+	err := client.doJSON(apiRequest{method: http.MethodGet, path: "/items", query: url.Values{"endpointId": {"2"}}}, &items)
+	require.NoError(t, err)
+	assert.Equal(t, []item{{ID: 1, Name: "first"}}, items)
+
+	var created item
+	err = client.doJSON(apiRequest{method: http.MethodPost, path: "/items", body: item{Name: "new"}}, &created)
+	require.NoError(t, err)
+	assert.Equal(t, item{ID: 7, Name: "new"}, created)
+
+	err = client.doJSON(apiRequest{method: http.MethodDelete, path: "/items/7"}, nil)
+	require.NoError(t, err)
+
+	err = client.doJSON(apiRequest{method: http.MethodGet, path: "/missing"}, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrorKindNotFound, apiErr.Kind)
+	assert.Equal(t, "Object not found inside the database", apiErr.Message)
+
+	unauthorized := &PortainerClient{serverURL: server.URL, token: "bad-token"}
+	err = unauthorized.doJSON(apiRequest{method: http.MethodGet, path: "/items"}, nil)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	assert.Equal(t, int64(4), recorder.get(MetricHTTPRequests))
+	assert.Equal(t, int64(1), recorder.get(MetricHTTPErrors))
+}
+
+func TestDoJSONReusesConnections(t *testing.T) {
+	var connections atomic.Int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewPortainerClient(server.URL, "test-token")
+
+	for range 5 {
+		require.NoError(t, client.doJSON(apiRequest{method: http.MethodGet, path: "/status"}, nil))
+	}
+
+	assert.Equal(t, int32(1), connections.Load(), "sequential requests must reuse the same connection")
+}
+
+func TestDoJSONRequiresServerURLAndToken(t *testing.T) {
+	client := &PortainerClient{}
+
+	err := client.doJSON(apiRequest{method: http.MethodGet, path: "/stacks"}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURL string
+		path      string
+		query     url.Values
+		expected  string
+	}{
+		{name: "defaults to https", serverURL: "portainer.local:9443", path: "/stacks", expected: "https://portainer.local:9443/api/stacks"},
+		{name: "keeps scheme", serverURL: "http://portainer.local/", path: "/stacks/1", expected: "http://portainer.local/api/stacks/1"},
+		{name: "encodes query", serverURL: "https://portainer.local", path: "/stacks/1", query: url.Values{"endpointId": {"3"}}, expected: "https://portainer.local/api/stacks/1?endpointId=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &PortainerClient{serverURL: tt.serverURL}
+			assert.Equal(t, tt.expected, client.apiURL(tt.path, tt.query))
+		})
+	}
+}
+
+func TestDoJSONTimesOutReadsOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{serverURL: server.URL, token: "test-token", readTimeout: 10 * time.Millisecond}
+
+	err := client.doJSON(apiRequest{method: http.MethodGet, path: "/stacks/1"}, nil)
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	err = client.doJSON(apiRequest{method: http.MethodPut, path: "/stacks/1"}, nil)
+	assert.NoError(t, err, "writes must not time out, as Portainer keeps performing them")
+}
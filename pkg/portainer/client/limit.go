@@ -0,0 +1,391 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/portainer/client-api-go/v2/client"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// Metric names reported by the client through its MetricsRecorder
+const (
+	// MetricRequestsInFlight is the number of Portainer API requests currently in flight
+	MetricRequestsInFlight = "client.requests.in_flight"
+	// MetricRequestsRejected is the number of requests rejected because the concurrency limit was reached
+	MetricRequestsRejected = "client.requests.rejected"
+	// MetricMaxConcurrentRequests is the configured maximum number of concurrent requests
+	MetricMaxConcurrentRequests = "client.requests.max_concurrent"
+)
+
+// defaultConcurrencyWait is how long a request waits for a free slot before being rejected
+const defaultConcurrencyWait = 10 * time.Second
+
+// MetricsRecorder receives the counters and gauges reported by the client.
+type MetricsRecorder interface {
+	Add(name string, delta int64)
+	Set(name string, value int64)
+}
+
+// noopMetricsRecorder discards all metrics
+type noopMetricsRecorder struct{}
+
+func (noopMetricsRecorder) Add(string, int64) {}
+func (noopMetricsRecorder) Set(string, int64) {}
+
+// ConcurrencyLimitError is returned when a request cannot be sent because
+// the maximum number of concurrent requests to Portainer is already in flight.
+type ConcurrencyLimitError struct {
+	// Limit is the configured maximum number of concurrent requests
+	Limit int
+	// RetryAfter is a suggested delay before retrying the request
+	RetryAfter time.Duration
+}
+
+func (e *ConcurrencyLimitError) Error() string {
+	return fmt.Sprintf("too many concurrent requests to Portainer (limit %d), retry after %s", e.Limit, e.RetryAfter)
+}
+
+// concurrencyLimiter caps the number of in-flight requests to the Portainer server.
+// A nil limiter does not limit anything.
+type concurrencyLimiter struct {
+	slots   chan struct{}
+	wait    time.Duration
+	metrics MetricsRecorder
+}
+
+// newConcurrencyLimiter creates a limiter allowing at most limit concurrent requests
+func newConcurrencyLimiter(limit int, wait time.Duration, metrics MetricsRecorder) *concurrencyLimiter {
+	metrics.Set(MetricMaxConcurrentRequests, int64(limit))
+
+	return &concurrencyLimiter{
+		slots:   make(chan struct{}, limit),
+		wait:    wait,
+		metrics: metrics,
+	}
+}
+
+// acquire reserves a request slot, waiting up to the configured duration for one to be released.
+// The returned function must be called to release the slot once the request is complete.
+func (l *concurrencyLimiter) acquire() (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(l.wait)
+		defer timer.Stop()
+
+		select {
+		case l.slots <- struct{}{}:
+		case <-timer.C:
+			l.metrics.Add(MetricRequestsRejected, 1)
+			return nil, &ConcurrencyLimitError{Limit: cap(l.slots), RetryAfter: time.Second}
+		}
+	}
+
+	l.metrics.Add(MetricRequestsInFlight, 1)
+
+	return func() {
+		<-l.slots
+		l.metrics.Add(MetricRequestsInFlight, -1)
+	}, nil
+}
+
+// limitedAPIClient wraps a PortainerAPIClient so that every call goes through the concurrency limiter.
+type limitedAPIClient struct {
+	cli     PortainerAPIClient
+	limiter *concurrencyLimiter
+}
+
+func (l *limitedAPIClient) ListEdgeGroups() ([]*apimodels.EdgegroupsDecoratedEdgeGroup, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListEdgeGroups()
+}
+
+func (l *limitedAPIClient) CreateEdgeGroup(name string, environmentIds []int64) (int64, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return l.cli.CreateEdgeGroup(name, environmentIds)
+}
+
+func (l *limitedAPIClient) UpdateEdgeGroup(id int64, name *string, environmentIds *[]int64, tagIds *[]int64) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateEdgeGroup(id, name, environmentIds, tagIds)
+}
+
+func (l *limitedAPIClient) ListEdgeStacks() ([]*apimodels.PortainereeEdgeStack, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListEdgeStacks()
+}
+
+func (l *limitedAPIClient) CreateEdgeStack(name string, file string, environmentGroupIds []int64) (int64, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return l.cli.CreateEdgeStack(name, file, environmentGroupIds)
+}
+
+func (l *limitedAPIClient) UpdateEdgeStack(id int64, file string, environmentGroupIds []int64) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateEdgeStack(id, file, environmentGroupIds)
+}
+
+func (l *limitedAPIClient) GetEdgeStackFile(id int64) (string, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return l.cli.GetEdgeStackFile(id)
+}
+
+func (l *limitedAPIClient) ListEndpointGroups() ([]*apimodels.PortainerEndpointGroup, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListEndpointGroups()
+}
+
+func (l *limitedAPIClient) CreateEndpointGroup(name string, associatedEndpoints []int64) (int64, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return l.cli.CreateEndpointGroup(name, associatedEndpoints)
+}
+
+func (l *limitedAPIClient) UpdateEndpointGroup(id int64, name *string, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateEndpointGroup(id, name, userAccesses, teamAccesses)
+}
+
+func (l *limitedAPIClient) AddEnvironmentToEndpointGroup(groupId int64, environmentId int64) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.AddEnvironmentToEndpointGroup(groupId, environmentId)
+}
+
+func (l *limitedAPIClient) RemoveEnvironmentFromEndpointGroup(groupId int64, environmentId int64) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.RemoveEnvironmentFromEndpointGroup(groupId, environmentId)
+}
+
+func (l *limitedAPIClient) ListEndpoints() ([]*apimodels.PortainereeEndpoint, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListEndpoints()
+}
+
+func (l *limitedAPIClient) GetEndpoint(id int64) (*apimodels.PortainereeEndpoint, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.GetEndpoint(id)
+}
+
+func (l *limitedAPIClient) UpdateEndpoint(id int64, tagIds *[]int64, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateEndpoint(id, tagIds, userAccesses, teamAccesses)
+}
+
+func (l *limitedAPIClient) GetSettings() (*apimodels.PortainereeSettings, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.GetSettings()
+}
+
+func (l *limitedAPIClient) ListTags() ([]*apimodels.PortainerTag, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListTags()
+}
+
+func (l *limitedAPIClient) CreateTag(name string) (int64, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return l.cli.CreateTag(name)
+}
+
+func (l *limitedAPIClient) ListTeams() ([]*apimodels.PortainerTeam, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListTeams()
+}
+
+func (l *limitedAPIClient) ListTeamMemberships() ([]*apimodels.PortainerTeamMembership, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListTeamMemberships()
+}
+
+func (l *limitedAPIClient) CreateTeam(name string) (int64, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return l.cli.CreateTeam(name)
+}
+
+func (l *limitedAPIClient) UpdateTeamName(id int, name string) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateTeamName(id, name)
+}
+
+func (l *limitedAPIClient) DeleteTeamMembership(id int) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.DeleteTeamMembership(id)
+}
+
+func (l *limitedAPIClient) CreateTeamMembership(teamId int, userId int) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.CreateTeamMembership(teamId, userId)
+}
+
+func (l *limitedAPIClient) ListUsers() ([]*apimodels.PortainereeUser, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return l.cli.ListUsers()
+}
+
+func (l *limitedAPIClient) UpdateUserRole(id int, role int64) error {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return l.cli.UpdateUserRole(id, role)
+}
+
+func (l *limitedAPIClient) GetVersion() (string, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return l.cli.GetVersion()
+}
+
+func (l *limitedAPIClient) ProxyDockerRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.cli.ProxyDockerRequest(environmentId, opts)
+	return releaseOnClose(resp, err, release)
+}
+
+func (l *limitedAPIClient) ProxyKubernetesRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error) {
+	release, err := l.limiter.acquire()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.cli.ProxyKubernetesRequest(environmentId, opts)
+	return releaseOnClose(resp, err, release)
+}
+
+// releaseOnClose ties a concurrency slot to a proxied response: the slot is released when the
+// response body is closed, so that it is held while the body is read, or immediately if there
+// is no body. The caller must close the body of the returned response.
+func releaseOnClose(resp *http.Response, err error, release func()) (*http.Response, error) {
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody is a response body that releases a concurrency slot when it is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/portainer/client-api-go/v2/client"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testMetricsRecorder records metrics in memory for assertions
+type testMetricsRecorder struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func newTestMetricsRecorder() *testMetricsRecorder {
+	return &testMetricsRecorder{values: map[string]int64{}}
+}
+
+func (r *testMetricsRecorder) Add(name string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] += delta
+}
+
+func (r *testMetricsRecorder) Set(name string, value int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = value
+}
+
+func (r *testMetricsRecorder) get(name string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[name]
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	recorder := newTestMetricsRecorder()
+	limiter := newConcurrencyLimiter(2, 20*time.Millisecond, recorder)
+
+	assert.Equal(t, int64(2), recorder.get(MetricMaxConcurrentRequests))
+
+	release1, err := limiter.acquire()
+	require.NoError(t, err)
+	release2, err := limiter.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), recorder.get(MetricRequestsInFlight))
+
+	_, err = limiter.acquire()
+	var limitErr *ConcurrencyLimitError
+	require.True(t, errors.As(err, &limitErr), "expected a ConcurrencyLimitError, got %v", err)
+	assert.Equal(t, 2, limitErr.Limit)
+	assert.Positive(t, limitErr.RetryAfter)
+	assert.Equal(t, int64(1), recorder.get(MetricRequestsRejected))
+
+	release1()
+	assert.Equal(t, int64(1), recorder.get(MetricRequestsInFlight))
+
+	release3, err := limiter.acquire()
+	require.NoError(t, err, "a released slot should be reusable")
+
+	release2()
+	release3()
+	assert.Equal(t, int64(0), recorder.get(MetricRequestsInFlight))
+}
+
+func TestConcurrencyLimiterWaitsForSlot(t *testing.T) {
+	limiter := newConcurrencyLimiter(1, time.Second, noopMetricsRecorder{})
+
+	release, err := limiter.acquire()
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+
+	release, err = limiter.acquire()
+	require.NoError(t, err, "acquire should wait for the slot to be released")
+	release()
+}
+
+func TestNilConcurrencyLimiter(t *testing.T) {
+	var limiter *concurrencyLimiter
+
+	release, err := limiter.acquire()
+
+	require.NoError(t, err)
+	assert.NotPanics(t, release)
+}
+
+func TestLimitedAPIClient(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListTags").Return([]*apimodels.PortainerTag{{ID: 1, Name: "tag"}}, nil)
+
+	limiter := newConcurrencyLimiter(1, 10*time.Millisecond, noopMetricsRecorder{})
+	client := &PortainerClient{cli: &limitedAPIClient{cli: mockAPI, limiter: limiter}, limiter: limiter}
+
+	tags, err := client.GetEnvironmentTags()
+	require.NoError(t, err)
+	assert.Len(t, tags, 1)
+
+	release, err := limiter.acquire()
+	require.NoError(t, err)
+	defer release()
+
+	_, err = client.GetEnvironmentTags()
+	var limitErr *ConcurrencyLimitError
+	assert.True(t, errors.As(err, &limitErr), "calls must be rejected while all slots are in use")
+	mockAPI.AssertNumberOfCalls(t, "ListTags", 1)
+}
+
+func TestLimitedAPIClientProxyHoldsSlotUntilBodyClosed(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ProxyDockerRequest", 1, mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("logs")),
+	}, nil).Once()
+	mockAPI.On("ProxyDockerRequest", 1, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+	recorder := newTestMetricsRecorder()
+	limiter := newConcurrencyLimiter(1, 10*time.Millisecond, recorder)
+	cli := &limitedAPIClient{cli: mockAPI, limiter: limiter}
+
+	resp, err := cli.ProxyDockerRequest(1, client.ProxyRequestOptions{Method: http.MethodGet})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), recorder.get(MetricRequestsInFlight), "the slot must be held while the body is read")
+
+	_, err = limiter.acquire()
+	var limitErr *ConcurrencyLimitError
+	assert.True(t, errors.As(err, &limitErr))
+
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, int64(0), recorder.get(MetricRequestsInFlight), "closing the body must release the slot once")
+
+	_, err = cli.ProxyDockerRequest(1, client.ProxyRequestOptions{Method: http.MethodGet})
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), recorder.get(MetricRequestsInFlight), "failed requests must release the slot")
+}
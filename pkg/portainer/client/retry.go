@@ -0,0 +1,247 @@
+package client
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/portainer/client-api-go/v2/client"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// MetricRequestsRetried is the number of Portainer API requests retried after a transient failure
+const MetricRequestsRetried = "client.requests.retried"
+
+// RetryPolicy configures the retries of idempotent requests failing with a transient error
+// (unavailable server or timeout). The delay between attempts doubles after each attempt,
+// starting at BaseDelay and capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. A value of 1 or less disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay between two attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used by NewPortainerClient
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// delay returns the delay to wait after the given failed attempt (starting at 1), with jitter
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Up to 50% of jitter so that concurrent clients do not retry in lockstep
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retrier runs requests with the retry policy and classifies their errors.
+// A nil retrier runs each request once.
+type retrier struct {
+	policy  RetryPolicy
+	sleep   func(time.Duration)
+	metrics MetricsRecorder
+}
+
+func newRetrier(policy RetryPolicy, metrics MetricsRecorder) *retrier {
+	return &retrier{
+		policy:  policy,
+		sleep:   time.Sleep,
+		metrics: metrics,
+	}
+}
+
+// do runs an idempotent request, retrying it while it fails with a transient error.
+// The returned error, if any, is an *APIError.
+func (r *retrier) do(request func() error) error {
+	if r == nil {
+		return classify(request())
+	}
+
+	attempts := max(r.policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := request()
+		if err == nil {
+			return nil
+		}
+
+		apiErr := ClassifyError(err)
+		if attempt >= attempts || !apiErr.Transient() {
+			return apiErr
+		}
+
+		r.metrics.Add(MetricRequestsRetried, 1)
+		r.sleep(r.policy.delay(attempt))
+	}
+}
+
+// classify converts a non-nil error into an *APIError
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ClassifyError(err)
+}
+
+// retryValue runs an idempotent request returning a value with the retrier
+func retryValue[T any](r *retrier, request func() (T, error)) (T, error) {
+	var value T
+	err := r.do(func() error {
+		var err error
+		value, err = request()
+		return err
+	})
+	return value, err
+}
+
+// retryAPIClient wraps a PortainerAPIClient so that read requests are retried on transient
+// failures, and all errors are classified as *APIError.
+type retryAPIClient struct {
+	cli     PortainerAPIClient
+	retrier *retrier
+}
+
+func (r *retryAPIClient) ListEdgeGroups() ([]*apimodels.EdgegroupsDecoratedEdgeGroup, error) {
+	return retryValue(r.retrier, r.cli.ListEdgeGroups)
+}
+
+func (r *retryAPIClient) CreateEdgeGroup(name string, environmentIds []int64) (int64, error) {
+	id, err := r.cli.CreateEdgeGroup(name, environmentIds)
+	return id, classify(err)
+}
+
+func (r *retryAPIClient) UpdateEdgeGroup(id int64, name *string, environmentIds *[]int64, tagIds *[]int64) error {
+	return classify(r.cli.UpdateEdgeGroup(id, name, environmentIds, tagIds))
+}
+
+func (r *retryAPIClient) ListEdgeStacks() ([]*apimodels.PortainereeEdgeStack, error) {
+	return retryValue(r.retrier, r.cli.ListEdgeStacks)
+}
+
+func (r *retryAPIClient) CreateEdgeStack(name string, file string, environmentGroupIds []int64) (int64, error) {
+	id, err := r.cli.CreateEdgeStack(name, file, environmentGroupIds)
+	return id, classify(err)
+}
+
+func (r *retryAPIClient) UpdateEdgeStack(id int64, file string, environmentGroupIds []int64) error {
+	return classify(r.cli.UpdateEdgeStack(id, file, environmentGroupIds))
+}
+
+func (r *retryAPIClient) GetEdgeStackFile(id int64) (string, error) {
+	return retryValue(r.retrier, func() (string, error) { return r.cli.GetEdgeStackFile(id) })
+}
+
+func (r *retryAPIClient) ListEndpointGroups() ([]*apimodels.PortainerEndpointGroup, error) {
+	return retryValue(r.retrier, r.cli.ListEndpointGroups)
+}
+
+func (r *retryAPIClient) CreateEndpointGroup(name string, associatedEndpoints []int64) (int64, error) {
+	id, err := r.cli.CreateEndpointGroup(name, associatedEndpoints)
+	return id, classify(err)
+}
+
+func (r *retryAPIClient) UpdateEndpointGroup(id int64, name *string, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	return classify(r.cli.UpdateEndpointGroup(id, name, userAccesses, teamAccesses))
+}
+
+func (r *retryAPIClient) AddEnvironmentToEndpointGroup(groupId int64, environmentId int64) error {
+	return classify(r.cli.AddEnvironmentToEndpointGroup(groupId, environmentId))
+}
+
+func (r *retryAPIClient) RemoveEnvironmentFromEndpointGroup(groupId int64, environmentId int64) error {
+	return classify(r.cli.RemoveEnvironmentFromEndpointGroup(groupId, environmentId))
+}
+
+func (r *retryAPIClient) ListEndpoints() ([]*apimodels.PortainereeEndpoint, error) {
+	return retryValue(r.retrier, r.cli.ListEndpoints)
+}
+
+func (r *retryAPIClient) GetEndpoint(id int64) (*apimodels.PortainereeEndpoint, error) {
+	return retryValue(r.retrier, func() (*apimodels.PortainereeEndpoint, error) { return r.cli.GetEndpoint(id) })
+}
+
+func (r *retryAPIClient) UpdateEndpoint(id int64, tagIds *[]int64, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	return classify(r.cli.UpdateEndpoint(id, tagIds, userAccesses, teamAccesses))
+}
+
+func (r *retryAPIClient) GetSettings() (*apimodels.PortainereeSettings, error) {
+	return retryValue(r.retrier, r.cli.GetSettings)
+}
+
+func (r *retryAPIClient) ListTags() ([]*apimodels.PortainerTag, error) {
+	return retryValue(r.retrier, r.cli.ListTags)
+}
+
+func (r *retryAPIClient) CreateTag(name string) (int64, error) {
+	id, err := r.cli.CreateTag(name)
+	return id, classify(err)
+}
+
+func (r *retryAPIClient) ListTeams() ([]*apimodels.PortainerTeam, error) {
+	return retryValue(r.retrier, r.cli.ListTeams)
+}
+
+func (r *retryAPIClient) ListTeamMemberships() ([]*apimodels.PortainerTeamMembership, error) {
+	return retryValue(r.retrier, r.cli.ListTeamMemberships)
+}
+
+func (r *retryAPIClient) CreateTeam(name string) (int64, error) {
+	id, err := r.cli.CreateTeam(name)
+	return id, classify(err)
+}
+
+func (r *retryAPIClient) UpdateTeamName(id int, name string) error {
+	return classify(r.cli.UpdateTeamName(id, name))
+}
+
+func (r *retryAPIClient) DeleteTeamMembership(id int) error {
+	return classify(r.cli.DeleteTeamMembership(id))
+}
+
+func (r *retryAPIClient) CreateTeamMembership(teamId int, userId int) error {
+	return classify(r.cli.CreateTeamMembership(teamId, userId))
+}
+
+func (r *retryAPIClient) ListUsers() ([]*apimodels.PortainereeUser, error) {
+	return retryValue(r.retrier, r.cli.ListUsers)
+}
+
+func (r *retryAPIClient) UpdateUserRole(id int, role int64) error {
+	return classify(r.cli.UpdateUserRole(id, role))
+}
+
+func (r *retryAPIClient) GetVersion() (string, error) {
+	return retryValue(r.retrier, r.cli.GetVersion)
+}
+
+// ProxyDockerRequest retries GET requests that fail before a response is received.
+// Responses are returned as is, whatever their status code.
+func (r *retryAPIClient) ProxyDockerRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error) {
+	request := func() (*http.Response, error) { return r.cli.ProxyDockerRequest(environmentId, opts) }
+	if opts.Method != http.MethodGet {
+		resp, err := request()
+		return resp, classify(err)
+	}
+	return retryValue(r.retrier, request)
+}
+
+// ProxyKubernetesRequest retries GET requests that fail before a response is received.
+// Responses are returned as is, whatever their status code.
+func (r *retryAPIClient) ProxyKubernetesRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error) {
+	request := func() (*http.Response, error) { return r.cli.ProxyKubernetesRequest(environmentId, opts) }
+	if opts.Method != http.MethodGet {
+		resp, err := request()
+		return resp, classify(err)
+	}
+	return retryValue(r.retrier, request)
+}
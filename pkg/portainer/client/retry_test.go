@@ -0,0 +1,160 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetrier(maxAttempts int, recorder MetricsRecorder) (*retrier, *[]time.Duration) {
+	delays := []time.Duration{}
+	r := newRetrier(RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: 100 * time.Millisecond, MaxDelay: 150 * time.Millisecond}, recorder)
+	r.sleep = func(d time.Duration) { delays = append(delays, d) }
+	return r, &delays
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for range 20 {
+		first := policy.delay(1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		second := policy.delay(2)
+		assert.GreaterOrEqual(t, second, 100*time.Millisecond)
+		assert.LessOrEqual(t, second, 200*time.Millisecond)
+
+		capped := policy.delay(10)
+		assert.LessOrEqual(t, capped, 300*time.Millisecond, "the delay must be capped")
+	}
+}
+
+func TestRetrier(t *testing.T) {
+	tests := []struct {
+		name             string
+		errors           []error
+		expectedCalls    int
+		expectedKind     ErrorKind
+		expectedRetries  int64
+		expectedSucceeds bool
+	}{
+		{
+			name:             "succeeds after transient failures",
+			errors:           []error{runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable), runtime.NewAPIError("unavailable", nil, http.StatusBadGateway), nil},
+			expectedCalls:    3,
+			expectedRetries:  2,
+			expectedSucceeds: true,
+		},
+		{
+			name:            "gives up after max attempts",
+			errors:          []error{runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable), runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable), runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable), nil},
+			expectedCalls:   3,
+			expectedKind:    ErrorKindUnavailable,
+			expectedRetries: 2,
+		},
+		{
+			name:          "does not retry non transient errors",
+			errors:        []error{runtime.NewAPIError("not found", nil, http.StatusNotFound), nil},
+			expectedCalls: 1,
+			expectedKind:  ErrorKindNotFound,
+		},
+		{
+			name:          "does not retry concurrency limit errors",
+			errors:        []error{&ConcurrencyLimitError{Limit: 1, RetryAfter: time.Second}, nil},
+			expectedCalls: 1,
+			expectedKind:  ErrorKindUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newTestMetricsRecorder()
+			r, delays := newTestRetrier(3, recorder)
+
+			calls := 0
+			err := r.do(func() error {
+				err := tt.errors[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedRetries, recorder.get(MetricRequestsRetried))
+			assert.Len(t, *delays, int(tt.expectedRetries))
+			if tt.expectedSucceeds {
+				assert.NoError(t, err)
+				return
+			}
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.expectedKind, apiErr.Kind)
+		})
+	}
+}
+
+func TestNilRetrier(t *testing.T) {
+	var r *retrier
+
+	calls := 0
+	err := r.do(func() error {
+		calls++
+		return runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable)
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestRetryAPIClient(t *testing.T) {
+	unavailable := runtime.NewAPIError("unavailable", nil, http.StatusServiceUnavailable)
+
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListTags").Return(nil, unavailable).Once()
+	mockAPI.On("ListTags").Return([]*apimodels.PortainerTag{{ID: 1, Name: "tag"}}, nil).Once()
+	mockAPI.On("CreateTag", "tag").Return(int64(0), unavailable).Once()
+
+	r, _ := newTestRetrier(3, noopMetricsRecorder{})
+	client := &PortainerClient{cli: &retryAPIClient{cli: mockAPI, retrier: r}}
+
+	tags, err := client.GetEnvironmentTags()
+	require.NoError(t, err, "reads must be retried on transient failures")
+	assert.Len(t, tags, 1)
+
+	_, err = client.CreateEnvironmentTag("tag")
+	assert.ErrorIs(t, err, ErrUnavailable, "writes must not be retried, but their errors must be classified")
+
+	mockAPI.AssertExpectations(t)
+	mockAPI.AssertNumberOfCalls(t, "CreateTag", 1)
+}
+
+func TestRawGetRequestRetries(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, `{"message":"Portainer is starting"}`, http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"StackFileContent":"services: {}"}`))
+	}))
+	defer server.Close()
+
+	r, _ := newTestRetrier(3, noopMetricsRecorder{})
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token", retrier: r}
+
+	content, err := client.getRegularStackFileHTTP(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "services: {}", content)
+	assert.Equal(t, int32(3), calls.Load())
+}
@@ -1,134 +1,156 @@
 package client
 
 import (
-	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/portainer/portainer-mcp/pkg/portainer/models"
 	"github.com/portainer/portainer-mcp/pkg/portainer/utils"
 )
 
-// GetStacks retrieves all stacks from the Portainer server.
-// This function queries regular Docker stacks via the Portainer REST API.
-// Falls back to edge stacks if regular stacks API fails.
+// GetStacks retrieves all stacks from the Portainer server: the regular stacks (compose, swarm and kubernetes)
+// followed by the edge stacks. Portainer numbers them separately, so a regular stack and an edge stack
+// can share an ID and must be told apart by their kind.
 //
 // Returns:
 //   - A slice of Stack objects
-//   - An error if the operation fails
+//   - An error if either kind of stacks cannot be listed
 func (c *PortainerClient) GetStacks() ([]models.Stack, error) {
 	regularStacks, err := c.listRegularStacksHTTP()
-	if err == nil && len(regularStacks) > 0 {
-		stacks := make([]models.Stack, len(regularStacks))
-		for i, regularStack := range regularStacks {
-			stacks[i] = models.ConvertRegularStackToStack(&regularStack)
-		}
-		return stacks, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regular stacks: %w", err)
 	}
 
-	edgeStacks, edgeErr := c.cli.ListEdgeStacks()
-	if edgeErr != nil {
-		if err != nil {
-			return nil, fmt.Errorf("failed to list regular stacks: %w (edge stacks also failed: %v)", err, edgeErr)
-		}
-		return nil, fmt.Errorf("failed to list edge stacks: %w", edgeErr)
+	edgeStacks, err := c.cli.ListEdgeStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge stacks: %w", err)
 	}
 
-	stacks := make([]models.Stack, len(edgeStacks))
-	for i, es := range edgeStacks {
-		stacks[i] = models.ConvertEdgeStackToStack(es)
+	stacks := make([]models.Stack, 0, len(regularStacks)+len(edgeStacks))
+	for _, regularStack := range regularStacks {
+		stacks = append(stacks, models.ConvertRegularStackToStack(&regularStack))
+	}
+	for _, es := range edgeStacks {
+		stacks = append(stacks, models.ConvertEdgeStackToStack(es))
 	}
 
 	return stacks, nil
 }
 
 func (c *PortainerClient) listRegularStacksHTTP() ([]models.RegularStack, error) {
-	serverURL := c.serverURL
-	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
-		serverURL = "https://" + serverURL
+	var stacks []models.RegularStack
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: "/stacks"}, &stacks); err != nil {
+		return nil, err
 	}
-	apiURL := fmt.Sprintf("%s/api/stacks", strings.TrimSuffix(serverURL, "/"))
 
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	return stacks, nil
+}
+
+// GetStack retrieves the details of a stack, including its kind, status, target environment and git configuration.
+//
+// Parameters:
+//   - id: The ID of the stack to retrieve
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
+//
+// Returns:
+//   - The stack
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) GetStack(id int, kind string) (models.Stack, error) {
+	stack, err := c.resolveStack(id, kind)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return models.Stack{}, err
 	}
-
-	req.Header.Set("X-API-Key", c.token)
-
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipTLSVerify},
+	if stack.edge != nil {
+		return models.ConvertEdgeStackToStack(stack.edge), nil
 	}
-	client := &http.Client{Transport: transport}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make http request: %w", err)
+	var regularStack models.RegularStack
+	if err := json.Unmarshal(stack.regular, &regularStack); err != nil {
+		return models.Stack{}, fmt.Errorf("failed to decode stack: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("api returned status %d: %s", resp.StatusCode, string(body))
-	}
+	return models.ConvertRegularStackToStack(&regularStack), nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetEdgeStackStatus retrieves the deployment status of an edge stack on each environment of its edge groups,
+// along with the number of environments in each status.
+//
+// Parameters:
+//   - id: The ID of the edge stack
+//
+// Returns:
+//   - The per-environment status of the edge stack
+//   - An error if the stack is not an edge stack or if the operation fails
+func (c *PortainerClient) GetEdgeStackStatus(id int) (models.EdgeStackStatus, error) {
+	edgeStack, err := c.getEdgeStack(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var stacks []models.RegularStack
-	if err := json.Unmarshal(body, &stacks); err != nil {
-		return nil, fmt.Errorf("failed to parse response json: %w", err)
+		return models.EdgeStackStatus{}, err
 	}
 
-	return stacks, nil
+	return models.ConvertEdgeStackStatus(edgeStack), nil
 }
 
 // GetStackFile retrieves the file content of a stack from the Portainer server.
-// This function queries regular Docker stacks via the Portainer REST API.
-// Falls back to edge stacks if regular stacks API fails.
+// The stack is looked up by kind, and never read from a stack of the other kind with the same ID.
 //
 // Parameters:
 //   - id: The ID of the stack to retrieve
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
 //
 // Returns:
-//   - The file content of the stack (Compose file)
+//   - The file content of the stack (Compose file or Kubernetes manifest)
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
 //   - An error if the operation fails
-func (c *PortainerClient) GetStackFile(id int) (string, error) {
+func (c *PortainerClient) GetStackFile(id int, kind string) (string, error) {
+	stack, err := c.resolveStack(id, kind)
+	if err != nil {
+		return "", err
+	}
+	if stack.edge != nil {
+		return c.GetEdgeStackFile(id)
+	}
+
 	file, err := c.getRegularStackFileHTTP(id)
-	if err == nil && file != "" {
-		return file, nil
+	if err != nil {
+		return "", fmt.Errorf("failed to get regular stack file: %w", err)
 	}
 
-	edgeFile, edgeErr := c.cli.GetEdgeStackFile(int64(id))
-	if edgeErr != nil {
-		if err != nil {
-			return "", fmt.Errorf("failed to get regular stack file: %w (edge stack also failed: %v)", err, edgeErr)
-		}
-		return "", fmt.Errorf("failed to get edge stack file: %w", edgeErr)
+	return file, nil
+}
+
+// GetEdgeStackFile retrieves the file content of an edge stack, without falling back to a regular stack
+// with the same ID as Portainer numbers regular and edge stacks separately.
+//
+// Parameters:
+//   - id: The ID of the edge stack to retrieve
+//
+// Returns:
+//   - The file content of the edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) GetEdgeStackFile(id int) (string, error) {
+	file, err := c.cli.GetEdgeStackFile(int64(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to get edge stack file: %w", err)
 	}
 
-	return edgeFile, nil
+	return file, nil
 }
 
+// ErrEdgeStackEnv is returned when the environment variables of an edge stack are requested.
+// Portainer only stores environment variables for regular stacks.
+var ErrEdgeStackEnv = errors.New("stack env is not available for edge stacks")
+
 // GetStackEnvNames retrieves the environment variable names for a regular stack.
 func (c *PortainerClient) GetStackEnvNames(id int) ([]string, error) {
-	if c.serverURL == "" || c.token == "" {
-		return nil, fmt.Errorf("stack env names require server url and token")
-	}
-
-	_, env, err := c.getRegularStackDetailsHTTP(id)
+	env, err := c.GetStackEnv(id)
 	if err != nil {
-		if shouldFallbackToEdge(err) {
-			return nil, fmt.Errorf("stack env names are not available for edge stacks")
-		}
-		return nil, fmt.Errorf("failed to get stack details: %w", err)
+		return nil, err
 	}
 
 	names := make([]string, 0, len(env))
@@ -144,63 +166,43 @@ func (c *PortainerClient) GetStackEnvNames(id int) ([]string, error) {
 	return names, nil
 }
 
-func (c *PortainerClient) getRegularStackFileHTTP(id int) (string, error) {
-	serverURL := c.serverURL
-	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
-		serverURL = "https://" + serverURL
-	}
-	apiURL := fmt.Sprintf("%s/api/stacks/%d/file", strings.TrimSuffix(serverURL, "/"), id)
-
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-Key", c.token)
-
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipTLSVerify},
+// GetStackEnv retrieves the environment variables, including their values, of a regular stack.
+// Values must not be exposed to the model as they often contain secrets.
+//
+// Parameters:
+//   - id: The ID of the stack
+//
+// Returns:
+//   - The environment variables of the stack
+//   - ErrEdgeStackEnv if the stack is an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) GetStackEnv(id int) ([]models.StackEnvVar, error) {
+	if c.serverURL == "" || c.token == "" {
+		return nil, fmt.Errorf("stack env requires server url and token")
 	}
-	client := &http.Client{Transport: transport}
 
-	resp, err := client.Do(req)
+	_, env, err := c.getRegularStackDetailsHTTP(id)
 	if err != nil {
-		return "", fmt.Errorf("failed to make http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("api returned status %d: %s", resp.StatusCode, string(body))
+		if shouldFallbackToEdge(err) {
+			return nil, ErrEdgeStackEnv
+		}
+		return nil, fmt.Errorf("failed to get stack details: %w", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
+	return env, nil
+}
 
+func (c *PortainerClient) getRegularStackFileHTTP(id int) (string, error) {
 	var response struct {
 		StackFileContent string `json:"StackFileContent"`
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response json: %w", err)
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: fmt.Sprintf("/stacks/%d/file", id)}, &response); err != nil {
+		return "", err
 	}
 
 	return response.StackFileContent, nil
 }
 
-type apiStatusError struct {
-	statusCode int
-	body       string
-}
-
-func (e *apiStatusError) Error() string {
-	if e.body == "" {
-		return fmt.Sprintf("api returned status %d", e.statusCode)
-	}
-	return fmt.Sprintf("api returned status %d: %s", e.statusCode, e.body)
-}
-
 type stackEnvEntryAlt struct {
 	Name  string `json:"Name"`
 	Value string `json:"Value"`
@@ -290,112 +292,69 @@ func mergeEnvOverrides(existing []models.StackEnvVar, overrides []models.StackEn
 	return merged
 }
 
-func (c *PortainerClient) getRegularStackDetailsHTTP(id int) (int, []models.StackEnvVar, error) {
-	serverURL := c.serverURL
-	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
-		serverURL = "https://" + serverURL
-	}
-	apiURL := fmt.Sprintf("%s/api/stacks/%d", strings.TrimSuffix(serverURL, "/"), id)
-
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-Key", c.token)
-
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipTLSVerify},
-	}
-	client := &http.Client{Transport: transport}
+// regularStackDetails is the part of a regular stack returned by GET /stacks/{id} used by the client
+type regularStackDetails struct {
+	Type       int                 `json:"Type"`
+	EndpointId int                 `json:"EndpointId"`
+	Env        json.RawMessage     `json:"Env"`
+	GitConfig  *gitRepoConfig      `json:"GitConfig"`
+	AutoUpdate *autoUpdateSettings `json:"AutoUpdate"`
+	// Webhook is the UUID of the redeploy webhook of a stack created from a file, git stacks keep it in AutoUpdate
+	Webhook string `json:"Webhook"`
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to make http request: %w", err)
+func (c *PortainerClient) getRegularStackHTTP(id int) (regularStackDetails, error) {
+	var details regularStackDetails
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: fmt.Sprintf("/stacks/%d", id)}, &details); err != nil {
+		return regularStackDetails{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, nil, &apiStatusError{statusCode: resp.StatusCode, body: string(body)}
-	}
+	return details, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+func (c *PortainerClient) getRegularStackDetailsHTTP(id int) (regularStackDetails, []models.StackEnvVar, error) {
+	details, err := c.getRegularStackHTTP(id)
 	if err != nil {
-		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var response struct {
-		EndpointId int             `json:"EndpointId"`
-		Env        json.RawMessage `json:"Env"`
-	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return 0, nil, fmt.Errorf("failed to parse response json: %w", err)
+		return regularStackDetails{}, nil, err
 	}
 
-	env, err := parseStackEnv(response.Env)
+	env, err := parseStackEnv(details.Env)
 	if err != nil {
-		return 0, nil, err
+		return regularStackDetails{}, nil, err
 	}
 
-	return response.EndpointId, env, nil
+	return details, env, nil
 }
 
-func (c *PortainerClient) updateRegularStackHTTP(id int, endpointId int, file string, env []models.StackEnvVar) error {
-	serverURL := c.serverURL
-	if !strings.HasPrefix(serverURL, "http://") && !strings.HasPrefix(serverURL, "https://") {
-		serverURL = "https://" + serverURL
-	}
-	apiURL := fmt.Sprintf("%s/api/stacks/%d?endpointId=%d", strings.TrimSuffix(serverURL, "/"), id, endpointId)
-
+// updateRegularStackHTTP replaces the file and environment variables of a regular stack and redeploys it.
+// The webhook of the stack is sent back, as Portainer replaces it with the one of the payload.
+func (c *PortainerClient) updateRegularStackHTTP(id int, details regularStackDetails, file string, env []models.StackEnvVar, opts models.StackRedeployOptions) error {
 	payload := struct {
 		StackFileContent string               `json:"StackFileContent"`
 		Prune            bool                 `json:"Prune"`
 		PullImage        bool                 `json:"PullImage"`
 		Env              []models.StackEnvVar `json:"Env"`
+		Webhook          string               `json:"Webhook,omitempty"`
 	}{
 		StackFileContent: file,
-		Prune:            false,
-		PullImage:        false,
+		Prune:            opts.Prune,
+		PullImage:        opts.PullImage,
 		Env:              env,
+		Webhook:          details.Webhook,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal stack update request: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPut, apiURL, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-Key", c.token)
-	req.Header.Set("Content-Type", "application/json")
-
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipTLSVerify},
-	}
-	client := &http.Client{Transport: transport}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to make http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		body, _ := io.ReadAll(resp.Body)
-		return &apiStatusError{statusCode: resp.StatusCode, body: string(body)}
-	}
-
-	return nil
+	return c.doJSON(apiRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/stacks/%d", id),
+		query:  url.Values{"endpointId": {strconv.Itoa(details.EndpointId)}},
+		body:   payload,
+	}, nil)
 }
 
 func shouldFallbackToEdge(err error) bool {
-	var apiErr *apiStatusError
+	var apiErr *APIError
 	if errors.As(err, &apiErr) {
-		if apiErr.statusCode == http.StatusNotFound {
+		if apiErr.StatusCode == http.StatusNotFound {
 			return true
 		}
 		if isEdgeStackErrorMessage(apiErr.body) {
@@ -412,77 +371,187 @@ func isEdgeStackErrorMessage(message string) bool {
 }
 
 // CreateStack creates a new stack on the Portainer server.
-// This function specifically creates a Docker Compose stack.
-// Stacks are the equivalent of Edge Stacks in Portainer.
+// A regular Docker Compose stack (standalone or swarm) is created on the given environment,
+// unless environment group IDs are given, in which case an edge stack is created instead.
 //
 // Parameters:
-//   - name: The name of the stack
-//   - file: The file content of the stack (Compose file)
-//   - environmentGroupIds: A slice of environment group IDs to include in the stack
+//   - opts: The stack to create (name, file, target environment or environment groups, type and env)
 //
 // Returns:
 //   - The ID of the created stack
 //   - An error if the operation fails
-func (c *PortainerClient) CreateStack(name, file string, environmentGroupIds []int) (int, error) {
-	id, err := c.cli.CreateEdgeStack(name, file, utils.IntToInt64Slice(environmentGroupIds))
+func (c *PortainerClient) CreateStack(opts models.StackCreateOptions) (int, error) {
+	if len(opts.EnvironmentGroupIds) > 0 {
+		if opts.EnvironmentID != 0 {
+			return 0, fmt.Errorf("a stack is created either on an environment or on environment groups, not both")
+		}
+		if len(opts.Env) > 0 {
+			return 0, ErrEdgeStackEnv
+		}
+
+		id, err := c.cli.CreateEdgeStack(opts.Name, opts.File, utils.IntToInt64Slice(opts.EnvironmentGroupIds))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create edge stack: %w", err)
+		}
+		return int(id), nil
+	}
+
+	if opts.EnvironmentID == 0 {
+		return 0, fmt.Errorf("an environment ID or environment group IDs are required to create a stack")
+	}
+
+	id, err := c.createRegularStackHTTP(opts)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create edge stack: %w", err)
+		return 0, fmt.Errorf("failed to create regular stack: %w", err)
 	}
 
-	return int(id), nil
+	return id, nil
+}
+
+// regularStackCreatePayload is the body of the /stacks/create/{type}/string requests
+type regularStackCreatePayload struct {
+	Name             string               `json:"name"`
+	StackFileContent string               `json:"stackFileContent"`
+	SwarmID          string               `json:"swarmID,omitempty"`
+	Env              []models.StackEnvVar `json:"env,omitempty"`
+}
+
+func (c *PortainerClient) createRegularStackHTTP(opts models.StackCreateOptions) (int, error) {
+	if c.serverURL == "" || c.token == "" {
+		return 0, fmt.Errorf("regular stack creation requires server url and token")
+	}
+
+	payload := regularStackCreatePayload{
+		Name:             opts.Name,
+		StackFileContent: opts.File,
+		Env:              opts.Env,
+	}
+
+	stackType, swarmID, err := c.resolveStackType(opts.Type, opts.EnvironmentID)
+	if err != nil {
+		return 0, err
+	}
+	payload.SwarmID = swarmID
+
+	var created models.RegularStack
+	err = c.doJSON(apiRequest{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/stacks/create/%s/string", stackType),
+		query:  url.Values{"endpointId": {strconv.Itoa(opts.EnvironmentID)}},
+		body:   payload,
+	}, &created)
+	if err != nil {
+		return 0, err
+	}
+
+	return created.ID, nil
+}
+
+// resolveStackType returns the type of a regular stack to create, standalone by default,
+// along with the swarm ID of the environment for swarm stacks
+func (c *PortainerClient) resolveStackType(stackType string, environmentID int) (string, string, error) {
+	switch stackType {
+	case "", models.StackTypeStandalone:
+		return models.StackTypeStandalone, "", nil
+	case models.StackTypeSwarm:
+		swarmID, err := c.getSwarmIDHTTP(environmentID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get swarm ID of environment %d: %w", environmentID, err)
+		}
+		return models.StackTypeSwarm, swarmID, nil
+	default:
+		return "", "", fmt.Errorf("invalid stack type: %s", stackType)
+	}
+}
+
+// getSwarmIDHTTP returns the ID of the swarm cluster of an environment, through the Docker API proxy
+func (c *PortainerClient) getSwarmIDHTTP(environmentID int) (string, error) {
+	var swarm struct {
+		ID string `json:"ID"`
+	}
+	if err := c.doJSON(apiRequest{method: http.MethodGet, path: fmt.Sprintf("/endpoints/%d/docker/swarm", environmentID)}, &swarm); err != nil {
+		return "", err
+	}
+	if swarm.ID == "" {
+		return "", fmt.Errorf("environment %d is not part of a swarm cluster", environmentID)
+	}
+
+	return swarm.ID, nil
 }
 
 // UpdateStack updates an existing stack on the Portainer server.
-// This function attempts a regular stack update first and falls back to edge stacks when appropriate.
+// The stack is looked up by kind, and a stack of the other kind with the same ID is never updated instead.
 //
 // Parameters:
 //   - id: The ID of the stack to update
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
 //   - file: The file content of the stack (Compose file)
-//   - environmentGroupIds: A slice of environment group IDs to include in the stack
+//   - environmentGroupIds: A slice of environment group IDs to include in an edge stack
+//   - envOverrides: The environment variables to set on a regular stack, the others keep their values
 //
 // Returns:
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
 //   - An error if the operation fails
-func (c *PortainerClient) UpdateStack(id int, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error {
-	if c.serverURL != "" && c.token != "" {
-		endpointId, env, err := c.getRegularStackDetailsHTTP(id)
-		if err == nil {
-			mergedEnv := mergeEnvOverrides(env, envOverrides)
-			err = c.updateRegularStackHTTP(id, endpointId, file, mergedEnv)
-			if err == nil {
-				return nil
-			}
-			if shouldFallbackToEdge(err) {
-				if len(envOverrides) > 0 {
-					return fmt.Errorf("stack env overrides are not supported for edge stacks")
-				}
-				edgeErr := c.cli.UpdateEdgeStack(int64(id), file, utils.IntToInt64Slice(environmentGroupIds))
-				if edgeErr != nil {
-					return fmt.Errorf("failed to update regular stack: %w (edge stack also failed: %v)", err, edgeErr)
-				}
-				return nil
-			}
-			return fmt.Errorf("failed to update regular stack: %w", err)
+func (c *PortainerClient) UpdateStack(id int, kind string, file string, environmentGroupIds []int, envOverrides []models.StackEnvVar) error {
+	stack, err := c.resolveStack(id, kind)
+	if err != nil {
+		return err
+	}
+
+	if stack.edge != nil {
+		if len(envOverrides) > 0 {
+			return fmt.Errorf("stack env overrides are not supported for edge stacks")
 		}
-		if shouldFallbackToEdge(err) {
-			if len(envOverrides) > 0 {
-				return fmt.Errorf("stack env overrides are not supported for edge stacks")
-			}
-			edgeErr := c.cli.UpdateEdgeStack(int64(id), file, utils.IntToInt64Slice(environmentGroupIds))
-			if edgeErr != nil {
-				return fmt.Errorf("failed to get regular stack details: %w (edge stack also failed: %v)", err, edgeErr)
-			}
-			return nil
+		if err := c.cli.UpdateEdgeStack(int64(id), file, utils.IntToInt64Slice(environmentGroupIds)); err != nil {
+			return fmt.Errorf("failed to update edge stack: %w", err)
 		}
-		return fmt.Errorf("failed to get regular stack details: %w", err)
+		return nil
+	}
+
+	details, err := stack.regularDetails()
+	if err != nil {
+		return err
 	}
 
-	if len(envOverrides) > 0 {
-		return fmt.Errorf("stack env overrides require a Portainer server url and token")
+	env, err := parseStackEnv(details.Env)
+	if err != nil {
+		return err
+	}
+
+	err = c.updateRegularStackHTTP(id, details, file, mergeEnvOverrides(env, envOverrides), models.StackRedeployOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update regular stack: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceStack updates a regular stack, replacing both its file and its whole set of environment variables.
+// Unlike UpdateStack, variables that are not part of env are removed from the stack.
+//
+// Parameters:
+//   - id: The ID of the stack to update
+//   - file: The file content of the stack (Compose file)
+//   - env: The complete list of environment variables of the stack
+//
+// Returns:
+//   - ErrEdgeStackEnv if the stack is an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) ReplaceStack(id int, file string, env []models.StackEnvVar) error {
+	if c.serverURL == "" || c.token == "" {
+		return fmt.Errorf("stack replacement requires server url and token")
 	}
 
-	err := c.cli.UpdateEdgeStack(int64(id), file, utils.IntToInt64Slice(environmentGroupIds))
+	details, _, err := c.getRegularStackDetailsHTTP(id)
 	if err != nil {
-		return fmt.Errorf("failed to update edge stack: %w", err)
+		if shouldFallbackToEdge(err) {
+			return ErrEdgeStackEnv
+		}
+		return fmt.Errorf("failed to get regular stack details: %w", err)
+	}
+
+	if err := c.updateRegularStackHTTP(id, details, file, env, models.StackRedeployOptions{}); err != nil {
+		return fmt.Errorf("failed to update regular stack: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// UpdateStackEnv changes the environment variables of a regular stack and redeploys it with its current file.
+//
+// Parameters:
+//   - id: The ID of the stack to update
+//   - change: The variables to rename, remove and set
+//
+// Returns:
+//   - ErrEdgeStackEnv if the stack is an edge stack
+//   - An error if a variable to rename or remove does not exist, if a rename conflicts with an existing variable,
+//     or if the operation fails
+func (c *PortainerClient) UpdateStackEnv(id int, change models.StackEnvChange) error {
+	if c.serverURL == "" || c.token == "" {
+		return fmt.Errorf("stack env update requires server url and token")
+	}
+
+	details, env, err := c.getRegularStackDetailsHTTP(id)
+	if err != nil {
+		if shouldFallbackToEdge(err) {
+			return ErrEdgeStackEnv
+		}
+		return fmt.Errorf("failed to get regular stack details: %w", err)
+	}
+
+	updated, err := applyStackEnvChange(env, change)
+	if err != nil {
+		return err
+	}
+
+	file, err := c.getRegularStackFileHTTP(id)
+	if err != nil {
+		return fmt.Errorf("failed to get regular stack file: %w", err)
+	}
+
+	if err := c.updateRegularStackHTTP(id, details, file, updated, models.StackRedeployOptions{}); err != nil {
+		return fmt.Errorf("failed to update regular stack: %w", err)
+	}
+
+	return nil
+}
+
+// applyStackEnvChange returns the environment variables of a stack after a change, keeping their order
+func applyStackEnvChange(env []models.StackEnvVar, change models.StackEnvChange) ([]models.StackEnvVar, error) {
+	for _, name := range sortedKeys(change.Rename) {
+		if indexOfEnvVar(env, name) < 0 {
+			return nil, &APIError{Kind: ErrorKindInvalid, Message: fmt.Sprintf("stack env variable %s does not exist", name)}
+		}
+	}
+
+	// All the variables are renamed at once, so that two variables can swap their names
+	updated := make([]models.StackEnvVar, 0, len(env))
+	seen := map[string]bool{}
+	for _, entry := range env {
+		name := entry.Name
+		if to, ok := change.Rename[name]; ok {
+			name = to
+		}
+		if seen[name] {
+			return nil, &APIError{Kind: ErrorKindConflict, Message: fmt.Sprintf("cannot rename to %s: a stack env variable with this name already exists", name)}
+		}
+		seen[name] = true
+		updated = append(updated, models.StackEnvVar{Name: name, Value: entry.Value})
+	}
+
+	for _, name := range change.Unset {
+		i := indexOfEnvVar(updated, name)
+		if i < 0 {
+			return nil, &APIError{Kind: ErrorKindInvalid, Message: fmt.Sprintf("stack env variable %s does not exist", name)}
+		}
+		updated = append(updated[:i], updated[i+1:]...)
+	}
+
+	return mergeEnvOverrides(updated, change.Set), nil
+}
+
+func indexOfEnvVar(env []models.StackEnvVar, name string) int {
+	for i, entry := range env {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyStackEnvChange(t *testing.T) {
+	env := []models.StackEnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}, {Name: "C", Value: "3"}}
+
+	tests := []struct {
+		name     string
+		change   models.StackEnvChange
+		want     []models.StackEnvVar
+		wantKind ErrorKind
+	}{
+		{
+			name:   "set",
+			change: models.StackEnvChange{Set: []models.StackEnvVar{{Name: "B", Value: "changed"}, {Name: "D", Value: "4"}}},
+			want:   []models.StackEnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "changed"}, {Name: "C", Value: "3"}, {Name: "D", Value: "4"}},
+		},
+		{
+			name:   "unset",
+			change: models.StackEnvChange{Unset: []string{"A", "C"}},
+			want:   []models.StackEnvVar{{Name: "B", Value: "2"}},
+		},
+		{
+			name:   "rename",
+			change: models.StackEnvChange{Rename: map[string]string{"B": "RENAMED"}},
+			want:   []models.StackEnvVar{{Name: "A", Value: "1"}, {Name: "RENAMED", Value: "2"}, {Name: "C", Value: "3"}},
+		},
+		{
+			name:   "swap names",
+			change: models.StackEnvChange{Rename: map[string]string{"A": "B", "B": "A"}},
+			want:   []models.StackEnvVar{{Name: "B", Value: "1"}, {Name: "A", Value: "2"}, {Name: "C", Value: "3"}},
+		},
+		{
+			name:     "unset unknown variable",
+			change:   models.StackEnvChange{Unset: []string{"MISSING"}},
+			wantKind: ErrorKindInvalid,
+		},
+		{
+			name:     "rename unknown variable",
+			change:   models.StackEnvChange{Rename: map[string]string{"MISSING": "X"}},
+			wantKind: ErrorKindInvalid,
+		},
+		{
+			name:     "rename to existing variable",
+			change:   models.StackEnvChange{Rename: map[string]string{"A": "C"}},
+			wantKind: ErrorKindConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyStackEnvChange(env, tt.change)
+			if tt.wantKind != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantKind, ClassifyError(err).Kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, "1", env[0].Value, "the current env must not be modified")
+	assert.Equal(t, "A", env[0].Name)
+}
+
+func TestUpdateStackEnv(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	client := server.client(new(MockPortainerAPI))
+
+	err := client.UpdateStackEnv(1, models.StackEnvChange{
+		Rename: map[string]string{"TOKEN": "API_TOKEN"},
+		Set:    []models.StackEnvVar{{Name: "MODE", Value: "prod"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PUT /api/stacks/1?endpointId=3"}, server.requests)
+	assert.Equal(t, "services: {}", server.payload["StackFileContent"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "API_TOKEN", "value": "secret"},
+		map[string]any{"name": "MODE", "value": "prod"},
+	}, server.payload["Env"])
+
+	err = client.UpdateStackEnv(1, models.StackEnvChange{Unset: []string{"MISSING"}})
+	assert.ErrorContains(t, err, "stack env variable MISSING does not exist")
+
+	err = client.UpdateStackEnv(9, models.StackEnvChange{Unset: []string{"TOKEN"}})
+	assert.ErrorIs(t, err, ErrEdgeStackEnv)
+}
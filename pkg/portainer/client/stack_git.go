@@ -0,0 +1,293 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// gitRepoConfig is the git configuration of a stack deployed from a repository
+type gitRepoConfig struct {
+	URL            string             `json:"URL"`
+	ReferenceName  string             `json:"ReferenceName"`
+	ConfigFilePath string             `json:"ConfigFilePath"`
+	ConfigHash     string             `json:"ConfigHash"`
+	TLSSkipVerify  bool               `json:"TLSSkipVerify"`
+	Authentication *gitAuthentication `json:"Authentication"`
+}
+
+// gitAuthentication holds the credentials of a git repository.
+// The password is never returned by Portainer.
+type gitAuthentication struct {
+	Username        string `json:"Username"`
+	GitCredentialID int    `json:"GitCredentialID"`
+}
+
+// autoUpdateSettings is the payload of the GitOps auto-update settings of a stack
+type autoUpdateSettings struct {
+	Interval       string `json:"interval,omitempty"`
+	Webhook        string `json:"webhook,omitempty"`
+	ForceUpdate    bool   `json:"forceUpdate"`
+	ForcePullImage bool   `json:"forcePullImage"`
+}
+
+func newAutoUpdateSettings(autoUpdate *models.StackAutoUpdate) *autoUpdateSettings {
+	if autoUpdate == nil || (autoUpdate.Interval == "" && autoUpdate.Webhook == "") {
+		return nil
+	}
+
+	return &autoUpdateSettings{
+		Interval:       autoUpdate.Interval,
+		Webhook:        autoUpdate.Webhook,
+		ForceUpdate:    autoUpdate.ForceUpdate,
+		ForcePullImage: autoUpdate.ForcePullImage,
+	}
+}
+
+// gitStackCreatePayload is the body of the /stacks/create/{type}/repository requests
+type gitStackCreatePayload struct {
+	Name                      string               `json:"name"`
+	SwarmID                   string               `json:"swarmID,omitempty"`
+	RepositoryURL             string               `json:"repositoryURL"`
+	RepositoryReferenceName   string               `json:"repositoryReferenceName,omitempty"`
+	ComposeFile               string               `json:"composeFile,omitempty"`
+	AdditionalFiles           []string             `json:"additionalFiles,omitempty"`
+	RepositoryAuthentication  bool                 `json:"repositoryAuthentication"`
+	RepositoryUsername        string               `json:"repositoryUsername,omitempty"`
+	RepositoryPassword        string               `json:"repositoryPassword,omitempty"`
+	RepositoryGitCredentialID int                  `json:"repositoryGitCredentialID,omitempty"`
+	TLSSkipVerify             bool                 `json:"tlsskipVerify"`
+	Env                       []models.StackEnvVar `json:"env,omitempty"`
+	AutoUpdate                *autoUpdateSettings  `json:"autoUpdate,omitempty"`
+}
+
+// gitStackUpdatePayload is the body of the /stacks/{id}/git requests
+type gitStackUpdatePayload struct {
+	AutoUpdate                *autoUpdateSettings  `json:"autoUpdate"`
+	Env                       []models.StackEnvVar `json:"env"`
+	RepositoryReferenceName   string               `json:"repositoryReferenceName"`
+	RepositoryAuthentication  bool                 `json:"repositoryAuthentication"`
+	RepositoryUsername        string               `json:"repositoryUsername,omitempty"`
+	RepositoryGitCredentialID int                  `json:"repositoryGitCredentialID,omitempty"`
+	TLSSkipVerify             bool                 `json:"tlsskipVerify"`
+}
+
+// gitStackRedeployPayload is the body of the /stacks/{id}/git/redeploy requests
+type gitStackRedeployPayload struct {
+	Env                       []models.StackEnvVar `json:"env"`
+	Prune                     bool                 `json:"prune"`
+	PullImage                 bool                 `json:"pullImage"`
+	RepositoryReferenceName   string               `json:"repositoryReferenceName"`
+	RepositoryAuthentication  bool                 `json:"repositoryAuthentication"`
+	RepositoryUsername        string               `json:"repositoryUsername,omitempty"`
+	RepositoryGitCredentialID int                  `json:"repositoryGitCredentialID,omitempty"`
+}
+
+// CreateStackFromGit creates a regular stack on an environment from a compose file stored in a git repository.
+//
+// Parameters:
+//   - opts: The stack to create (name, environment, type, repository, credentials, env and auto-update settings)
+//
+// Returns:
+//   - The ID of the created stack
+//   - An error if the operation fails
+func (c *PortainerClient) CreateStackFromGit(opts models.StackGitCreateOptions) (int, error) {
+	if opts.EnvironmentID == 0 {
+		return 0, fmt.Errorf("an environment ID is required to create a stack from git")
+	}
+	if opts.RepositoryURL == "" {
+		return 0, fmt.Errorf("a repository URL is required to create a stack from git")
+	}
+
+	payload := gitStackCreatePayload{
+		Name:                      opts.Name,
+		RepositoryURL:             opts.RepositoryURL,
+		RepositoryReferenceName:   opts.ReferenceName,
+		ComposeFile:               opts.ComposeFile,
+		AdditionalFiles:           opts.AdditionalFiles,
+		RepositoryAuthentication:  opts.Username != "" || opts.GitCredentialID != 0,
+		RepositoryUsername:        opts.Username,
+		RepositoryPassword:        opts.Password,
+		RepositoryGitCredentialID: opts.GitCredentialID,
+		TLSSkipVerify:             opts.TLSSkipVerify,
+		Env:                       opts.Env,
+		AutoUpdate:                newAutoUpdateSettings(opts.AutoUpdate),
+	}
+
+	stackType, swarmID, err := c.resolveStackType(opts.Type, opts.EnvironmentID)
+	if err != nil {
+		return 0, err
+	}
+	payload.SwarmID = swarmID
+
+	var created models.RegularStack
+	err = c.doJSON(apiRequest{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/stacks/create/%s/repository", stackType),
+		query:  url.Values{"endpointId": {strconv.Itoa(opts.EnvironmentID)}},
+		body:   payload,
+	}, &created)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create stack from git: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// GetStackGitSettings retrieves the git repository and the auto-update settings of a stack deployed from git.
+//
+// Parameters:
+//   - id: The ID of the stack
+//
+// Returns:
+//   - The git settings of the stack, including the URL of its webhook if enabled
+//   - An error if the stack is not deployed from git or if the operation fails
+func (c *PortainerClient) GetStackGitSettings(id int) (models.StackGitSettings, error) {
+	details, err := c.getGitStackHTTP(id)
+	if err != nil {
+		return models.StackGitSettings{}, err
+	}
+
+	settings := models.StackGitSettings{
+		StackID: id,
+		StackGitConfig: models.StackGitConfig{
+			RepositoryURL: details.GitConfig.URL,
+			ReferenceName: details.GitConfig.ReferenceName,
+			ComposeFile:   details.GitConfig.ConfigFilePath,
+			CommitHash:    details.GitConfig.ConfigHash,
+		},
+		Authenticated: details.GitConfig.Authentication != nil,
+	}
+
+	if autoUpdate := details.AutoUpdate; autoUpdate != nil && (autoUpdate.Interval != "" || autoUpdate.Webhook != "") {
+		settings.AutoUpdate = &models.StackAutoUpdate{
+			Interval:       autoUpdate.Interval,
+			Webhook:        autoUpdate.Webhook,
+			ForceUpdate:    autoUpdate.ForceUpdate,
+			ForcePullImage: autoUpdate.ForcePullImage,
+		}
+		if autoUpdate.Webhook != "" {
+			settings.WebhookURL = c.apiURL("/stacks/webhooks/"+autoUpdate.Webhook, nil)
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateStackAutoUpdate replaces the auto-update settings of a stack deployed from git.
+// The git reference, credentials and environment variables of the stack are kept.
+//
+// Parameters:
+//   - id: The ID of the stack
+//   - autoUpdate: The new auto-update settings, or nil to disable auto-updates
+//
+// Returns:
+//   - An error if the stack is not deployed from git or if the operation fails
+func (c *PortainerClient) UpdateStackAutoUpdate(id int, autoUpdate *models.StackAutoUpdate) error {
+	details, err := c.getGitStackHTTP(id)
+	if err != nil {
+		return err
+	}
+
+	env, err := parseStackEnv(details.Env)
+	if err != nil {
+		return err
+	}
+
+	payload := gitStackUpdatePayload{
+		AutoUpdate:              newAutoUpdateSettings(autoUpdate),
+		Env:                     nonNilEnv(env),
+		RepositoryReferenceName: details.GitConfig.ReferenceName,
+		TLSSkipVerify:           details.GitConfig.TLSSkipVerify,
+	}
+	if auth := details.GitConfig.Authentication; auth != nil {
+		// Portainer keeps the stored password when none is sent
+		payload.RepositoryAuthentication = true
+		payload.RepositoryUsername = auth.Username
+		payload.RepositoryGitCredentialID = auth.GitCredentialID
+	}
+
+	err = c.doJSON(apiRequest{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/stacks/%d/git", id),
+		query:  url.Values{"endpointId": {strconv.Itoa(details.EndpointId)}},
+		body:   payload,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update stack git settings: %w", err)
+	}
+
+	return nil
+}
+
+// RedeployStackFromGit pulls the latest version of the compose file of a stack from its git repository and redeploys it.
+// The credentials and environment variables of the stack are kept.
+//
+// Parameters:
+//   - id: The ID of the stack
+//   - opts: The redeploy options (git reference, pull images, prune services)
+//
+// Returns:
+//   - An error if the stack is not deployed from git or if the operation fails
+func (c *PortainerClient) RedeployStackFromGit(id int, opts models.StackGitRedeployOptions) error {
+	details, err := c.getGitStackHTTP(id)
+	if err != nil {
+		return err
+	}
+
+	env, err := parseStackEnv(details.Env)
+	if err != nil {
+		return err
+	}
+
+	payload := gitStackRedeployPayload{
+		Env:                     nonNilEnv(env),
+		Prune:                   opts.Prune,
+		PullImage:               opts.PullImage,
+		RepositoryReferenceName: details.GitConfig.ReferenceName,
+	}
+	if opts.ReferenceName != "" {
+		payload.RepositoryReferenceName = opts.ReferenceName
+	}
+	if auth := details.GitConfig.Authentication; auth != nil {
+		payload.RepositoryAuthentication = true
+		payload.RepositoryUsername = auth.Username
+		payload.RepositoryGitCredentialID = auth.GitCredentialID
+	}
+
+	err = c.doJSON(apiRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/stacks/%d/git/redeploy", id),
+		query:  url.Values{"endpointId": {strconv.Itoa(details.EndpointId)}},
+		body:   payload,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to redeploy stack from git: %w", err)
+	}
+
+	return nil
+}
+
+// getGitStackHTTP returns the details of a regular stack, or an error if it is not deployed from git
+func (c *PortainerClient) getGitStackHTTP(id int) (regularStackDetails, error) {
+	details, err := c.getRegularStackHTTP(id)
+	if err != nil {
+		return regularStackDetails{}, fmt.Errorf("failed to get stack details: %w", err)
+	}
+	if details.GitConfig == nil || details.GitConfig.URL == "" {
+		return regularStackDetails{}, fmt.Errorf("stack %d is not deployed from a git repository", id)
+	}
+
+	return details, nil
+}
+
+// nonNilEnv returns env, or an empty list if it is nil, as Portainer replaces the stack env with the one sent
+func nonNilEnv(env []models.StackEnvVar) []models.StackEnvVar {
+	if env == nil {
+		return []models.StackEnvVar{}
+	}
+	return env
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitStackServer is a local stand-in for the stack endpoints of the Portainer API.
+// It serves a git stack (ID 1) and a stack created from a file (ID 2), and records the last write.
+type gitStackServer struct {
+	*httptest.Server
+	path    string
+	payload map[string]any
+}
+
+func newGitStackServer(t *testing.T) *gitStackServer {
+	t.Helper()
+
+	s := &gitStackServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.path = r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+			s.payload = map[string]any{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&s.payload))
+			_ = json.NewEncoder(w).Encode(models.RegularStack{ID: 9})
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/stacks/1":
+			_, _ = w.Write([]byte(`{
+				"Id": 1,
+				"EndpointId": 3,
+				"Env": [{"name": "TOKEN", "value": "secret"}],
+				"GitConfig": {
+					"URL": "https://git.example.com/ops/stacks.git",
+					"ReferenceName": "refs/heads/main",
+					"ConfigFilePath": "web/docker-compose.yml",
+					"ConfigHash": "bc4c183d",
+					"Authentication": {"Username": "deploy", "GitCredentialID": 0}
+				},
+				"AutoUpdate": {"Interval": "5m", "Webhook": "05de31a2-79fa-4644-9c12-faa67e5c49f0", "ForcePullImage": true}
+			}`))
+		case "/api/stacks/2":
+			_, _ = w.Write([]byte(`{"Id": 2, "EndpointId": 3, "Env": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *gitStackServer) client() *PortainerClient {
+	return &PortainerClient{cli: new(MockPortainerAPI), serverURL: s.URL, token: "test-token"}
+}
+
+func TestCreateStackFromGit(t *testing.T) {
+	server := newGitStackServer(t)
+
+	id, err := server.client().CreateStackFromGit(models.StackGitCreateOptions{
+		Name:          "web",
+		EnvironmentID: 3,
+		RepositoryURL: "https://git.example.com/ops/stacks.git",
+		ReferenceName: "refs/heads/main",
+		ComposeFile:   "web/docker-compose.yml",
+		Username:      "deploy",
+		Password:      "token",
+		Env:           []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+		AutoUpdate:    &models.StackAutoUpdate{Interval: "5m"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.Equal(t, "POST /api/stacks/create/standalone/repository?endpointId=3", server.path)
+	assert.Equal(t, map[string]any{
+		"name":                     "web",
+		"repositoryURL":            "https://git.example.com/ops/stacks.git",
+		"repositoryReferenceName":  "refs/heads/main",
+		"composeFile":              "web/docker-compose.yml",
+		"repositoryAuthentication": true,
+		"repositoryUsername":       "deploy",
+		"repositoryPassword":       "token",
+		"tlsskipVerify":            false,
+		"env":                      []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+		"autoUpdate":               map[string]any{"interval": "5m", "forceUpdate": false, "forcePullImage": false},
+	}, server.payload)
+
+	_, err = server.client().CreateStackFromGit(models.StackGitCreateOptions{Name: "web", EnvironmentID: 3})
+	assert.Error(t, err, "repository URL is required")
+}
+
+func TestGetStackGitSettings(t *testing.T) {
+	server := newGitStackServer(t)
+
+	settings, err := server.client().GetStackGitSettings(1)
+	require.NoError(t, err)
+	assert.Equal(t, models.StackGitSettings{
+		StackID: 1,
+		StackGitConfig: models.StackGitConfig{
+			RepositoryURL: "https://git.example.com/ops/stacks.git",
+			ReferenceName: "refs/heads/main",
+			ComposeFile:   "web/docker-compose.yml",
+			CommitHash:    "bc4c183d",
+		},
+		Authenticated: true,
+		AutoUpdate: &models.StackAutoUpdate{
+			Interval:       "5m",
+			Webhook:        "05de31a2-79fa-4644-9c12-faa67e5c49f0",
+			ForcePullImage: true,
+		},
+		WebhookURL: server.URL + "/api/stacks/webhooks/05de31a2-79fa-4644-9c12-faa67e5c49f0",
+	}, settings)
+
+	_, err = server.client().GetStackGitSettings(2)
+	assert.ErrorContains(t, err, "not deployed from a git repository")
+}
+
+func TestUpdateStackAutoUpdate(t *testing.T) {
+	server := newGitStackServer(t)
+
+	err := server.client().UpdateStackAutoUpdate(1, &models.StackAutoUpdate{Interval: "1h", ForceUpdate: true})
+	require.NoError(t, err)
+	assert.Equal(t, "POST /api/stacks/1/git?endpointId=3", server.path)
+	assert.Equal(t, map[string]any{
+		"autoUpdate":               map[string]any{"interval": "1h", "forceUpdate": true, "forcePullImage": false},
+		"env":                      []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+		"repositoryReferenceName":  "refs/heads/main",
+		"repositoryAuthentication": true,
+		"repositoryUsername":       "deploy",
+		"tlsskipVerify":            false,
+	}, server.payload, "the git settings and env of the stack must be kept")
+
+	err = server.client().UpdateStackAutoUpdate(1, nil)
+	require.NoError(t, err)
+	assert.Nil(t, server.payload["autoUpdate"], "nil settings must disable auto-updates")
+
+	server.path = ""
+	err = server.client().UpdateStackAutoUpdate(2, nil)
+	assert.Error(t, err)
+	assert.Empty(t, server.path)
+}
+
+func TestRedeployStackFromGit(t *testing.T) {
+	server := newGitStackServer(t)
+
+	err := server.client().RedeployStackFromGit(1, models.StackGitRedeployOptions{PullImage: true})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT /api/stacks/1/git/redeploy?endpointId=3", server.path)
+	assert.Equal(t, map[string]any{
+		"env":                      []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+		"prune":                    false,
+		"pullImage":                true,
+		"repositoryReferenceName":  "refs/heads/main",
+		"repositoryAuthentication": true,
+		"repositoryUsername":       "deploy",
+	}, server.payload)
+
+	err = server.client().RedeployStackFromGit(1, models.StackGitRedeployOptions{ReferenceName: "refs/tags/v2", Prune: true})
+	require.NoError(t, err)
+	assert.Equal(t, "refs/tags/v2", server.payload["repositoryReferenceName"])
+	assert.Equal(t, true, server.payload["prune"])
+
+	server.path = ""
+	err = server.client().RedeployStackFromGit(2, models.StackGitRedeployOptions{})
+	assert.Error(t, err)
+	assert.Empty(t, server.path)
+}
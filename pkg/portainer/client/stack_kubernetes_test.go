@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateKubernetesStack(t *testing.T) {
+	var (
+		request string
+		payload map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte(`{"Id": 21, "Type": 3, "EndpointId": 7}`))
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+
+	id, err := client.CreateKubernetesStack(models.KubernetesStackCreateOptions{
+		Name:          "shop",
+		EnvironmentID: 7,
+		Namespace:     "shop",
+		Manifest:      "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 21, id)
+	assert.Equal(t, "POST /api/stacks/create/kubernetes/string?endpointId=7", request)
+	assert.Equal(t, map[string]any{
+		"stackName":        "shop",
+		"namespace":        "shop",
+		"composeFormat":    false,
+		"stackFileContent": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n",
+	}, payload)
+}
+
+func TestUpdateKubernetesStack(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	client := server.client(new(MockPortainerAPI))
+
+	require.NoError(t, client.UpdateKubernetesStack(3, "kind: ConfigMap"))
+	assert.Equal(t, []string{"PUT /api/stacks/3?endpointId=7"}, server.requests)
+	assert.Equal(t, map[string]any{"stackFileContent": "kind: ConfigMap"}, server.payload)
+
+	err := client.UpdateKubernetesStack(1, "kind: ConfigMap")
+	assert.ErrorContains(t, err, "stack 1 is not a kubernetes stack")
+	assert.Equal(t, ErrorKindInvalid, ClassifyError(err).Kind)
+}
@@ -0,0 +1,279 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// ErrEdgeStackUnsupported is returned when an operation that only exists for regular stacks is requested for an edge stack
+var ErrEdgeStackUnsupported = errors.New("operation not supported for edge stacks")
+
+// ErrAmbiguousStackID is returned when a stack is looked up by ID without a kind and the ID matches both
+// a regular stack and an edge stack, as Portainer numbers them separately
+var ErrAmbiguousStackID = errors.New("the ID matches both a regular stack and an edge stack, the kind of the stack must be given")
+
+// resolvedStack is a stack looked up by ID. Exactly one of regular and edge is set.
+type resolvedStack struct {
+	// regular is the response of GET /stacks/{id} for a regular stack
+	regular json.RawMessage
+	edge    *apimodels.PortainereeEdgeStack
+}
+
+// resolveStack looks up a stack by ID among the regular and the edge stacks.
+// kind is models.StackKindRegular or models.StackKindEdge to look up a single kind of stack. If it is empty,
+// both kinds are looked up and an ID matching both is rejected with ErrAmbiguousStackID rather than
+// acting on one of the two stacks.
+func (c *PortainerClient) resolveStack(id int, kind string) (resolvedStack, error) {
+	switch kind {
+	case "", models.StackKindRegular, models.StackKindEdge:
+	default:
+		return resolvedStack{}, fmt.Errorf("invalid stack kind: %s", kind)
+	}
+
+	var stack resolvedStack
+
+	if kind != models.StackKindEdge {
+		var raw json.RawMessage
+		err := c.doJSON(apiRequest{method: http.MethodGet, path: fmt.Sprintf("/stacks/%d", id)}, &raw)
+		if err == nil {
+			stack.regular = raw
+		} else if !shouldFallbackToEdge(err) {
+			return resolvedStack{}, fmt.Errorf("failed to get stack details: %w", err)
+		}
+	}
+
+	if kind != models.StackKindRegular {
+		edgeStack, err := c.getEdgeStack(id)
+		if err == nil {
+			stack.edge = edgeStack
+		} else if !errors.Is(err, ErrNotFound) {
+			return resolvedStack{}, err
+		}
+	}
+
+	switch {
+	case stack.regular != nil && stack.edge != nil:
+		return resolvedStack{}, fmt.Errorf("stack %d: %w", id, ErrAmbiguousStackID)
+	case stack.regular == nil && stack.edge == nil:
+		return resolvedStack{}, &APIError{Kind: ErrorKindNotFound, Message: fmt.Sprintf("stack %d not found", id)}
+	}
+
+	return stack, nil
+}
+
+// regularDetails decodes the details of a resolved regular stack
+func (s resolvedStack) regularDetails() (regularStackDetails, error) {
+	var details regularStackDetails
+	if err := json.Unmarshal(s.regular, &details); err != nil {
+		return regularStackDetails{}, fmt.Errorf("failed to decode stack details: %w", err)
+	}
+
+	return details, nil
+}
+
+// StartStack starts the services of a stopped regular stack.
+//
+// Parameters:
+//   - id: The ID of the stack to start
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
+//
+// Returns:
+//   - ErrEdgeStackUnsupported if the stack is an edge stack
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) StartStack(id int, kind string) error {
+	return c.setRegularStackStatusHTTP(id, kind, "start")
+}
+
+// StopStack stops the services of a regular stack. The stack and its file are kept.
+//
+// Parameters:
+//   - id: The ID of the stack to stop
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
+//
+// Returns:
+//   - ErrEdgeStackUnsupported if the stack is an edge stack
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) StopStack(id int, kind string) error {
+	return c.setRegularStackStatusHTTP(id, kind, "stop")
+}
+
+func (c *PortainerClient) setRegularStackStatusHTTP(id int, kind string, action string) error {
+	stack, err := c.resolveStack(id, kind)
+	if err != nil {
+		return err
+	}
+	if stack.edge != nil {
+		return fmt.Errorf("failed to %s stack %d: %w", action, id, ErrEdgeStackUnsupported)
+	}
+
+	details, err := stack.regularDetails()
+	if err != nil {
+		return err
+	}
+
+	err = c.doJSON(apiRequest{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/stacks/%d/%s", id, action),
+		query:  url.Values{"endpointId": {strconv.Itoa(details.EndpointId)}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s stack: %w", action, err)
+	}
+
+	return nil
+}
+
+// RedeployStack redeploys a stack with its current file and environment variables.
+// Stacks deployed from git are pulled from their repository first.
+// Edge stacks are redeployed on all the environments of their edge groups, pruning is not supported for them.
+//
+// Parameters:
+//   - id: The ID of the stack to redeploy
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
+//   - opts: The redeploy options (pull images, prune services)
+//
+// Returns:
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) RedeployStack(id int, kind string, opts models.StackRedeployOptions) error {
+	stack, err := c.resolveStack(id, kind)
+	if err != nil {
+		return err
+	}
+	if stack.edge != nil {
+		return c.redeployEdgeStackHTTP(stack.edge, opts)
+	}
+
+	details, err := stack.regularDetails()
+	if err != nil {
+		return err
+	}
+
+	if details.GitConfig != nil && details.GitConfig.URL != "" {
+		return c.RedeployStackFromGit(id, models.StackGitRedeployOptions{PullImage: opts.PullImage, Prune: opts.Prune})
+	}
+
+	env, err := parseStackEnv(details.Env)
+	if err != nil {
+		return err
+	}
+
+	file, err := c.getRegularStackFileHTTP(id)
+	if err != nil {
+		return fmt.Errorf("failed to get stack file: %w", err)
+	}
+
+	if err := c.updateRegularStackHTTP(id, details, file, nonNilEnv(env), opts); err != nil {
+		return fmt.Errorf("failed to redeploy stack: %w", err)
+	}
+
+	return nil
+}
+
+func (c *PortainerClient) redeployEdgeStackHTTP(edgeStack *apimodels.PortainereeEdgeStack, opts models.StackRedeployOptions) error {
+	if opts.Prune {
+		return fmt.Errorf("prune is %w", ErrEdgeStackUnsupported)
+	}
+
+	file, err := c.cli.GetEdgeStackFile(edgeStack.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get edge stack file: %w", err)
+	}
+
+	payload := apimodels.EdgestacksUpdateEdgeStackPayload{
+		StackFileContent: file,
+		EdgeGroups:       edgeStack.EdgeGroups,
+		DeploymentType:   edgeStack.DeploymentType,
+		EnvVars:          edgeStack.EnvVars,
+		Registries:       edgeStack.Registries,
+		PrePullImage:     edgeStack.PrePullImage,
+		RePullImage:      opts.PullImage,
+		UpdateVersion:    true,
+	}
+
+	err = c.doJSON(apiRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/edge_stacks/%d", edgeStack.ID),
+		body:   payload,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to redeploy edge stack: %w", err)
+	}
+
+	return nil
+}
+
+// getEdgeStack returns an edge stack from the list of edge stacks
+func (c *PortainerClient) getEdgeStack(id int) (*apimodels.PortainereeEdgeStack, error) {
+	edgeStacks, err := c.cli.ListEdgeStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge stacks: %w", err)
+	}
+
+	for _, edgeStack := range edgeStacks {
+		if int(edgeStack.ID) == id {
+			return edgeStack, nil
+		}
+	}
+
+	return nil, &APIError{Kind: ErrorKindNotFound, Message: fmt.Sprintf("stack %d not found", id)}
+}
+
+// DeleteStack removes a stack and its services. Edge stacks are removed from all the environments of their edge groups.
+//
+// Parameters:
+//   - id: The ID of the stack to delete
+//   - kind: models.StackKindRegular or models.StackKindEdge, or empty to reject an ID matching both kinds
+//   - removeVolumes: Also remove the volumes of the stack. Not supported for edge stacks.
+//
+// Returns:
+//   - ErrAmbiguousStackID if kind is empty and the ID matches both a regular and an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) DeleteStack(id int, kind string, removeVolumes bool) error {
+	stack, err := c.resolveStack(id, kind)
+	if err != nil {
+		return err
+	}
+
+	if stack.edge != nil {
+		if removeVolumes {
+			return fmt.Errorf("removing volumes is %w", ErrEdgeStackUnsupported)
+		}
+
+		err = c.doJSON(apiRequest{method: http.MethodDelete, path: fmt.Sprintf("/edge_stacks/%d", id)}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to delete edge stack: %w", err)
+		}
+		return nil
+	}
+
+	details, err := stack.regularDetails()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"endpointId": {strconv.Itoa(details.EndpointId)}}
+	if removeVolumes {
+		query.Set("removeVolumes", "true")
+	}
+
+	err = c.doJSON(apiRequest{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/stacks/%d", id),
+		query:  query,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete stack: %w", err)
+	}
+
+	return nil
+}
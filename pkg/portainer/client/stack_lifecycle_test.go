@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stackLifecycleServer is a local stand-in for the stack endpoints of the Portainer API.
+// It serves a regular stack (ID 1), a git stack (ID 2) and reports any other stack as an edge stack.
+// It records the writes it receives.
+type stackLifecycleServer struct {
+	*httptest.Server
+	requests []string
+	payload  map[string]any
+}
+
+func newStackLifecycleServer(t *testing.T) *stackLifecycleServer {
+	t.Helper()
+
+	s := &stackLifecycleServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			s.requests = append(s.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+			s.payload = nil
+			_ = json.NewDecoder(r.Body).Decode(&s.payload)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/stacks/1":
+			_, _ = w.Write([]byte(`{"Id": 1, "EndpointId": 3, "Env": [{"name": "TOKEN", "value": "secret"}]}`))
+		case "/api/stacks/1/file":
+			_, _ = w.Write([]byte(`{"StackFileContent": "services: {}"}`))
+		case "/api/stacks/2":
+			_, _ = w.Write([]byte(`{"Id": 2, "EndpointId": 3, "GitConfig": {"URL": "https://git.example.com/ops/stacks.git", "ReferenceName": "refs/heads/main"}}`))
+		case "/api/stacks/3":
+			_, _ = w.Write([]byte(`{"Id": 3, "Type": 3, "EndpointId": 7, "Namespace": "shop"}`))
+		default:
+			http.Error(w, `{"message":"Unable to find a stack with the specified identifier inside the database"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *stackLifecycleServer) client(api PortainerAPIClient) *PortainerClient {
+	return &PortainerClient{cli: api, serverURL: s.URL, token: "test-token"}
+}
+
+// edgeStacksAPI returns a mocked API client listing edge stacks with the given IDs
+func edgeStacksAPI(ids ...int64) *MockPortainerAPI {
+	edgeStacks := make([]*apimodels.PortainereeEdgeStack, len(ids))
+	for i, id := range ids {
+		edgeStacks[i] = &apimodels.PortainereeEdgeStack{ID: id}
+	}
+
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListEdgeStacks").Return(edgeStacks, nil)
+	return mockAPI
+}
+
+func TestStartAndStopStack(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	client := server.client(edgeStacksAPI(5))
+
+	require.NoError(t, client.StartStack(1, ""))
+	require.NoError(t, client.StopStack(1, ""))
+	assert.Equal(t, []string{
+		"POST /api/stacks/1/start?endpointId=3",
+		"POST /api/stacks/1/stop?endpointId=3",
+	}, server.requests)
+
+	err := client.StopStack(5, "")
+	assert.ErrorIs(t, err, ErrEdgeStackUnsupported)
+}
+
+func TestRedeployStack(t *testing.T) {
+	t.Run("regular stack", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(edgeStacksAPI()).RedeployStack(1, "", models.StackRedeployOptions{PullImage: true, Prune: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PUT /api/stacks/1?endpointId=3"}, server.requests)
+		assert.Equal(t, map[string]any{
+			"StackFileContent": "services: {}",
+			"Env":              []any{map[string]any{"name": "TOKEN", "value": "secret"}},
+			"PullImage":        true,
+			"Prune":            true,
+		}, server.payload)
+	})
+
+	t.Run("git stack", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(edgeStacksAPI()).RedeployStack(2, "", models.StackRedeployOptions{PullImage: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PUT /api/stacks/2/git/redeploy?endpointId=3"}, server.requests)
+		assert.Equal(t, true, server.payload["pullImage"])
+		assert.Equal(t, "refs/heads/main", server.payload["repositoryReferenceName"])
+	})
+
+	t.Run("edge stack", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEdgeStacks").Return([]*apimodels.PortainereeEdgeStack{
+			{ID: 5, EdgeGroups: []int64{1, 2}, DeploymentType: 1, Registries: []int64{}},
+		}, nil)
+		mockAPI.On("GetEdgeStackFile", int64(5)).Return("apiVersion: v1", nil)
+
+		err := server.client(mockAPI).RedeployStack(5, "", models.StackRedeployOptions{PullImage: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PUT /api/edge_stacks/5?"}, server.requests)
+		assert.Equal(t, "apiVersion: v1", server.payload["stackFileContent"])
+		assert.Equal(t, []any{float64(1), float64(2)}, server.payload["edgeGroups"])
+		assert.Equal(t, float64(1), server.payload["deploymentType"], "the deployment type of the edge stack must be kept")
+		assert.Equal(t, true, server.payload["updateVersion"])
+		assert.Equal(t, true, server.payload["rePullImage"])
+
+		err = server.client(mockAPI).RedeployStack(5, "", models.StackRedeployOptions{Prune: true})
+		assert.ErrorIs(t, err, ErrEdgeStackUnsupported)
+
+		err = server.client(mockAPI).RedeployStack(6, "", models.StackRedeployOptions{})
+		assert.Error(t, err)
+		assert.Len(t, server.requests, 1)
+	})
+}
+
+func TestDeleteStack(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	client := server.client(edgeStacksAPI(5))
+
+	require.NoError(t, client.DeleteStack(1, "", true))
+	require.NoError(t, client.DeleteStack(5, "", false))
+	assert.Equal(t, []string{
+		"DELETE /api/stacks/1?endpointId=3&removeVolumes=true",
+		"DELETE /api/edge_stacks/5?",
+	}, server.requests)
+
+	err := client.DeleteStack(5, "", true)
+	assert.ErrorIs(t, err, ErrEdgeStackUnsupported)
+	assert.Len(t, server.requests, 2)
+}
+
+func TestStackKindResolution(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	// Edge stack 1 shares its ID with regular stack 1
+	client := server.client(edgeStacksAPI(1, 5))
+
+	err := client.DeleteStack(1, "", false)
+	assert.ErrorIs(t, err, ErrAmbiguousStackID)
+	err = client.RedeployStack(1, "", models.StackRedeployOptions{})
+	assert.ErrorIs(t, err, ErrAmbiguousStackID)
+	err = client.StopStack(1, "")
+	assert.ErrorIs(t, err, ErrAmbiguousStackID)
+	assert.Empty(t, server.requests, "no write must be sent for an ambiguous ID")
+
+	require.NoError(t, client.DeleteStack(1, models.StackKindRegular, false))
+	require.NoError(t, client.DeleteStack(1, models.StackKindEdge, false))
+	assert.Equal(t, []string{
+		"DELETE /api/stacks/1?endpointId=3",
+		"DELETE /api/edge_stacks/1?",
+	}, server.requests)
+
+	err = client.DeleteStack(5, models.StackKindRegular, false)
+	assert.ErrorIs(t, err, ErrNotFound, "a missing regular stack must not fall back to the edge stack with the same ID")
+	err = client.DeleteStack(9, "", false)
+	assert.ErrorIs(t, err, ErrNotFound)
+	err = client.DeleteStack(1, "compose", false)
+	assert.ErrorContains(t, err, "invalid stack kind")
+	assert.Len(t, server.requests, 2)
+}
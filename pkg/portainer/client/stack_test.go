@@ -15,6 +15,7 @@ import (
 	"github.com/portainer/portainer-mcp/pkg/portainer/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetStacks(t *testing.T) {
@@ -48,12 +49,14 @@ func TestGetStacks(t *testing.T) {
 					Name:                "stack1",
 					CreatedAt:           time.Unix(now, 0).Format(time.RFC3339),
 					EnvironmentGroupIds: []int{1, 2},
+					Kind:                models.StackKindEdge,
 				},
 				{
 					ID:                  2,
 					Name:                "stack2",
 					CreatedAt:           time.Unix(now, 0).Format(time.RFC3339),
 					EnvironmentGroupIds: []int{3},
+					Kind:                models.StackKindEdge,
 				},
 			},
 		},
@@ -69,12 +72,17 @@ func TestGetStacks(t *testing.T) {
 		},
 	}
 
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAPI := new(MockPortainerAPI)
 			mockAPI.On("ListEdgeStacks").Return(tt.mockStacks, tt.mockError)
 
-			client := &PortainerClient{cli: mockAPI}
+			client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "test-token"}
 
 			stacks, err := client.GetStacks()
 
@@ -112,7 +120,11 @@ func TestGetStacksRegular(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+	// The edge stack shares its ID with the regular stack, both must be listed
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListEdgeStacks").Return([]*apimodels.PortainereeEdgeStack{{ID: 10, Name: "edge-stack", CreationDate: now, EdgeGroups: []int64{1}}}, nil)
+
+	client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "test-token"}
 
 	stacks, err := client.GetStacks()
 
@@ -123,77 +135,143 @@ func TestGetStacksRegular(t *testing.T) {
 			Name:                "regular-stack",
 			CreatedAt:           time.Unix(now, 0).Format(time.RFC3339),
 			EnvironmentGroupIds: []int{},
+			Kind:                models.StackKindCompose,
+			Status:              models.StackStatusRunning,
+			EnvironmentID:       3,
+		},
+		{
+			ID:                  10,
+			Name:                "edge-stack",
+			CreatedAt:           time.Unix(now, 0).Format(time.RFC3339),
+			EnvironmentGroupIds: []int{1},
+			Kind:                models.StackKindEdge,
 		},
 	}, stacks)
+
+	t.Run("regular stacks cannot be listed", func(t *testing.T) {
+		client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "wrong-token"}
+
+		_, err := client.GetStacks()
+		assert.ErrorContains(t, err, "failed to list regular stacks")
+	})
+}
+
+func TestGetStack(t *testing.T) {
+	server := newStackLifecycleServer(t)
+
+	t.Run("regular stack", func(t *testing.T) {
+		stack, err := server.client(edgeStacksAPI(5)).GetStack(2, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, stack.ID)
+		assert.Equal(t, 3, stack.EnvironmentID)
+		require.NotNil(t, stack.Git)
+		assert.Equal(t, "https://git.example.com/ops/stacks.git", stack.Git.RepositoryURL)
+	})
+
+	t.Run("edge stack", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEdgeStacks").Return([]*apimodels.PortainereeEdgeStack{{ID: 5, Name: "edge", EdgeGroups: []int64{1}}}, nil)
+
+		stack, err := server.client(mockAPI).GetStack(5, "")
+		require.NoError(t, err)
+		assert.Equal(t, models.StackKindEdge, stack.Kind)
+		assert.Equal(t, []int{1}, stack.EnvironmentGroupIds)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEdgeStacks").Return([]*apimodels.PortainereeEdgeStack{}, nil)
+
+		_, err := server.client(mockAPI).GetStack(9, "")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("shared ID", func(t *testing.T) {
+		client := server.client(edgeStacksAPI(1, 5))
+
+		_, err := client.GetStack(1, "")
+		assert.ErrorIs(t, err, ErrAmbiguousStackID)
+
+		stack, err := client.GetStack(1, models.StackKindEdge)
+		require.NoError(t, err)
+		assert.Equal(t, models.StackKindEdge, stack.Kind)
+
+		_, err = client.GetStack(5, models.StackKindRegular)
+		assert.ErrorIs(t, err, ErrNotFound, "a missing regular stack must not be replaced by the edge stack with the same ID")
+	})
+}
+
+func TestGetEdgeStackStatus(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListEdgeStacks").Return([]*apimodels.PortainereeEdgeStack{
+		{ID: 5, Name: "edge", Status: map[string]apimodels.PortainerEdgeStackStatus{
+			"2": {EndpointID: 2, Status: []*apimodels.PortainerEdgeStackDeploymentStatus{{Type: 7, Time: 1609459200}}},
+		}},
+	}, nil)
+
+	client := &PortainerClient{cli: mockAPI}
+
+	status, err := client.GetEdgeStackStatus(5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, status.StackID)
+	assert.Equal(t, models.EdgeStackStatusSummary{Total: 1, Running: 1}, status.Summary)
+	require.Len(t, status.Environments, 1)
+	assert.Equal(t, 2, status.Environments[0].EnvironmentID)
+
+	_, err = client.GetEdgeStackStatus(6)
+	assert.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestGetStackFile(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	// Edge stack 1 shares its ID with regular stack 1
+	mockAPI := edgeStacksAPI(1, 5)
+	mockAPI.On("GetEdgeStackFile", int64(1)).Return("edge: 1", nil)
+	mockAPI.On("GetEdgeStackFile", int64(5)).Return("edge: 5", nil)
+	client := server.client(mockAPI)
+
 	tests := []struct {
 		name          string
 		stackID       int
-		mockFile      string
-		mockError     error
+		kind          string
 		expected      string
-		expectedError bool
+		expectedError error
 	}{
-		{
-			name:     "successful retrieval",
-			stackID:  1,
-			mockFile: "version: '3'\nservices:\n  web:\n    image: nginx",
-			expected: "version: '3'\nservices:\n  web:\n    image: nginx",
-		},
-		{
-			name:          "get file error",
-			stackID:       2,
-			mockError:     errors.New("failed to get stack file"),
-			expectedError: true,
-		},
+		{name: "regular stack", stackID: 1, kind: models.StackKindRegular, expected: "services: {}"},
+		{name: "edge stack", stackID: 1, kind: models.StackKindEdge, expected: "edge: 1"},
+		{name: "edge stack without kind", stackID: 5, expected: "edge: 5"},
+		{name: "ambiguous ID", stackID: 1, expectedError: ErrAmbiguousStackID},
+		{name: "no fallback to the edge stack", stackID: 5, kind: models.StackKindRegular, expectedError: ErrNotFound},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockAPI := new(MockPortainerAPI)
-			mockAPI.On("GetEdgeStackFile", int64(tt.stackID)).Return(tt.mockFile, tt.mockError)
-
-			client := &PortainerClient{cli: mockAPI}
+			file, err := client.GetStackFile(tt.stackID, tt.kind)
 
-			file, err := client.GetStackFile(tt.stackID)
-
-			if tt.expectedError {
-				assert.Error(t, err)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
 				return
 			}
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, file)
-			mockAPI.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGetStackFileRegular(t *testing.T) {
-	stackID := 42
-	stackFile := "version: '3'\nservices:\n  web:\n    image: nginx"
-
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet || r.URL.Path != "/api/stacks/42/file" || r.Header.Get("X-API-Key") != "test-token" {
-			http.Error(w, "bad request", http.StatusBadRequest)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(struct {
-			StackFileContent string `json:"StackFileContent"`
-		}{
-			StackFileContent: stackFile,
-		})
-	}))
-	defer server.Close()
-
-	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+func TestGetEdgeStackFile(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("GetEdgeStackFile", int64(42)).Return("services: {}", nil)
+	mockAPI.On("GetEdgeStackFile", int64(43)).Return("", errors.New("not found"))
 
-	file, err := client.GetStackFile(stackID)
+	client := &PortainerClient{cli: mockAPI}
 
+	file, err := client.GetEdgeStackFile(42)
 	assert.NoError(t, err)
-	assert.Equal(t, stackFile, file)
+	assert.Equal(t, "services: {}", file)
+
+	_, err = client.GetEdgeStackFile(43)
+	assert.ErrorContains(t, err, "failed to get edge stack file")
+	mockAPI.AssertExpectations(t)
 }
 
 func TestCreateStack(t *testing.T) {
@@ -232,7 +310,11 @@ func TestCreateStack(t *testing.T) {
 
 			client := &PortainerClient{cli: mockAPI}
 
-			id, err := client.CreateStack(tt.stackName, tt.stackFile, tt.environmentGroupIds)
+			id, err := client.CreateStack(models.StackCreateOptions{
+				Name:                tt.stackName,
+				File:                tt.stackFile,
+				EnvironmentGroupIds: tt.environmentGroupIds,
+			})
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -245,46 +327,164 @@ func TestCreateStack(t *testing.T) {
 	}
 }
 
+func TestCreateRegularStack(t *testing.T) {
+	var requestPath string
+	var payload regularStackCreatePayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/3/docker/swarm":
+			_ = json.NewEncoder(w).Encode(map[string]string{"ID": "swarm-id"})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/4/docker/swarm":
+			http.Error(w, "This node is not a swarm manager", http.StatusServiceUnavailable)
+		case r.Method == http.MethodPost:
+			requestPath = r.URL.Path + "?" + r.URL.RawQuery
+			payload = regularStackCreatePayload{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			_ = json.NewEncoder(w).Encode(models.RegularStack{ID: 7, Name: payload.Name})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	env := []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}}
+
+	tests := []struct {
+		name            string
+		opts            models.StackCreateOptions
+		expectedPath    string
+		expectedPayload regularStackCreatePayload
+		expectError     bool
+	}{
+		{
+			name:            "standalone by default",
+			opts:            models.StackCreateOptions{Name: "web", File: "services: {}", EnvironmentID: 3, Env: env},
+			expectedPath:    "/api/stacks/create/standalone/string?endpointId=3",
+			expectedPayload: regularStackCreatePayload{Name: "web", StackFileContent: "services: {}", Env: env},
+		},
+		{
+			name:            "swarm",
+			opts:            models.StackCreateOptions{Name: "web", File: "services: {}", EnvironmentID: 3, Type: models.StackTypeSwarm},
+			expectedPath:    "/api/stacks/create/swarm/string?endpointId=3",
+			expectedPayload: regularStackCreatePayload{Name: "web", StackFileContent: "services: {}", SwarmID: "swarm-id"},
+		},
+		{
+			name:        "swarm on a standalone environment",
+			opts:        models.StackCreateOptions{Name: "web", File: "services: {}", EnvironmentID: 4, Type: models.StackTypeSwarm},
+			expectError: true,
+		},
+		{
+			name:        "invalid type",
+			opts:        models.StackCreateOptions{Name: "web", File: "services: {}", EnvironmentID: 3, Type: "kubernetes"},
+			expectError: true,
+		},
+		{
+			name:        "no target",
+			opts:        models.StackCreateOptions{Name: "web", File: "services: {}"},
+			expectError: true,
+		},
+		{
+			name:        "env on edge stack",
+			opts:        models.StackCreateOptions{Name: "web", File: "services: {}", EnvironmentGroupIds: []int{1}, Env: env},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestPath = ""
+			client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+
+			id, err := client.CreateStack(tt.opts)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Empty(t, requestPath, "no stack must be created")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 7, id)
+			assert.Equal(t, tt.expectedPath, requestPath)
+			assert.Equal(t, tt.expectedPayload, payload)
+		})
+	}
+}
+
 func TestUpdateStack(t *testing.T) {
+	stackFile := "version: '3'\nservices:\n  web:\n    image: nginx:latest"
+
 	tests := []struct {
 		name                string
 		stackID             int
-		stackFile           string
+		kind                string
 		environmentGroupIds []int
+		envOverrides        []models.StackEnvVar
 		mockError           error
-		expectedError       bool
+		expectedError       string
+		expectedEdgeUpdate  bool
 	}{
 		{
-			name:                "successful update",
-			stackID:             1,
-			stackFile:           "version: '3'\nservices:\n  web:\n    image: nginx:latest",
+			name:                "edge stack",
+			stackID:             5,
 			environmentGroupIds: []int{1, 2},
+			expectedEdgeUpdate:  true,
+		},
+		{
+			name:                "edge stack sharing the ID of a regular stack",
+			stackID:             1,
+			kind:                models.StackKindEdge,
+			environmentGroupIds: []int{1},
+			expectedEdgeUpdate:  true,
 		},
 		{
 			name:                "update error",
-			stackID:             2,
-			stackFile:           "version: '3'\nservices:\n  web:\n    image: nginx:latest",
+			stackID:             5,
 			environmentGroupIds: []int{1},
-			mockError:           errors.New("failed to update stack"),
-			expectedError:       true,
+			mockError:           errors.New("api error"),
+			expectedError:       "failed to update edge stack",
+			expectedEdgeUpdate:  true,
+		},
+		{
+			name:          "env overrides on an edge stack",
+			stackID:       5,
+			envOverrides:  []models.StackEnvVar{{Name: "TOKEN", Value: "secret"}},
+			expectedError: "not supported for edge stacks",
+		},
+		{
+			name:          "ambiguous ID",
+			stackID:       1,
+			expectedError: ErrAmbiguousStackID.Error(),
+		},
+		{
+			name:          "no fallback to the edge stack",
+			stackID:       5,
+			kind:          models.StackKindRegular,
+			expectedError: "stack 5 not found",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockAPI := new(MockPortainerAPI)
-			mockAPI.On("UpdateEdgeStack", int64(tt.stackID), tt.stackFile, utils.IntToInt64Slice(tt.environmentGroupIds)).Return(tt.mockError)
+			server := newStackLifecycleServer(t)
+			mockAPI := edgeStacksAPI(1, 5)
+			mockAPI.On("UpdateEdgeStack", int64(tt.stackID), stackFile, utils.IntToInt64Slice(tt.environmentGroupIds)).Return(tt.mockError)
+			client := server.client(mockAPI)
 
-			client := &PortainerClient{cli: mockAPI}
+			err := client.UpdateStack(tt.stackID, tt.kind, stackFile, tt.environmentGroupIds, tt.envOverrides)
 
-			err := client.UpdateStack(tt.stackID, tt.stackFile, tt.environmentGroupIds, nil)
+			if tt.expectedEdgeUpdate {
+				mockAPI.AssertCalled(t, "UpdateEdgeStack", int64(tt.stackID), stackFile, utils.IntToInt64Slice(tt.environmentGroupIds))
+			} else {
+				mockAPI.AssertNotCalled(t, "UpdateEdgeStack", mock.Anything, mock.Anything, mock.Anything)
+			}
+			assert.Empty(t, server.requests, "no regular stack must be updated")
 
-			if tt.expectedError {
-				assert.Error(t, err)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
 				return
 			}
 			assert.NoError(t, err)
-			mockAPI.AssertExpectations(t)
 		})
 	}
 }
@@ -362,10 +562,10 @@ func TestUpdateStackRegular(t *testing.T) {
 	}))
 	defer server.Close()
 
-	mockAPI := new(MockPortainerAPI)
+	mockAPI := edgeStacksAPI()
 	client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "test-token"}
 
-	err := client.UpdateStack(stackID, stackFile, []int{1}, nil)
+	err := client.UpdateStack(stackID, "", stackFile, []int{1}, nil)
 
 	assert.NoError(t, err)
 	assert.True(t, getCalled.Load())
@@ -373,27 +573,81 @@ func TestUpdateStackRegular(t *testing.T) {
 	mockAPI.AssertNotCalled(t, "UpdateEdgeStack", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestUpdateStackFallbackToEdge(t *testing.T) {
-	stackID := 99
-	stackFile := "version: '3'\nservices:\n  web:\n    image: nginx:latest"
-	environmentGroupIds := []int{1, 2}
-
+func TestGetStackEnv(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet && r.URL.Path == "/api/stacks/99" {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/12":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(struct {
+				EndpointId int                `json:"EndpointId"`
+				Env        []stackEnvEntryAlt `json:"Env"`
+			}{
+				EndpointId: 5,
+				Env:        []stackEnvEntryAlt{{Name: "API_KEY", Value: "secret"}},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/99":
 			http.Error(w, "not found", http.StatusNotFound)
-			return
+		default:
+			http.NotFound(w, r)
 		}
-		http.NotFound(w, r)
 	}))
 	defer server.Close()
 
-	mockAPI := new(MockPortainerAPI)
-	mockAPI.On("UpdateEdgeStack", int64(stackID), stackFile, utils.IntToInt64Slice(environmentGroupIds)).Return(nil)
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
 
-	client := &PortainerClient{cli: mockAPI, serverURL: server.URL, token: "test-token"}
+	env, err := client.GetStackEnv(12)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.StackEnvVar{{Name: "API_KEY", Value: "secret"}}, env)
 
-	err := client.UpdateStack(stackID, stackFile, environmentGroupIds, nil)
+	_, err = client.GetStackEnv(99)
+	assert.ErrorIs(t, err, ErrEdgeStackEnv)
+}
 
+func TestReplaceStack(t *testing.T) {
+	stackFile := "services:\n  web:\n    image: nginx:alpine"
+	env := []models.StackEnvVar{{Name: "ONLY", Value: "value"}}
+
+	var updateCalled atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/12":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(struct {
+				EndpointId int                `json:"EndpointId"`
+				Env        []stackEnvEntryAlt `json:"Env"`
+			}{
+				EndpointId: 5,
+				Env:        []stackEnvEntryAlt{{Name: "ONLY", Value: "old"}, {Name: "REMOVED", Value: "x"}},
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/api/stacks/12":
+			updateCalled.Store(true)
+			var payload struct {
+				StackFileContent string               `json:"StackFileContent"`
+				Env              []models.StackEnvVar `json:"Env"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			assert.Equal(t, "5", r.URL.Query().Get("endpointId"))
+			assert.Equal(t, stackFile, payload.StackFileContent)
+			assert.Equal(t, env, payload.Env, "the env must be replaced, not merged")
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/99":
+			http.Error(w, "not found", http.StatusNotFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+
+	err := client.ReplaceStack(12, stackFile, env)
 	assert.NoError(t, err)
-	mockAPI.AssertExpectations(t)
+	assert.True(t, updateCalled.Load())
+
+	err = client.ReplaceStack(99, stackFile, env)
+	assert.ErrorIs(t, err, ErrEdgeStackEnv)
 }
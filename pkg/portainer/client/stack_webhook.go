@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+)
+
+// GetStackWebhook retrieves the redeploy webhook of a regular stack.
+// Stacks deployed from git keep their webhook in their auto-update settings.
+//
+// Parameters:
+//   - id: The ID of the stack
+//
+// Returns:
+//   - The webhook of the stack, with its URL if enabled
+//   - ErrEdgeStackUnsupported if the stack is an edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) GetStackWebhook(id int) (models.StackWebhook, error) {
+	details, err := c.getRegularStackHTTP(id)
+	if err != nil {
+		if shouldFallbackToEdge(err) {
+			return models.StackWebhook{}, fmt.Errorf("failed to get the webhook of stack %d: %w", id, ErrEdgeStackUnsupported)
+		}
+		return models.StackWebhook{}, fmt.Errorf("failed to get stack details: %w", err)
+	}
+
+	webhook := models.StackWebhook{StackID: id, Webhook: stackWebhookID(details)}
+	if webhook.Webhook != "" {
+		webhook.Enabled = true
+		webhook.URL = c.apiURL("/stacks/webhooks/"+webhook.Webhook, nil)
+	}
+
+	return webhook, nil
+}
+
+// SetStackWebhook enables or disables the redeploy webhook of a regular stack.
+// For stacks created from a file, Portainer only changes the webhook when the stack is updated,
+// so the stack is redeployed with its current file and environment variables.
+// For stacks deployed from git, the other auto-update settings are kept.
+//
+// Parameters:
+//   - id: The ID of the stack
+//   - webhook: The UUID identifying the new webhook, or an empty string to disable the webhook
+//
+// Returns:
+//   - ErrEdgeStackUnsupported if the stack is an edge stack
+//   - An error if the stack is a kubernetes stack created from a file or if the operation fails
+func (c *PortainerClient) SetStackWebhook(id int, webhook string) error {
+	details, env, err := c.getRegularStackDetailsHTTP(id)
+	if err != nil {
+		if shouldFallbackToEdge(err) {
+			return fmt.Errorf("failed to set the webhook of stack %d: %w", id, ErrEdgeStackUnsupported)
+		}
+		return fmt.Errorf("failed to get stack details: %w", err)
+	}
+
+	if details.GitConfig != nil && details.GitConfig.URL != "" {
+		autoUpdate := &models.StackAutoUpdate{Webhook: webhook}
+		if current := details.AutoUpdate; current != nil {
+			autoUpdate.Interval = current.Interval
+			autoUpdate.ForceUpdate = current.ForceUpdate
+			autoUpdate.ForcePullImage = current.ForcePullImage
+		}
+		return c.UpdateStackAutoUpdate(id, autoUpdate)
+	}
+
+	if details.Type == kubernetesStackType {
+		return &APIError{Kind: ErrorKindInvalid, Message: fmt.Sprintf("the webhook of kubernetes stack %d can only be changed for stacks deployed from git", id)}
+	}
+
+	file, err := c.getRegularStackFileHTTP(id)
+	if err != nil {
+		return fmt.Errorf("failed to get stack file: %w", err)
+	}
+
+	details.Webhook = webhook
+	if err := c.updateRegularStackHTTP(id, details, file, nonNilEnv(env), models.StackRedeployOptions{}); err != nil {
+		return fmt.Errorf("failed to update stack webhook: %w", err)
+	}
+
+	return nil
+}
+
+// TriggerStackWebhook calls the redeploy webhook of a stack.
+//
+// Parameters:
+//   - webhook: The UUID identifying the webhook of the stack
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) TriggerStackWebhook(webhook string) error {
+	if webhook == "" {
+		return &APIError{Kind: ErrorKindInvalid, Message: "a webhook is required"}
+	}
+
+	if err := c.doJSON(apiRequest{method: http.MethodPost, path: "/stacks/webhooks/" + url.PathEscape(webhook)}, nil); err != nil {
+		return fmt.Errorf("failed to trigger stack webhook: %w", err)
+	}
+
+	return nil
+}
+
+// stackWebhookID returns the UUID of the webhook of a regular stack, or an empty string if it is disabled
+func stackWebhookID(details regularStackDetails) string {
+	if details.GitConfig != nil && details.GitConfig.URL != "" {
+		if details.AutoUpdate == nil {
+			return ""
+		}
+		return details.AutoUpdate.Webhook
+	}
+
+	return details.Webhook
+}
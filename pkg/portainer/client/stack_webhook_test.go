@@ -0,0 +1,107 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer-mcp/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStackWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/stacks/1":
+			_, _ = w.Write([]byte(`{"Id": 1, "EndpointId": 3, "Webhook": "05de31a2-79fa-4644-9c12-faa67e5c49f0"}`))
+		case "/api/stacks/2":
+			_, _ = w.Write([]byte(`{"Id": 2, "EndpointId": 3, "GitConfig": {"URL": "https://git.example.com/ops/stacks.git"}, "AutoUpdate": {"Interval": "5m"}}`))
+		default:
+			http.Error(w, `{"message":"Unable to find a stack with the specified identifier inside the database"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &PortainerClient{cli: new(MockPortainerAPI), serverURL: server.URL, token: "test-token"}
+
+	webhook, err := client.GetStackWebhook(1)
+	require.NoError(t, err)
+	assert.Equal(t, models.StackWebhook{
+		StackID: 1,
+		Enabled: true,
+		Webhook: "05de31a2-79fa-4644-9c12-faa67e5c49f0",
+		URL:     server.URL + "/api/stacks/webhooks/05de31a2-79fa-4644-9c12-faa67e5c49f0",
+	}, webhook)
+
+	webhook, err = client.GetStackWebhook(2)
+	require.NoError(t, err)
+	assert.Equal(t, models.StackWebhook{StackID: 2}, webhook)
+
+	_, err = client.GetStackWebhook(5)
+	assert.ErrorIs(t, err, ErrEdgeStackUnsupported)
+}
+
+func TestSetStackWebhook(t *testing.T) {
+	t.Run("stack created from a file", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(new(MockPortainerAPI)).SetStackWebhook(1, "05de31a2-79fa-4644-9c12-faa67e5c49f0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PUT /api/stacks/1?endpointId=3"}, server.requests)
+		assert.Equal(t, "services: {}", server.payload["StackFileContent"])
+		assert.Equal(t, "05de31a2-79fa-4644-9c12-faa67e5c49f0", server.payload["Webhook"])
+		assert.Equal(t, []any{map[string]any{"name": "TOKEN", "value": "secret"}}, server.payload["Env"])
+	})
+
+	t.Run("stack deployed from git", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(new(MockPortainerAPI)).SetStackWebhook(2, "05de31a2-79fa-4644-9c12-faa67e5c49f0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"POST /api/stacks/2/git?endpointId=3"}, server.requests)
+		assert.Equal(t, map[string]any{
+			"webhook":        "05de31a2-79fa-4644-9c12-faa67e5c49f0",
+			"forceUpdate":    false,
+			"forcePullImage": false,
+		}, server.payload["autoUpdate"])
+	})
+
+	t.Run("disable the webhook of a stack deployed from git", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(new(MockPortainerAPI)).SetStackWebhook(2, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"POST /api/stacks/2/git?endpointId=3"}, server.requests)
+		assert.Nil(t, server.payload["autoUpdate"])
+	})
+
+	t.Run("kubernetes stack", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(new(MockPortainerAPI)).SetStackWebhook(3, "05de31a2-79fa-4644-9c12-faa67e5c49f0")
+		require.Error(t, err)
+		assert.Equal(t, ErrorKindInvalid, ClassifyError(err).Kind)
+		assert.Empty(t, server.requests)
+	})
+
+	t.Run("edge stack", func(t *testing.T) {
+		server := newStackLifecycleServer(t)
+
+		err := server.client(new(MockPortainerAPI)).SetStackWebhook(5, "05de31a2-79fa-4644-9c12-faa67e5c49f0")
+		assert.ErrorIs(t, err, ErrEdgeStackUnsupported)
+		assert.Empty(t, server.requests)
+	})
+}
+
+func TestTriggerStackWebhook(t *testing.T) {
+	server := newStackLifecycleServer(t)
+	client := server.client(new(MockPortainerAPI))
+
+	require.NoError(t, client.TriggerStackWebhook("05de31a2-79fa-4644-9c12-faa67e5c49f0"))
+	assert.Equal(t, []string{"POST /api/stacks/webhooks/05de31a2-79fa-4644-9c12-faa67e5c49f0?"}, server.requests)
+
+	err := client.TriggerStackWebhook("")
+	require.Error(t, err)
+	assert.Equal(t, ErrorKindInvalid, ClassifyError(err).Kind)
+}
@@ -13,17 +13,19 @@ import (
 //   - A slice of EnvironmentTag objects
 //   - An error if the operation fails
 func (c *PortainerClient) GetEnvironmentTags() ([]models.EnvironmentTag, error) {
-	tags, err := c.cli.ListTags()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list environment tags: %w", err)
-	}
+	return cachedList(c.cache, CacheKeyTags, func() ([]models.EnvironmentTag, error) {
+		tags, err := c.cli.ListTags()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environment tags: %w", err)
+		}
 
-	environmentTags := make([]models.EnvironmentTag, len(tags))
-	for i, tag := range tags {
-		environmentTags[i] = models.ConvertTagToEnvironmentTag(tag)
-	}
+		environmentTags := make([]models.EnvironmentTag, len(tags))
+		for i, tag := range tags {
+			environmentTags[i] = models.ConvertTagToEnvironmentTag(tag)
+		}
 
-	return environmentTags, nil
+		return environmentTags, nil
+	}, cloneEnvironmentTag)
 }
 
 // CreateEnvironmentTag creates a new environment tag on the Portainer server.
@@ -36,6 +38,8 @@ func (c *PortainerClient) GetEnvironmentTags() ([]models.EnvironmentTag, error)
 //   - The ID of the created environment tag
 //   - An error if the operation fails
 func (c *PortainerClient) CreateEnvironmentTag(name string) (int, error) {
+	defer c.cache.invalidate(CacheKeyTags)
+
 	id, err := c.cli.CreateTag(name)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create environment tag: %w", err)
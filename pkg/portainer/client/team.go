@@ -12,23 +12,25 @@ import (
 //   - A slice of Team objects containing team information
 //   - An error if the operation fails
 func (c *PortainerClient) GetTeams() ([]models.Team, error) {
-	portainerTeams, err := c.cli.ListTeams()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list teams: %w", err)
-	}
+	return cachedList(c.cache, CacheKeyTeams, func() ([]models.Team, error) {
+		portainerTeams, err := c.cli.ListTeams()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
 
-	// Get team memberships to populate team members
-	memberships, err := c.cli.ListTeamMemberships()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list team memberships: %w", err)
-	}
+		// Get team memberships to populate team members
+		memberships, err := c.cli.ListTeamMemberships()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list team memberships: %w", err)
+		}
 
-	teams := make([]models.Team, len(portainerTeams))
-	for i, team := range portainerTeams {
-		teams[i] = models.ConvertToTeam(team, memberships)
-	}
+		teams := make([]models.Team, len(portainerTeams))
+		for i, team := range portainerTeams {
+			teams[i] = models.ConvertToTeam(team, memberships)
+		}
 
-	return teams, nil
+		return teams, nil
+	}, cloneTeam)
 }
 
 // UpdateTeamName updates the name of a team.
@@ -37,6 +39,8 @@ func (c *PortainerClient) GetTeams() ([]models.Team, error) {
 //   - id: The ID of the team to update
 //   - name: The new name for the team
 func (c *PortainerClient) UpdateTeamName(id int, name string) error {
+	defer c.cache.invalidate(CacheKeyTeams)
+
 	return c.cli.UpdateTeamName(id, name)
 }
 
@@ -49,6 +53,8 @@ func (c *PortainerClient) UpdateTeamName(id int, name string) error {
 //   - The ID of the created team
 //   - An error if the operation fails
 func (c *PortainerClient) CreateTeam(name string) (int, error) {
+	defer c.cache.invalidate(CacheKeyTeams)
+
 	id, err := c.cli.CreateTeam(name)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create team: %w", err)
@@ -63,6 +69,9 @@ func (c *PortainerClient) CreateTeam(name string) (int, error) {
 //   - teamId: The ID of the team to update
 //   - userIds: The IDs of the users associated with the team
 func (c *PortainerClient) UpdateTeamMembers(teamId int, userIds []int) error {
+	// Memberships may have been partially updated on failure, so the teams are always invalidated
+	defer c.cache.invalidate(CacheKeyTeams)
+
 	memberships, err := c.cli.ListTeamMemberships()
 	if err != nil {
 		return fmt.Errorf("failed to list team memberships: %w", err)
@@ -12,17 +12,19 @@ import (
 //   - A slice of User objects containing user information
 //   - An error if the operation fails
 func (c *PortainerClient) GetUsers() ([]models.User, error) {
-	portainerUsers, err := c.cli.ListUsers()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
-	}
-
-	users := make([]models.User, len(portainerUsers))
-	for i, user := range portainerUsers {
-		users[i] = models.ConvertToUser(user)
-	}
-
-	return users, nil
+	return cachedList(c.cache, CacheKeyUsers, func() ([]models.User, error) {
+		portainerUsers, err := c.cli.ListUsers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		users := make([]models.User, len(portainerUsers))
+		for i, user := range portainerUsers {
+			users[i] = models.ConvertToUser(user)
+		}
+
+		return users, nil
+	}, nil)
 }
 
 // UpdateUserRole updates the role of a user.
@@ -39,6 +41,8 @@ func (c *PortainerClient) UpdateUserRole(id int, role string) error {
 		return fmt.Errorf("invalid role: must be admin, user or edge_admin")
 	}
 
+	defer c.cache.invalidate(CacheKeyUsers)
+
 	return c.cli.UpdateUserRole(id, roleInt)
 }
 
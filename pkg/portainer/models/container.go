@@ -0,0 +1,307 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Labels set by docker compose on the containers of a project
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+	composeLabelPrefix  = "com.docker.compose."
+)
+
+// redactedValue replaces the values of environment variables, labels and arguments that may hold secrets
+const redactedValue = "********"
+
+// maxHealthOutputLength is the maximum length of the output of the last health check of a container
+const maxHealthOutputLength = 512
+
+// Container is a compact summary of a Docker container, as listed by GET /containers/json
+type Container struct {
+	Name   string `json:"name"`
+	Image  string `json:"image"`
+	State  string `json:"state"`
+	Health string `json:"health,omitempty"`
+	// Ports are formatted like in docker ps, e.g. 8080->80/tcp
+	Ports          []string `json:"ports"`
+	ComposeProject string   `json:"compose_project,omitempty"`
+	// Uptime is the time since the container started, e.g. 2 hours, empty if it is not running
+	Uptime string `json:"uptime,omitempty"`
+}
+
+// ContainerDetails is a trimmed summary of the inspection of a Docker container.
+// The values of the environment variables are not included, and values that may hold secrets are redacted.
+type ContainerDetails struct {
+	ID             string             `json:"id"`
+	Name           string             `json:"name"`
+	Image          string             `json:"image"`
+	Created        string             `json:"created"`
+	Command        []string           `json:"command"`
+	State          ContainerState     `json:"state"`
+	Health         *ContainerHealth   `json:"health,omitempty"`
+	RestartPolicy  string             `json:"restart_policy,omitempty"`
+	RestartCount   int                `json:"restart_count"`
+	ComposeProject string             `json:"compose_project,omitempty"`
+	ComposeService string             `json:"compose_service,omitempty"`
+	Ports          []string           `json:"ports"`
+	Mounts         []ContainerMount   `json:"mounts"`
+	Networks       []ContainerNetwork `json:"networks"`
+	NetworkMode    string             `json:"network_mode,omitempty"`
+	Privileged     bool               `json:"privileged,omitempty"`
+	EnvNames       []string           `json:"env_names"`
+	// Labels excludes the labels set by docker compose, which are summarized by ComposeProject and ComposeService
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ContainerState is the state of an inspected container
+type ContainerState struct {
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code"`
+	Error      string `json:"error,omitempty"`
+	OOMKilled  bool   `json:"oom_killed,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// ContainerHealth is the health check status of an inspected container, with the output of its last check
+type ContainerHealth struct {
+	Status        string `json:"status"`
+	FailingStreak int    `json:"failing_streak"`
+	LastOutput    string `json:"last_output,omitempty"`
+}
+
+// ContainerMount is a volume or bind mount of a container
+type ContainerMount struct {
+	Type string `json:"type"`
+	// Source is the name of the volume, or the host path of a bind mount
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only,omitempty"`
+}
+
+// ContainerNetwork is a network a container is connected to
+type ContainerNetwork struct {
+	Name      string `json:"name"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+func ConvertContainerSummary(rawContainer container.Summary) Container {
+	c := Container{
+		Image:          rawContainer.Image,
+		State:          rawContainer.State,
+		Health:         healthFromStatus(rawContainer.Status),
+		Ports:          make([]string, 0, len(rawContainer.Ports)),
+		ComposeProject: rawContainer.Labels[composeProjectLabel],
+	}
+
+	if len(rawContainer.Names) > 0 {
+		c.Name = strings.TrimPrefix(rawContainer.Names[0], "/")
+	}
+
+	if rawContainer.State == "running" && strings.HasPrefix(rawContainer.Status, "Up ") {
+		uptime := strings.TrimPrefix(rawContainer.Status, "Up ")
+		if i := strings.Index(uptime, " ("); i >= 0 {
+			uptime = uptime[:i]
+		}
+		c.Uptime = uptime
+	}
+
+	for _, port := range rawContainer.Ports {
+		public := ""
+		if port.PublicPort != 0 {
+			public = fmt.Sprint(port.PublicPort)
+		}
+		c.Ports = appendPort(c.Ports, port.IP, public, fmt.Sprint(port.PrivatePort), port.Type)
+	}
+	slices.Sort(c.Ports)
+
+	return c
+}
+
+func ConvertContainerInspect(rawContainer container.InspectResponse) ContainerDetails {
+	details := ContainerDetails{
+		Command:  []string{},
+		Ports:    []string{},
+		Mounts:   make([]ContainerMount, 0, len(rawContainer.Mounts)),
+		Networks: []ContainerNetwork{},
+		EnvNames: []string{},
+	}
+
+	if base := rawContainer.ContainerJSONBase; base != nil {
+		details.ID = base.ID
+		details.Name = strings.TrimPrefix(base.Name, "/")
+		details.Created = base.Created
+		details.RestartCount = base.RestartCount
+		if base.Path != "" {
+			details.Command = redactArgs(append([]string{base.Path}, base.Args...))
+		}
+
+		if state := base.State; state != nil {
+			details.State = ContainerState{
+				Status:     state.Status,
+				ExitCode:   state.ExitCode,
+				Error:      state.Error,
+				OOMKilled:  state.OOMKilled,
+				StartedAt:  dockerTime(state.StartedAt),
+				FinishedAt: dockerTime(state.FinishedAt),
+			}
+			if health := state.Health; health != nil {
+				details.Health = &ContainerHealth{Status: health.Status, FailingStreak: health.FailingStreak}
+				if n := len(health.Log); n > 0 && health.Log[n-1] != nil {
+					details.Health.LastOutput = truncate(strings.TrimSpace(health.Log[n-1].Output), maxHealthOutputLength)
+				}
+			}
+		}
+
+		if hostConfig := base.HostConfig; hostConfig != nil {
+			details.NetworkMode = string(hostConfig.NetworkMode)
+			details.Privileged = hostConfig.Privileged
+			if policy := hostConfig.RestartPolicy; !policy.IsNone() {
+				details.RestartPolicy = string(policy.Name)
+				if policy.MaximumRetryCount > 0 {
+					details.RestartPolicy += fmt.Sprintf(":%d", policy.MaximumRetryCount)
+				}
+			}
+		}
+	}
+
+	if config := rawContainer.Config; config != nil {
+		details.Image = config.Image
+		details.ComposeProject = config.Labels[composeProjectLabel]
+		details.ComposeService = config.Labels[composeServiceLabel]
+
+		for _, env := range config.Env {
+			name, _, _ := strings.Cut(env, "=")
+			details.EnvNames = append(details.EnvNames, name)
+		}
+
+		for key, value := range config.Labels {
+			if strings.HasPrefix(key, composeLabelPrefix) {
+				continue
+			}
+			if details.Labels == nil {
+				details.Labels = map[string]string{}
+			}
+			if isSecretName(key) {
+				value = redactedValue
+			}
+			details.Labels[key] = value
+		}
+	}
+
+	for _, mount := range rawContainer.Mounts {
+		source := mount.Source
+		if mount.Name != "" {
+			source = mount.Name
+		}
+		details.Mounts = append(details.Mounts, ContainerMount{
+			Type:        string(mount.Type),
+			Source:      source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
+		})
+	}
+
+	if settings := rawContainer.NetworkSettings; settings != nil {
+		for port, bindings := range settings.Ports {
+			if len(bindings) == 0 {
+				details.Ports = appendPort(details.Ports, "", "", port.Port(), port.Proto())
+			}
+			for _, binding := range bindings {
+				details.Ports = appendPort(details.Ports, binding.HostIP, binding.HostPort, port.Port(), port.Proto())
+			}
+		}
+
+		for name, endpoint := range settings.Networks {
+			network := ContainerNetwork{Name: name}
+			if endpoint != nil {
+				network.IPAddress = endpoint.IPAddress
+			}
+			details.Networks = append(details.Networks, network)
+		}
+	}
+
+	slices.Sort(details.Ports)
+	slices.SortFunc(details.Networks, func(a, b ContainerNetwork) int { return strings.Compare(a.Name, b.Name) })
+
+	return details
+}
+
+// healthFromStatus extracts the health of a container from its status, e.g. "Up 2 hours (healthy)"
+func healthFromStatus(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return container.Healthy
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return container.Unhealthy
+	case strings.HasSuffix(status, "(health: starting)"):
+		return container.Starting
+	default:
+		return ""
+	}
+}
+
+// appendPort appends a port formatted like in docker ps, unless it is already in ports.
+// The host IP is omitted when the port is published on all interfaces, so that IPv4 and IPv6 bindings are merged.
+func appendPort(ports []string, hostIP, hostPort, containerPort, proto string) []string {
+	if proto == "" {
+		proto = "tcp"
+	}
+
+	formatted := fmt.Sprintf("%s/%s", containerPort, proto)
+	if hostPort != "" {
+		if hostIP != "" && hostIP != "0.0.0.0" && hostIP != "::" {
+			hostPort = hostIP + ":" + hostPort
+		}
+		formatted = hostPort + "->" + formatted
+	}
+
+	if slices.Contains(ports, formatted) {
+		return ports
+	}
+	return append(ports, formatted)
+}
+
+// redactArgs redacts the values of the arguments of a command that look like secrets, e.g. --password=value
+func redactArgs(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if name, _, ok := strings.Cut(arg, "="); ok && isSecretName(name) {
+			arg = name + "=" + redactedValue
+		}
+		redacted = append(redacted, arg)
+	}
+	return redacted
+}
+
+// isSecretName returns true if the name of a label or an argument suggests that its value is a secret
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "passwd", "secret", "token", "apikey", "api_key", "api-key", "credential"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerTime returns a timestamp of the Docker API, or an empty string for the zero time Docker returns for unset timestamps
+func dockerTime(timestamp string) string {
+	if strings.HasPrefix(timestamp, "0001-01-01") {
+		return ""
+	}
+	return timestamp
+}
+
+// truncate shortens s to at most length bytes, marking the truncation
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length] + "..."
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertContainerSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    container.Summary
+		expected Container
+	}{
+		{
+			name: "running compose container",
+			input: container.Summary{
+				Names:  []string{"/web-app-1"},
+				Image:  "nginx:1.27",
+				State:  "running",
+				Status: "Up 2 hours (healthy)",
+				Ports: []container.Port{
+					{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+					{IP: "::", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+					{IP: "127.0.0.1", PrivatePort: 9113, PublicPort: 9113, Type: "tcp"},
+					{PrivatePort: 443, Type: "tcp"},
+				},
+				Labels: map[string]string{"com.docker.compose.project": "web"},
+			},
+			expected: Container{
+				Name:           "web-app-1",
+				Image:          "nginx:1.27",
+				State:          "running",
+				Health:         "healthy",
+				Ports:          []string{"127.0.0.1:9113->9113/tcp", "443/tcp", "8080->80/tcp"},
+				ComposeProject: "web",
+				Uptime:         "2 hours",
+			},
+		},
+		{
+			name: "starting health check",
+			input: container.Summary{
+				Names:  []string{"/db"},
+				Image:  "postgres:16",
+				State:  "running",
+				Status: "Up About a minute (health: starting)",
+			},
+			expected: Container{
+				Name:   "db",
+				Image:  "postgres:16",
+				State:  "running",
+				Health: "starting",
+				Ports:  []string{},
+				Uptime: "About a minute",
+			},
+		},
+		{
+			name: "exited container",
+			input: container.Summary{
+				Names:  []string{"/migrate"},
+				Image:  "app:2",
+				State:  "exited",
+				Status: "Exited (1) 3 days ago",
+			},
+			expected: Container{
+				Name:  "migrate",
+				Image: "app:2",
+				State: "exited",
+				Ports: []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConvertContainerSummary(tt.input))
+		})
+	}
+}
+
+func TestConvertContainerInspect(t *testing.T) {
+	input := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:           "4f1c2b7e9a0d",
+			Name:         "/web-app-1",
+			Created:      "2025-01-01T10:00:00Z",
+			Path:         "/entrypoint.sh",
+			Args:         []string{"serve", "--db-password=hunter2", "--port=80"},
+			RestartCount: 2,
+			State: &container.State{
+				Status:     "running",
+				Running:    true,
+				StartedAt:  "2025-01-01T10:00:05Z",
+				FinishedAt: "0001-01-01T00:00:00Z",
+				Health: &container.Health{
+					Status:        container.Unhealthy,
+					FailingStreak: 3,
+					Log: []*container.HealthcheckResult{
+						{ExitCode: 0, Output: "ok"},
+						{ExitCode: 1, Output: "connection refused\n"},
+					},
+				},
+			},
+			HostConfig: &container.HostConfig{
+				NetworkMode:   "web_default",
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5},
+			},
+		},
+		Config: &container.Config{
+			Image: "nginx:1.27",
+			Env:   []string{"API_TOKEN=s3cr3t", "LOG_LEVEL=debug"},
+			Labels: map[string]string{
+				"com.docker.compose.project": "web",
+				"com.docker.compose.service": "app",
+				"traefik.enable":             "true",
+				"backup.secret":              "s3cr3t",
+			},
+		},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeVolume, Name: "web_data", Source: "/var/lib/docker/volumes/web_data/_data", Destination: "/data", RW: true},
+			{Type: mount.TypeBind, Source: "/etc/web/nginx.conf", Destination: "/etc/nginx/nginx.conf"},
+		},
+		NetworkSettings: &container.NetworkSettings{
+			NetworkSettingsBase: container.NetworkSettingsBase{
+				Ports: nat.PortMap{
+					"80/tcp":  []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "8080"}, {HostIP: "::", HostPort: "8080"}},
+					"443/tcp": nil,
+				},
+			},
+			Networks: map[string]*network.EndpointSettings{
+				"web_default": {IPAddress: "172.18.0.2"},
+				"proxy":       {IPAddress: "172.19.0.4"},
+			},
+		},
+	}
+
+	assert.Equal(t, ContainerDetails{
+		ID:      "4f1c2b7e9a0d",
+		Name:    "web-app-1",
+		Image:   "nginx:1.27",
+		Created: "2025-01-01T10:00:00Z",
+		Command: []string{"/entrypoint.sh", "serve", "--db-password=********", "--port=80"},
+		State: ContainerState{
+			Status:    "running",
+			StartedAt: "2025-01-01T10:00:05Z",
+		},
+		Health:         &ContainerHealth{Status: "unhealthy", FailingStreak: 3, LastOutput: "connection refused"},
+		RestartPolicy:  "on-failure:5",
+		RestartCount:   2,
+		ComposeProject: "web",
+		ComposeService: "app",
+		Ports:          []string{"443/tcp", "8080->80/tcp"},
+		Mounts: []ContainerMount{
+			{Type: "volume", Source: "web_data", Destination: "/data"},
+			{Type: "bind", Source: "/etc/web/nginx.conf", Destination: "/etc/nginx/nginx.conf", ReadOnly: true},
+		},
+		Networks: []ContainerNetwork{
+			{Name: "proxy", IPAddress: "172.19.0.4"},
+			{Name: "web_default", IPAddress: "172.18.0.2"},
+		},
+		NetworkMode: "web_default",
+		EnvNames:    []string{"API_TOKEN", "LOG_LEVEL"},
+		Labels:      map[string]string{"traefik.enable": "true", "backup.secret": "********"},
+	}, ConvertContainerInspect(input))
+}
+
+func TestConvertContainerInspectEmpty(t *testing.T) {
+	assert.Equal(t, ContainerDetails{
+		Command:  []string{},
+		Ports:    []string{},
+		Mounts:   []ContainerMount{},
+		Networks: []ContainerNetwork{},
+		EnvNames: []string{},
+	}, ConvertContainerInspect(container.InspectResponse{}))
+}
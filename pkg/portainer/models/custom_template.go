@@ -0,0 +1,91 @@
+package models
+
+import (
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// CustomTemplate is a Portainer custom template, a stack file saved in Portainer with its variables
+type CustomTemplate struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Type is the type of the stacks deployed from the template: standalone, swarm or kubernetes
+	Type      string                   `json:"type"`
+	Variables []CustomTemplateVariable `json:"variables"`
+}
+
+// CustomTemplateVariable is a variable of a custom template, used as {{ name }} in its file
+type CustomTemplateVariable struct {
+	Name         string `json:"name"`
+	Label        string `json:"label"`
+	DefaultValue string `json:"default_value,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// CustomTemplateCreateOptions defines a custom template to create from the content of its file
+type CustomTemplateCreateOptions struct {
+	Title       string
+	Description string
+	// Type is the type of the stacks deployed from the template, standalone or swarm
+	Type      string
+	File      string
+	Variables []CustomTemplateVariable
+}
+
+// Types of custom templates in the Portainer API
+const (
+	customTemplateTypeSwarm      = 1
+	customTemplateTypeCompose    = 2
+	customTemplateTypeKubernetes = 3
+)
+
+func ConvertCustomTemplate(rawTemplate *apimodels.PortainereeCustomTemplate) CustomTemplate {
+	customTemplate := CustomTemplate{
+		ID:          int(rawTemplate.ID),
+		Title:       rawTemplate.Title,
+		Description: rawTemplate.Description,
+		Type:        convertCustomTemplateType(rawTemplate.Type),
+		Variables:   make([]CustomTemplateVariable, 0, len(rawTemplate.Variables)),
+	}
+
+	for _, variable := range rawTemplate.Variables {
+		if variable == nil {
+			continue
+		}
+		customTemplate.Variables = append(customTemplate.Variables, CustomTemplateVariable{
+			Name:         variable.Name,
+			Label:        variable.Label,
+			DefaultValue: variable.DefaultValue,
+			Description:  variable.Description,
+		})
+	}
+
+	return customTemplate
+}
+
+func convertCustomTemplateType(templateType int64) string {
+	switch templateType {
+	case customTemplateTypeSwarm:
+		return StackTypeSwarm
+	case customTemplateTypeCompose:
+		return StackTypeStandalone
+	case customTemplateTypeKubernetes:
+		return StackKindKubernetes
+	default:
+		return StackKindUnknown
+	}
+}
+
+// ConvertCustomTemplateTypeToID returns the Portainer API type of a custom template of the given type
+func ConvertCustomTemplateTypeToID(templateType string) (int64, bool) {
+	switch templateType {
+	case StackTypeSwarm:
+		return customTemplateTypeSwarm, true
+	case StackTypeStandalone:
+		return customTemplateTypeCompose, true
+	case StackKindKubernetes:
+		return customTemplateTypeKubernetes, true
+	default:
+		return 0, false
+	}
+}
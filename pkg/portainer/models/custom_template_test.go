@@ -0,0 +1,58 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+func TestConvertCustomTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawTemplate *apimodels.PortainereeCustomTemplate
+		want        CustomTemplate
+	}{
+		{
+			name: "compose template with variables",
+			rawTemplate: &apimodels.PortainereeCustomTemplate{
+				ID:          4,
+				Title:       "web",
+				Description: "Web server",
+				Type:        2,
+				Variables: []*apimodels.PortainerCustomTemplateVariableDefinition{
+					{Name: "port", Label: "Port", DefaultValue: "8080", Description: "[port] Published port"},
+					nil,
+				},
+			},
+			want: CustomTemplate{
+				ID:          4,
+				Title:       "web",
+				Description: "Web server",
+				Type:        StackTypeStandalone,
+				Variables: []CustomTemplateVariable{
+					{Name: "port", Label: "Port", DefaultValue: "8080", Description: "[port] Published port"},
+				},
+			},
+		},
+		{
+			name:        "swarm template",
+			rawTemplate: &apimodels.PortainereeCustomTemplate{ID: 5, Title: "db", Type: 1},
+			want:        CustomTemplate{ID: 5, Title: "db", Type: StackTypeSwarm, Variables: []CustomTemplateVariable{}},
+		},
+		{
+			name:        "kubernetes template",
+			rawTemplate: &apimodels.PortainereeCustomTemplate{ID: 6, Title: "app", Type: 3},
+			want:        CustomTemplate{ID: 6, Title: "app", Type: StackKindKubernetes, Variables: []CustomTemplateVariable{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertCustomTemplate(tt.rawTemplate)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertCustomTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewEdgeAgentDeployCommand returns the command to run on a host to deploy an edge agent
+// connecting to the environment identified by edgeKey.
+//
+// Parameters:
+//   - platform: The platform of the host, one of EnvironmentPlatformDockerStandalone,
+//     EnvironmentPlatformDockerSwarm or EnvironmentPlatformKubernetes
+//   - edgeID: The identifier of the edge agent
+//   - edgeKey: The edge key of the environment
+//   - agentVersion: The version of the agent, matching the version of the Portainer server (e.g. 2.31.2)
+//   - insecurePoll: Whether the agent skips the verification of the Portainer server TLS certificate,
+//     only needed when the server uses a self-signed certificate
+//
+// Returns:
+//   - The deploy command
+//   - An error if the platform is not supported
+func NewEdgeAgentDeployCommand(platform, edgeID, edgeKey, agentVersion string, insecurePoll bool) (string, error) {
+	insecurePollValue := "0"
+	if insecurePoll {
+		insecurePollValue = "1"
+	}
+
+	switch platform {
+	case EnvironmentPlatformDockerStandalone:
+		return strings.Join([]string{
+			"docker run -d",
+			"  -v /var/run/docker.sock:/var/run/docker.sock",
+			"  -v /var/lib/docker/volumes:/var/lib/docker/volumes",
+			"  -v /:/host",
+			"  -v portainer_agent_data:/data",
+			"  --restart always",
+			"  -e EDGE=1",
+			"  -e EDGE_ID=" + edgeID,
+			"  -e EDGE_KEY=" + edgeKey,
+			"  -e EDGE_INSECURE_POLL=" + insecurePollValue,
+			"  --name portainer_edge_agent",
+			"  portainer/agent:" + agentVersion,
+		}, " \\\n"), nil
+
+	case EnvironmentPlatformDockerSwarm:
+		return "docker network create --driver overlay portainer_agent_network && \\\n" + strings.Join([]string{
+			"docker service create",
+			"  --name portainer_edge_agent",
+			"  --network portainer_agent_network",
+			"  -e EDGE=1",
+			"  -e EDGE_ID=" + edgeID,
+			"  -e EDGE_KEY=" + edgeKey,
+			"  -e EDGE_INSECURE_POLL=" + insecurePollValue,
+			"  -e AGENT_CLUSTER_ADDR=tasks.portainer_edge_agent",
+			"  --mode global",
+			"  --constraint 'node.platform.os == linux'",
+			"  --mount type=bind,src=//var/run/docker.sock,dst=/var/run/docker.sock",
+			"  --mount type=bind,src=//var/lib/docker/volumes,dst=/var/lib/docker/volumes",
+			"  --mount type=bind,src=//,dst=/host",
+			"  --mount type=volume,src=portainer_agent_data,dst=/data",
+			"  portainer/agent:" + agentVersion,
+		}, " \\\n"), nil
+
+	case EnvironmentPlatformKubernetes:
+		return fmt.Sprintf(
+			`curl https://downloads.portainer.io/%s/portainer-edge-agent-setup.sh | bash -s -- "%s" "%s" "%s" "" ""`,
+			downloadsRelease(agentVersion), edgeID, edgeKey, insecurePollValue,
+		), nil
+
+	default:
+		return "", fmt.Errorf("unsupported edge agent platform: %s", platform)
+	}
+}
+
+// downloadsRelease returns the release folder of the Portainer downloads site for a version,
+// e.g. ee2-31 for 2.31.2
+func downloadsRelease(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return "ee" + version
+	}
+	return fmt.Sprintf("ee%s-%s", parts[0], parts[1])
+}
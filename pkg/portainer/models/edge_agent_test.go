@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewEdgeAgentDeployCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		platform     string
+		insecurePoll bool
+		contains     []string
+		excludes     []string
+		wantErr      bool
+	}{
+		{
+			name:     "docker standalone",
+			platform: EnvironmentPlatformDockerStandalone,
+			contains: []string{"docker run -d", "-e EDGE_ID=edge-id", "-e EDGE_KEY=edge-key", "-e EDGE_INSECURE_POLL=0", "portainer/agent:2.31.2"},
+			excludes: []string{"EDGE_INSECURE_POLL=1"},
+		},
+		{
+			name:         "docker standalone with self-signed certificates",
+			platform:     EnvironmentPlatformDockerStandalone,
+			insecurePoll: true,
+			contains:     []string{"-e EDGE_INSECURE_POLL=1"},
+		},
+		{
+			name:     "docker swarm",
+			platform: EnvironmentPlatformDockerSwarm,
+			contains: []string{"docker service create", "--mode global", "-e EDGE_KEY=edge-key", "portainer/agent:2.31.2"},
+		},
+		{
+			name:     "kubernetes",
+			platform: EnvironmentPlatformKubernetes,
+			contains: []string{"https://downloads.portainer.io/ee2-31/portainer-edge-agent-setup.sh", `"edge-id" "edge-key" "0"`},
+		},
+		{
+			name:         "kubernetes with self-signed certificates",
+			platform:     EnvironmentPlatformKubernetes,
+			insecurePoll: true,
+			contains:     []string{`"edge-id" "edge-key" "1"`},
+		},
+		{
+			name:     "unsupported platform",
+			platform: EnvironmentPlatformAzureACI,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEdgeAgentDeployCommand(tt.platform, "edge-id", "edge-key", "2.31.2", tt.insecurePoll)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewEdgeAgentDeployCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("NewEdgeAgentDeployCommand() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("NewEdgeAgentDeployCommand() = %q, want it not to contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
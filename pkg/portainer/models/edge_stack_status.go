@@ -0,0 +1,148 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// Deployment status of an edge stack on an environment
+const (
+	EdgeStackStatusPending   = "pending"
+	EdgeStackStatusDeploying = "deploying"
+	EdgeStackStatusRunning   = "running"
+	EdgeStackStatusRemoving  = "removing"
+	EdgeStackStatusError     = "error"
+)
+
+// EdgeStackStatus describes the rollout of an edge stack across the environments of its edge groups
+type EdgeStackStatus struct {
+	StackID      int                          `json:"stack_id"`
+	Name         string                       `json:"name"`
+	Summary      EdgeStackStatusSummary       `json:"summary"`
+	Environments []EdgeStackEnvironmentStatus `json:"environments"`
+}
+
+// EdgeStackStatusSummary counts the environments of an edge stack in each deployment status
+type EdgeStackStatusSummary struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Deploying int `json:"deploying"`
+	Running   int `json:"running"`
+	Removing  int `json:"removing"`
+	Error     int `json:"error"`
+}
+
+// EdgeStackEnvironmentStatus is the deployment status of an edge stack on a single environment
+type EdgeStackEnvironmentStatus struct {
+	EnvironmentID int    `json:"environment_id"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	// Version is the version of the stack file deployed on the environment
+	Version int `json:"version,omitempty"`
+	// UpdatedAt is the time of the latest status reported by the environment, in RFC3339 format
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// Status types reported by the edge agents, see EdgeStackStatusType in the Portainer API
+const (
+	edgeStatusPending             = 0
+	edgeStatusDeploymentReceived  = 1
+	edgeStatusError               = 2
+	edgeStatusAcknowledged        = 3
+	edgeStatusRemoved             = 4
+	edgeStatusRemoteUpdateSuccess = 5
+	edgeStatusImagesPulled        = 6
+	edgeStatusRunning             = 7
+	edgeStatusDeploying           = 8
+	edgeStatusRemoving            = 9
+	edgeStatusPausedDeploying     = 10
+	edgeStatusPausedRemoving      = 11
+	edgeStatusCompleted           = 12
+)
+
+// ConvertEdgeStackStatus builds the per-environment status of an edge stack from the status map of the stack.
+// The status of each environment is the latest status it reported.
+func ConvertEdgeStackStatus(rawEdgeStack *apimodels.PortainereeEdgeStack) EdgeStackStatus {
+	status := EdgeStackStatus{
+		StackID:      int(rawEdgeStack.ID),
+		Name:         rawEdgeStack.Name,
+		Environments: make([]EdgeStackEnvironmentStatus, 0, len(rawEdgeStack.Status)),
+	}
+
+	for key, rawStatus := range rawEdgeStack.Status {
+		environmentID := int(rawStatus.EndpointID)
+		if id, err := strconv.Atoi(key); err == nil {
+			environmentID = id
+		}
+
+		envStatus := EdgeStackEnvironmentStatus{
+			EnvironmentID: environmentID,
+			Status:        EdgeStackStatusPending,
+		}
+
+		if latest := latestEdgeDeploymentStatus(rawStatus.Status); latest != nil {
+			envStatus.Status = convertEdgeStatusType(latest.Type)
+			envStatus.Version = int(latest.Version)
+			envStatus.UpdatedAt = formatUnixTime(latest.Time)
+			if envStatus.Status == EdgeStackStatusError {
+				envStatus.Error = latest.Error
+			}
+		}
+
+		status.Environments = append(status.Environments, envStatus)
+	}
+
+	sort.Slice(status.Environments, func(i, j int) bool {
+		return status.Environments[i].EnvironmentID < status.Environments[j].EnvironmentID
+	})
+
+	for _, envStatus := range status.Environments {
+		status.Summary.Total++
+		switch envStatus.Status {
+		case EdgeStackStatusPending:
+			status.Summary.Pending++
+		case EdgeStackStatusDeploying:
+			status.Summary.Deploying++
+		case EdgeStackStatusRunning:
+			status.Summary.Running++
+		case EdgeStackStatusRemoving:
+			status.Summary.Removing++
+		case EdgeStackStatusError:
+			status.Summary.Error++
+		}
+	}
+
+	return status
+}
+
+// latestEdgeDeploymentStatus returns the most recent entry of the status history of an environment
+func latestEdgeDeploymentStatus(history []*apimodels.PortainerEdgeStackDeploymentStatus) *apimodels.PortainerEdgeStackDeploymentStatus {
+	var latest *apimodels.PortainerEdgeStackDeploymentStatus
+	for _, entry := range history {
+		if entry == nil {
+			continue
+		}
+		if latest == nil || entry.Time >= latest.Time {
+			latest = entry
+		}
+	}
+	return latest
+}
+
+func convertEdgeStatusType(statusType int64) string {
+	switch statusType {
+	case edgeStatusError:
+		return EdgeStackStatusError
+	case edgeStatusAcknowledged, edgeStatusImagesPulled, edgeStatusDeploying:
+		return EdgeStackStatusDeploying
+	case edgeStatusRunning, edgeStatusRemoteUpdateSuccess, edgeStatusCompleted:
+		return EdgeStackStatusRunning
+	case edgeStatusRemoved, edgeStatusRemoving, edgeStatusPausedRemoving:
+		return EdgeStackStatusRemoving
+	default:
+		// pending, deployment received, paused deploying and unknown types
+		return EdgeStackStatusPending
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertEdgeStackStatus(t *testing.T) {
+	edgeStack := &models.PortainereeEdgeStack{
+		ID:   4,
+		Name: "monitoring",
+		Status: map[string]models.PortainerEdgeStackStatus{
+			"3": {EndpointID: 3, Status: []*models.PortainerEdgeStackDeploymentStatus{
+				{Type: edgeStatusAcknowledged, Time: 1609459200, Version: 1},
+				{Type: edgeStatusRunning, Time: 1609459260, Version: 1},
+			}},
+			"1": {EndpointID: 1, Status: []*models.PortainerEdgeStackDeploymentStatus{
+				{Type: edgeStatusDeploying, Time: 1609459200, Version: 2},
+				{Type: edgeStatusError, Time: 1609459300, Version: 2, Error: "pull access denied for app"},
+			}},
+			"2": {EndpointID: 2},
+			"5": {EndpointID: 5, Status: []*models.PortainerEdgeStackDeploymentStatus{
+				{Type: edgeStatusImagesPulled, Time: 1609459200, Version: 2},
+			}},
+		},
+	}
+
+	status := ConvertEdgeStackStatus(edgeStack)
+
+	assert.Equal(t, EdgeStackStatus{
+		StackID: 4,
+		Name:    "monitoring",
+		Summary: EdgeStackStatusSummary{Total: 4, Pending: 1, Deploying: 1, Running: 1, Error: 1},
+		Environments: []EdgeStackEnvironmentStatus{
+			{EnvironmentID: 1, Status: EdgeStackStatusError, Error: "pull access denied for app", Version: 2, UpdatedAt: "2021-01-01T00:01:40Z"},
+			{EnvironmentID: 2, Status: EdgeStackStatusPending},
+			{EnvironmentID: 3, Status: EdgeStackStatusRunning, Version: 1, UpdatedAt: "2021-01-01T00:01:00Z"},
+			{EnvironmentID: 5, Status: EdgeStackStatusDeploying, Version: 2, UpdatedAt: "2021-01-01T00:00:00Z"},
+		},
+	}, status)
+}
+
+func TestConvertEdgeStatusType(t *testing.T) {
+	tests := []struct {
+		statusType int64
+		expected   string
+	}{
+		{edgeStatusPending, EdgeStackStatusPending},
+		{edgeStatusDeploymentReceived, EdgeStackStatusPending},
+		{edgeStatusPausedDeploying, EdgeStackStatusPending},
+		{edgeStatusAcknowledged, EdgeStackStatusDeploying},
+		{edgeStatusDeploying, EdgeStackStatusDeploying},
+		{edgeStatusRunning, EdgeStackStatusRunning},
+		{edgeStatusCompleted, EdgeStackStatusRunning},
+		{edgeStatusRemoving, EdgeStackStatusRemoving},
+		{edgeStatusError, EdgeStackStatusError},
+		{99, EdgeStackStatusPending},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, convertEdgeStatusType(tt.statusType), "status type %d", tt.statusType)
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/portainer/portainer-mcp/pkg/portainer/utils"
 )
@@ -15,6 +17,36 @@ type Environment struct {
 	TeamAccesses map[int]string `json:"team_accesses"`
 }
 
+// EnvironmentDetails is the detailed view of an environment
+type EnvironmentDetails struct {
+	Environment
+	URL               string `json:"url"`
+	PublicURL         string `json:"public_url,omitempty"`
+	GroupID           int    `json:"group_id"`
+	Platform          string `json:"platform"`
+	DockerVersion     string `json:"docker_version,omitempty"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	AgentVersion      string `json:"agent_version,omitempty"`
+	// EdgeCheckinInterval is the check-in interval of an edge agent in seconds, 0 means the global default
+	EdgeCheckinInterval int `json:"edge_checkin_interval,omitempty"`
+	// LastCheckIn is the time of the last edge agent check-in, in RFC3339 format
+	LastCheckIn string               `json:"last_check_in,omitempty"`
+	Snapshot    *EnvironmentSnapshot `json:"snapshot,omitempty"`
+}
+
+// EnvironmentSnapshot holds the resource counts of the latest snapshot of an environment
+type EnvironmentSnapshot struct {
+	// Time is the time of the snapshot, in RFC3339 format
+	Time                  string `json:"time"`
+	ContainerCount        int    `json:"container_count"`
+	RunningContainerCount int    `json:"running_container_count"`
+	StoppedContainerCount int    `json:"stopped_container_count"`
+	VolumeCount           int    `json:"volume_count"`
+	ImageCount            int    `json:"image_count"`
+	StackCount            int    `json:"stack_count"`
+	NodeCount             int    `json:"node_count"`
+}
+
 // Environment status constants
 const (
 	EnvironmentStatusActive   = "active"
@@ -34,6 +66,136 @@ const (
 	EnvironmentTypeUnknown             = "unknown"
 )
 
+// Environment platform constants
+const (
+	EnvironmentPlatformDockerStandalone = "docker-standalone"
+	EnvironmentPlatformDockerSwarm      = "docker-swarm"
+	EnvironmentPlatformPodman           = "podman"
+	EnvironmentPlatformKubernetes       = "kubernetes"
+	EnvironmentPlatformAzureACI         = "azure-aci"
+)
+
+// Kinds of environments that can be created
+const (
+	EnvironmentKindAgent     = "agent"
+	EnvironmentKindEdgeAgent = "edge-agent"
+)
+
+// EnvironmentCreateOptions holds the settings of an environment to create
+type EnvironmentCreateOptions struct {
+	Name string
+	// Kind is the kind of environment to create (EnvironmentKind* constants)
+	Kind string
+	// URL is the address of the agent for agent environments (e.g. 10.0.0.1:9001),
+	// or the Portainer server URL used by the agent for edge agent environments
+	URL string
+	// PublicURL is the address where exposed containers are reachable
+	PublicURL string
+	// TunnelServerAddress is the address of the Portainer tunnel server used by edge agents (e.g. portainer.example.com:8000)
+	TunnelServerAddress string
+	// GroupID is the ID of the access group of the environment, 0 means the default group
+	GroupID int
+	TagIds  []int
+	// EdgeCheckinInterval is the check-in interval of edge agents in seconds, 0 means the global default
+	EdgeCheckinInterval int
+}
+
+// CreatedEnvironment is an environment created on the Portainer server,
+// with the information needed to deploy the agent of edge environments
+type CreatedEnvironment struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	EdgeKey string `json:"edge_key,omitempty"`
+	// EdgeID is the identifier to give to the edge agent when deploying it
+	EdgeID        string `json:"edge_id,omitempty"`
+	DeployCommand string `json:"deploy_command,omitempty"`
+}
+
+// Environment list sort fields
+const (
+	EnvironmentSortName = "name"
+	EnvironmentSortID   = "id"
+)
+
+// Environment list sort orders
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// EnvironmentListOptions holds the filters, sorting and pagination of an environment list.
+// Zero values disable the corresponding filter.
+type EnvironmentListOptions struct {
+	// Search is matched by Portainer against the environment name, URL, group name and tag names
+	Search string
+	// TagIds only keeps the environments that have all these tags
+	TagIds []int
+	// AccessGroupIds only keeps the environments that belong to one of these access groups
+	AccessGroupIds []int
+	// EnvironmentGroupIds only keeps the environments that belong to one of these environment groups
+	EnvironmentGroupIds []int
+	// Types only keeps the environments of these types (EnvironmentType* constants)
+	Types []string
+	// Status only keeps the environments with this status (EnvironmentStatusActive or EnvironmentStatusInactive)
+	Status string
+	// Sort is the field used to sort the environments (EnvironmentSort* constants), defaults to ID
+	Sort string
+	// Order is the sort order (SortOrder* constants), defaults to ascending
+	Order string
+	// Start is the index of the first environment to return
+	Start int
+	// Limit is the maximum number of environments to return, 0 means no limit
+	Limit int
+}
+
+// EnvironmentPage is a page of a filtered environment list
+type EnvironmentPage struct {
+	Environments []Environment `json:"environments"`
+	// Total is the number of environments matching the filters, across all pages
+	Total int `json:"total"`
+	Start int `json:"start"`
+	Limit int `json:"limit,omitempty"`
+	// NextStart is the start of the next page, if there is one
+	NextStart int `json:"nextStart,omitempty"`
+}
+
+// ConvertEnvironmentTypeToID converts an environment type (EnvironmentType* constants)
+// to the numeric type used by Portainer. It returns false for an unknown type.
+func ConvertEnvironmentTypeToID(environmentType string) (int, bool) {
+	switch environmentType {
+	case EnvironmentTypeDockerLocal:
+		return 1, true
+	case EnvironmentTypeDockerAgent:
+		return 2, true
+	case EnvironmentTypeAzureACI:
+		return 3, true
+	case EnvironmentTypeDockerEdgeAgent:
+		return 4, true
+	case EnvironmentTypeKubernetesLocal:
+		return 5, true
+	case EnvironmentTypeKubernetesAgent:
+		return 6, true
+	case EnvironmentTypeKubernetesEdgeAgent:
+		return 7, true
+	default:
+		return 0, false
+	}
+}
+
+// ConvertEnvironmentStatusToID converts an environment status to the numeric status used by Portainer.
+// It returns false for an unknown status.
+func ConvertEnvironmentStatusToID(status string) (int, bool) {
+	switch status {
+	case EnvironmentStatusActive:
+		return 1, true
+	case EnvironmentStatusInactive:
+		return 2, true
+	default:
+		return 0, false
+	}
+}
+
 func ConvertEndpointToEnvironment(rawEndpoint *apimodels.PortainereeEndpoint) Environment {
 	return Environment{
 		ID:           int(rawEndpoint.ID),
@@ -91,3 +253,87 @@ func convertEnvironmentType(rawEndpoint *apimodels.PortainereeEndpoint) string {
 		return EnvironmentTypeUnknown
 	}
 }
+
+// ConvertEndpointToEnvironmentDetails converts an endpoint to the detailed view of an environment.
+// Versions and resource counts are taken from the latest snapshot of the environment, if any.
+func ConvertEndpointToEnvironmentDetails(rawEndpoint *apimodels.PortainereeEndpoint) EnvironmentDetails {
+	details := EnvironmentDetails{
+		Environment:         ConvertEndpointToEnvironment(rawEndpoint),
+		URL:                 rawEndpoint.URL,
+		PublicURL:           rawEndpoint.PublicURL,
+		GroupID:             int(rawEndpoint.GroupID),
+		EdgeCheckinInterval: int(rawEndpoint.EdgeCheckinInterval),
+		LastCheckIn:         formatUnixTime(rawEndpoint.LastCheckInDate),
+	}
+
+	if rawEndpoint.Agent != nil {
+		details.AgentVersion = rawEndpoint.Agent.Version
+	}
+
+	switch rawEndpoint.Type {
+	case 3:
+		details.Platform = EnvironmentPlatformAzureACI
+	case 5, 6, 7:
+		details.Platform = EnvironmentPlatformKubernetes
+		if rawEndpoint.Kubernetes != nil {
+			if snapshot := latestKubernetesSnapshot(rawEndpoint.Kubernetes.Snapshots); snapshot != nil {
+				details.KubernetesVersion = snapshot.KubernetesVersion
+				details.Snapshot = &EnvironmentSnapshot{
+					Time:      formatUnixTime(snapshot.Time),
+					NodeCount: int(snapshot.NodeCount),
+				}
+			}
+		}
+	default:
+		details.Platform = EnvironmentPlatformDockerStandalone
+		if snapshot := latestDockerSnapshot(rawEndpoint.Snapshots); snapshot != nil {
+			switch {
+			case snapshot.IsPodman:
+				details.Platform = EnvironmentPlatformPodman
+			case snapshot.Swarm:
+				details.Platform = EnvironmentPlatformDockerSwarm
+			}
+			details.DockerVersion = snapshot.DockerVersion
+			details.Snapshot = &EnvironmentSnapshot{
+				Time:                  formatUnixTime(snapshot.Time),
+				ContainerCount:        int(snapshot.ContainerCount),
+				RunningContainerCount: int(snapshot.RunningContainerCount),
+				StoppedContainerCount: int(snapshot.StoppedContainerCount),
+				VolumeCount:           int(snapshot.VolumeCount),
+				ImageCount:            int(snapshot.ImageCount),
+				StackCount:            int(snapshot.StackCount),
+				NodeCount:             int(snapshot.NodeCount),
+			}
+		}
+	}
+
+	return details
+}
+
+func latestDockerSnapshot(snapshots []*apimodels.PortainerDockerSnapshot) *apimodels.PortainerDockerSnapshot {
+	var latest *apimodels.PortainerDockerSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot != nil && (latest == nil || snapshot.Time > latest.Time) {
+			latest = snapshot
+		}
+	}
+	return latest
+}
+
+func latestKubernetesSnapshot(snapshots []*apimodels.PortainerKubernetesSnapshot) *apimodels.PortainerKubernetesSnapshot {
+	var latest *apimodels.PortainerKubernetesSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot != nil && (latest == nil || snapshot.Time > latest.Time) {
+			latest = snapshot
+		}
+	}
+	return latest
+}
+
+// formatUnixTime formats a Unix timestamp in RFC3339 format, or returns an empty string if it is not set
+func formatUnixTime(timestamp int64) string {
+	if timestamp <= 0 {
+		return ""
+	}
+	return time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+}
@@ -231,3 +231,161 @@ func TestConvertEnvironmentType(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertEnvironmentTypeToID(t *testing.T) {
+	for typeValue := 1; typeValue <= 7; typeValue++ {
+		environmentType := convertEnvironmentType(&models.PortainereeEndpoint{Type: int64(typeValue)})
+		got, ok := ConvertEnvironmentTypeToID(environmentType)
+		if !ok || got != typeValue {
+			t.Errorf("ConvertEnvironmentTypeToID(%s) = %v, %v, want %v, true", environmentType, got, ok, typeValue)
+		}
+	}
+
+	if _, ok := ConvertEnvironmentTypeToID(EnvironmentTypeUnknown); ok {
+		t.Errorf("ConvertEnvironmentTypeToID(%s) should not be ok", EnvironmentTypeUnknown)
+	}
+}
+
+func TestConvertEnvironmentStatusToID(t *testing.T) {
+	tests := []struct {
+		status string
+		want   int
+		wantOk bool
+	}{
+		{status: EnvironmentStatusActive, want: 1, wantOk: true},
+		{status: EnvironmentStatusInactive, want: 2, wantOk: true},
+		{status: EnvironmentStatusUnknown, want: 0, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got, ok := ConvertEnvironmentStatusToID(tt.status)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ConvertEnvironmentStatusToID() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestConvertEndpointToEnvironmentDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *models.PortainereeEndpoint
+		want     EnvironmentDetails
+	}{
+		{
+			name: "docker swarm edge agent with snapshots",
+			endpoint: &models.PortainereeEndpoint{
+				ID:                  1,
+				Name:                "edge",
+				Type:                4,
+				Heartbeat:           true,
+				URL:                 "tcp://10.0.0.1:9001",
+				PublicURL:           "10.0.0.1",
+				GroupID:             2,
+				EdgeCheckinInterval: 5,
+				LastCheckInDate:     1700000000,
+				Agent:               &models.PortainereeEnvironmentAgentData{Version: "2.31.2"},
+				Snapshots: []*models.PortainerDockerSnapshot{
+					{Time: 1600000000, DockerVersion: "24.0.0"},
+					{
+						Time:                  1700000000,
+						DockerVersion:         "25.0.0",
+						Swarm:                 true,
+						ContainerCount:        3,
+						RunningContainerCount: 2,
+						StoppedContainerCount: 1,
+						VolumeCount:           4,
+						ImageCount:            5,
+						StackCount:            6,
+						NodeCount:             1,
+					},
+				},
+			},
+			want: EnvironmentDetails{
+				Environment: Environment{
+					ID:           1,
+					Name:         "edge",
+					Status:       EnvironmentStatusActive,
+					Type:         EnvironmentTypeDockerEdgeAgent,
+					TagIds:       []int{},
+					UserAccesses: map[int]string{},
+					TeamAccesses: map[int]string{},
+				},
+				URL:                 "tcp://10.0.0.1:9001",
+				PublicURL:           "10.0.0.1",
+				GroupID:             2,
+				Platform:            EnvironmentPlatformDockerSwarm,
+				DockerVersion:       "25.0.0",
+				AgentVersion:        "2.31.2",
+				EdgeCheckinInterval: 5,
+				LastCheckIn:         "2023-11-14T22:13:20Z",
+				Snapshot: &EnvironmentSnapshot{
+					Time:                  "2023-11-14T22:13:20Z",
+					ContainerCount:        3,
+					RunningContainerCount: 2,
+					StoppedContainerCount: 1,
+					VolumeCount:           4,
+					ImageCount:            5,
+					StackCount:            6,
+					NodeCount:             1,
+				},
+			},
+		},
+		{
+			name: "kubernetes agent",
+			endpoint: &models.PortainereeEndpoint{
+				ID:   2,
+				Name: "k8s",
+				Type: 6,
+				Kubernetes: &models.PortainereeKubernetesData{
+					Snapshots: []*models.PortainerKubernetesSnapshot{
+						{Time: 1700000000, KubernetesVersion: "v1.30.0", NodeCount: 3},
+					},
+				},
+			},
+			want: EnvironmentDetails{
+				Environment: Environment{
+					ID:           2,
+					Name:         "k8s",
+					Status:       EnvironmentStatusUnknown,
+					Type:         EnvironmentTypeKubernetesAgent,
+					TagIds:       []int{},
+					UserAccesses: map[int]string{},
+					TeamAccesses: map[int]string{},
+				},
+				Platform:          EnvironmentPlatformKubernetes,
+				KubernetesVersion: "v1.30.0",
+				Snapshot: &EnvironmentSnapshot{
+					Time:      "2023-11-14T22:13:20Z",
+					NodeCount: 3,
+				},
+			},
+		},
+		{
+			name:     "docker environment without snapshot",
+			endpoint: &models.PortainereeEndpoint{ID: 3, Name: "local", Type: 1, Status: 1},
+			want: EnvironmentDetails{
+				Environment: Environment{
+					ID:           3,
+					Name:         "local",
+					Status:       EnvironmentStatusActive,
+					Type:         EnvironmentTypeDockerLocal,
+					TagIds:       []int{},
+					UserAccesses: map[int]string{},
+					TeamAccesses: map[int]string{},
+				},
+				Platform: EnvironmentPlatformDockerStandalone,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertEndpointToEnvironmentDetails(tt.endpoint)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertEndpointToEnvironmentDetails() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,140 @@ type Stack struct {
 	Name                string `json:"name"`
 	CreatedAt           string `json:"created_at"`
 	EnvironmentGroupIds []int  `json:"group_ids"`
+	// Kind is one of compose, swarm, kubernetes or edge
+	Kind string `json:"kind"`
+	// Status is running or stopped for regular stacks, and empty for edge stacks
+	Status        string          `json:"status,omitempty"`
+	EnvironmentID int             `json:"environment_id,omitempty"`
+	CreatedBy     string          `json:"created_by,omitempty"`
+	UpdatedAt     string          `json:"updated_at,omitempty"`
+	UpdatedBy     string          `json:"updated_by,omitempty"`
+	Git           *StackGitConfig `json:"git,omitempty"`
+	// Namespace is the namespace of kubernetes stacks
+	Namespace string `json:"namespace,omitempty"`
+	// ComposeFormat is true for kubernetes stacks deployed from a compose file instead of a manifest
+	ComposeFormat bool `json:"compose_format,omitempty"`
+}
+
+// Kinds of stacks
+const (
+	StackKindCompose    = "compose"
+	StackKindSwarm      = "swarm"
+	StackKindKubernetes = "kubernetes"
+	StackKindEdge       = "edge"
+	StackKindUnknown    = "unknown"
+)
+
+// StackKindRegular selects the regular stacks (compose, swarm and kubernetes) when looking up a stack by ID,
+// as opposed to StackKindEdge. Portainer numbers regular and edge stacks separately, so an ID can match one of each.
+const StackKindRegular = "regular"
+
+// Status of regular stacks
+const (
+	StackStatusRunning = "running"
+	StackStatusStopped = "stopped"
+)
+
+// StackGitConfig describes the git repository a stack is deployed from
+type StackGitConfig struct {
+	RepositoryURL string `json:"repository_url"`
+	ReferenceName string `json:"reference_name"`
+	ComposeFile   string `json:"compose_file"`
+	CommitHash    string `json:"commit_hash"`
+}
+
+// Types of the regular stacks that can be created on an environment
+const (
+	StackTypeStandalone = "standalone"
+	StackTypeSwarm      = "swarm"
+)
+
+// StackCreateOptions defines the stack to create.
+// A regular stack is created on EnvironmentID, unless EnvironmentGroupIds are given,
+// in which case an edge stack is deployed to these edge groups.
+type StackCreateOptions struct {
+	Name                string
+	File                string
+	EnvironmentID       int
+	Type                string
+	EnvironmentGroupIds []int
+	Env                 []StackEnvVar
+}
+
+// KubernetesStackCreateOptions defines a kubernetes stack to create on EnvironmentID
+type KubernetesStackCreateOptions struct {
+	Name          string
+	EnvironmentID int
+	Namespace     string
+	// Manifest is the multi-document YAML manifest of the stack, or a compose file if ComposeFormat is true
+	Manifest string
+	// ComposeFormat lets Portainer convert the compose file in Manifest to kubernetes resources
+	ComposeFormat bool
+}
+
+// StackGitCreateOptions defines a regular stack deployed from a compose file stored in a git repository
+type StackGitCreateOptions struct {
+	Name          string
+	EnvironmentID int
+	Type          string
+	RepositoryURL string
+	// ReferenceName is the git reference to deploy, e.g. refs/heads/main. Defaults to the default branch.
+	ReferenceName string
+	// ComposeFile is the path of the compose file in the repository. Defaults to docker-compose.yml.
+	ComposeFile     string
+	AdditionalFiles []string
+	Username        string
+	Password        string
+	// GitCredentialID is the ID of git credentials saved in Portainer, used instead of Username and Password
+	GitCredentialID int
+	TLSSkipVerify   bool
+	Env             []StackEnvVar
+	AutoUpdate      *StackAutoUpdate
+}
+
+// StackAutoUpdate holds the GitOps auto-update settings of a stack deployed from git.
+// The stack is updated every Interval (e.g. 5m) and/or when its webhook is called.
+type StackAutoUpdate struct {
+	Interval string `json:"interval,omitempty"`
+	// Webhook is the UUID identifying the webhook of the stack
+	Webhook        string `json:"webhook,omitempty"`
+	ForceUpdate    bool   `json:"force_update"`
+	ForcePullImage bool   `json:"force_pull_image"`
+}
+
+// StackGitSettings describes the git repository of a stack and its auto-update settings
+type StackGitSettings struct {
+	StackID int `json:"stack_id"`
+	StackGitConfig
+	Authenticated bool             `json:"authenticated"`
+	AutoUpdate    *StackAutoUpdate `json:"auto_update,omitempty"`
+	WebhookURL    string           `json:"webhook_url,omitempty"`
+}
+
+// StackWebhook describes the redeploy webhook of a regular stack.
+// Calling the webhook URL redeploys the stack, pulling it from git first for stacks deployed from git.
+type StackWebhook struct {
+	StackID int  `json:"stack_id"`
+	Enabled bool `json:"enabled"`
+	// Webhook is the UUID identifying the webhook of the stack
+	Webhook string `json:"webhook,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// StackGitRedeployOptions defines how a stack deployed from git is pulled and redeployed
+type StackGitRedeployOptions struct {
+	// ReferenceName switches the stack to another git reference if set
+	ReferenceName string
+	PullImage     bool
+	Prune         bool
+}
+
+// StackRedeployOptions defines how a stack is redeployed
+type StackRedeployOptions struct {
+	// PullImage pulls the latest version of the images of the stack
+	PullImage bool
+	// Prune removes the services that are no longer in the stack file
+	Prune bool
 }
 
 type StackEnvVar struct {
@@ -19,34 +153,124 @@ type StackEnvVar struct {
 	Value string `json:"value"`
 }
 
+// StackEnvChange describes a change of the environment variables of a regular stack.
+// Renames are applied first, then removals, then the variables to set.
+type StackEnvChange struct {
+	// Set adds variables or overrides their values
+	Set []StackEnvVar
+	// Unset removes variables
+	Unset []string
+	// Rename renames variables, keeping their values, mapping each current name to its new name
+	Rename map[string]string
+}
+
 // RegularStack represents a regular Docker stack from the Portainer API.
 type RegularStack struct {
-	ID           int    `json:"Id"`
-	Name         string `json:"Name"`
-	Type         int    `json:"Type"`
-	EndpointId   int    `json:"EndpointId"`
-	CreationDate int64  `json:"CreationDate"`
-	Status       int    `json:"Status"`
+	ID              int                    `json:"Id"`
+	Name            string                 `json:"Name"`
+	Type            int                    `json:"Type"`
+	EndpointId      int                    `json:"EndpointId"`
+	CreationDate    int64                  `json:"CreationDate"`
+	CreatedBy       string                 `json:"CreatedBy"`
+	UpdateDate      int64                  `json:"UpdateDate"`
+	UpdatedBy       string                 `json:"UpdatedBy"`
+	Status          int                    `json:"Status"`
+	GitConfig       *RegularStackGitConfig `json:"GitConfig"`
+	Namespace       string                 `json:"Namespace"`
+	IsComposeFormat bool                   `json:"IsComposeFormat"`
 }
 
+// RegularStackGitConfig is the git configuration of a regular stack from the Portainer API
+type RegularStackGitConfig struct {
+	URL            string `json:"URL"`
+	ReferenceName  string `json:"ReferenceName"`
+	ConfigFilePath string `json:"ConfigFilePath"`
+	ConfigHash     string `json:"ConfigHash"`
+}
+
+// Types and status of regular stacks in the Portainer API
+const (
+	regularStackTypeSwarm      = 1
+	regularStackTypeCompose    = 2
+	regularStackTypeKubernetes = 3
+
+	regularStackStatusActive   = 1
+	regularStackStatusInactive = 2
+)
+
 func ConvertEdgeStackToStack(rawEdgeStack *apimodels.PortainereeEdgeStack) Stack {
 	createdAt := time.Unix(rawEdgeStack.CreationDate, 0).Format(time.RFC3339)
 
-	return Stack{
+	stack := Stack{
 		ID:                  int(rawEdgeStack.ID),
 		Name:                rawEdgeStack.Name,
 		CreatedAt:           createdAt,
 		EnvironmentGroupIds: utils.Int64ToIntSlice(rawEdgeStack.EdgeGroups),
+		Kind:                StackKindEdge,
+	}
+
+	if gitConfig := rawEdgeStack.GitConfig; gitConfig != nil && gitConfig.URL != "" {
+		stack.Git = &StackGitConfig{
+			RepositoryURL: gitConfig.URL,
+			ReferenceName: gitConfig.ReferenceName,
+			ComposeFile:   gitConfig.ConfigFilePath,
+			CommitHash:    gitConfig.ConfigHash,
+		}
 	}
+
+	return stack
 }
 
 func ConvertRegularStackToStack(rawStack *RegularStack) Stack {
 	createdAt := time.Unix(rawStack.CreationDate, 0).Format(time.RFC3339)
 
-	return Stack{
+	stack := Stack{
 		ID:                  rawStack.ID,
 		Name:                rawStack.Name,
 		CreatedAt:           createdAt,
 		EnvironmentGroupIds: []int{},
+		Kind:                convertRegularStackType(rawStack.Type),
+		Status:              convertRegularStackStatus(rawStack.Status),
+		EnvironmentID:       rawStack.EndpointId,
+		CreatedBy:           rawStack.CreatedBy,
+		UpdatedAt:           formatUnixTime(rawStack.UpdateDate),
+		UpdatedBy:           rawStack.UpdatedBy,
+		Namespace:           rawStack.Namespace,
+		ComposeFormat:       rawStack.IsComposeFormat,
+	}
+
+	if gitConfig := rawStack.GitConfig; gitConfig != nil && gitConfig.URL != "" {
+		stack.Git = &StackGitConfig{
+			RepositoryURL: gitConfig.URL,
+			ReferenceName: gitConfig.ReferenceName,
+			ComposeFile:   gitConfig.ConfigFilePath,
+			CommitHash:    gitConfig.ConfigHash,
+		}
+	}
+
+	return stack
+}
+
+func convertRegularStackType(stackType int) string {
+	switch stackType {
+	case regularStackTypeSwarm:
+		return StackKindSwarm
+	case regularStackTypeCompose:
+		return StackKindCompose
+	case regularStackTypeKubernetes:
+		return StackKindKubernetes
+	default:
+		return StackKindUnknown
+	}
+}
+
+func convertRegularStackStatus(status int) string {
+	switch status {
+	case regularStackStatusActive:
+		return StackStatusRunning
+	case regularStackStatusInactive:
+		return StackStatusStopped
+	default:
+		return ""
 	}
 }
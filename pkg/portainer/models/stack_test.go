@@ -28,6 +28,7 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 				Name:                "Web Application Stack",
 				CreatedAt:           "2021-01-01T00:00:00Z",
 				EnvironmentGroupIds: []int{1, 2, 3},
+				Kind:                StackKindEdge,
 			},
 		},
 		{
@@ -43,6 +44,7 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 				Name:                "Empty Stack",
 				CreatedAt:           "2022-01-01T00:00:00Z",
 				EnvironmentGroupIds: []int{},
+				Kind:                StackKindEdge,
 			},
 		},
 		{
@@ -58,6 +60,7 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 				Name:                "Single Group Stack",
 				CreatedAt:           "2023-01-01T00:00:00Z",
 				EnvironmentGroupIds: []int{4},
+				Kind:                StackKindEdge,
 			},
 		},
 		{
@@ -73,6 +76,35 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 				Name:                "Recent Stack",
 				CreatedAt:           time.Unix(time.Now().Add(-24*time.Hour).Unix(), 0).Format(time.RFC3339),
 				EnvironmentGroupIds: []int{1, 2},
+				Kind:                StackKindEdge,
+			},
+		},
+		{
+			name: "edge stack deployed from git",
+			edgeStack: &models.PortainereeEdgeStack{
+				ID:           5,
+				Name:         "Git Stack",
+				CreationDate: 1609459200,
+				EdgeGroups:   []int64{1},
+				GitConfig: &models.GittypesRepoConfig{
+					URL:            "https://git.example.com/ops/stacks.git",
+					ReferenceName:  "refs/heads/main",
+					ConfigFilePath: "docker-compose.yml",
+					ConfigHash:     "bc4c183d",
+				},
+			},
+			want: Stack{
+				ID:                  5,
+				Name:                "Git Stack",
+				CreatedAt:           "2021-01-01T00:00:00Z",
+				EnvironmentGroupIds: []int{1},
+				Kind:                StackKindEdge,
+				Git: &StackGitConfig{
+					RepositoryURL: "https://git.example.com/ops/stacks.git",
+					ReferenceName: "refs/heads/main",
+					ComposeFile:   "docker-compose.yml",
+					CommitHash:    "bc4c183d",
+				},
 			},
 		},
 	}
@@ -88,24 +120,97 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 }
 
 func TestConvertRegularStackToStack(t *testing.T) {
-	regularStack := RegularStack{
-		ID:           12,
-		Name:         "regular-stack",
-		Type:         2,
-		EndpointId:   5,
-		CreationDate: 1609459200,
-		Status:       1,
-	}
-
-	want := Stack{
-		ID:                  12,
-		Name:                "regular-stack",
-		CreatedAt:           "2021-01-01T00:00:00Z",
-		EnvironmentGroupIds: []int{},
+	tests := []struct {
+		name         string
+		regularStack RegularStack
+		want         Stack
+	}{
+		{
+			name: "running compose stack",
+			regularStack: RegularStack{
+				ID:           12,
+				Name:         "regular-stack",
+				Type:         2,
+				EndpointId:   5,
+				CreationDate: 1609459200,
+				CreatedBy:    "admin",
+				Status:       1,
+			},
+			want: Stack{
+				ID:                  12,
+				Name:                "regular-stack",
+				CreatedAt:           "2021-01-01T00:00:00Z",
+				EnvironmentGroupIds: []int{},
+				Kind:                StackKindCompose,
+				Status:              StackStatusRunning,
+				EnvironmentID:       5,
+				CreatedBy:           "admin",
+			},
+		},
+		{
+			name: "stopped swarm stack deployed from git",
+			regularStack: RegularStack{
+				ID:           13,
+				Name:         "git-stack",
+				Type:         1,
+				EndpointId:   2,
+				CreationDate: 1609459200,
+				UpdateDate:   1640995200,
+				UpdatedBy:    "ops",
+				Status:       2,
+				GitConfig: &RegularStackGitConfig{
+					URL:            "https://git.example.com/ops/stacks.git",
+					ReferenceName:  "refs/heads/main",
+					ConfigFilePath: "docker-compose.yml",
+					ConfigHash:     "bc4c183d",
+				},
+			},
+			want: Stack{
+				ID:                  13,
+				Name:                "git-stack",
+				CreatedAt:           "2021-01-01T00:00:00Z",
+				EnvironmentGroupIds: []int{},
+				Kind:                StackKindSwarm,
+				Status:              StackStatusStopped,
+				EnvironmentID:       2,
+				UpdatedAt:           "2022-01-01T00:00:00Z",
+				UpdatedBy:           "ops",
+				Git: &StackGitConfig{
+					RepositoryURL: "https://git.example.com/ops/stacks.git",
+					ReferenceName: "refs/heads/main",
+					ComposeFile:   "docker-compose.yml",
+					CommitHash:    "bc4c183d",
+				},
+			},
+		},
+		{
+			name: "kubernetes stack",
+			regularStack: RegularStack{
+				ID:           14,
+				Name:         "k8s-stack",
+				Type:         3,
+				EndpointId:   7,
+				CreationDate: 1609459200,
+				Namespace:    "shop",
+			},
+			want: Stack{
+				ID:                  14,
+				Name:                "k8s-stack",
+				CreatedAt:           "2021-01-01T00:00:00Z",
+				EnvironmentGroupIds: []int{},
+				Kind:                StackKindKubernetes,
+				EnvironmentID:       7,
+				Namespace:           "shop",
+			},
+		},
 	}
 
-	got := ConvertRegularStackToStack(&regularStack)
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("ConvertRegularStackToStack() = %v, want %v", got, want)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertRegularStackToStack(&tt.regularStack)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertRegularStackToStack() = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }